@@ -2,15 +2,25 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	awscloud "github.com/azguard/azguard/internal/cloud/aws"
 	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/providers"
 	"github.com/azguard/azguard/internal/storage"
 	"github.com/spf13/cobra"
 )
 
-var awsCostClient *awscloud.CostClient
+var (
+	awsCostClient *awscloud.CostClient
+	awsProfile    string
+)
 
 func awsCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -22,23 +32,37 @@ Examples:
   azguard aws status              Check AWS free tier usage
   azguard aws scan                Scan services approaching limits
   azguard aws alerts --threshold 80  Set alert threshold
-  azguard aws resources           List free tier eligible services
-  azguard aws cost                View AWS cost breakdown`,
+  azguard aws resources           Find running resources in every region
+  azguard aws cost                View AWS cost breakdown
+  azguard aws fetch --months 3    Store daily AWS costs for history and trends
+  azguard aws forecast            Forecast this month's bill
+  azguard aws budget push         Mirror local budgets as AWS Budgets`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Run the root PersistentPreRunE first for config/db
 			if err := cmd.Root().PersistentPreRunE(cmd, args); err != nil {
 				return err
 			}
+			// The demo registry serves status, scan and resources; commands
+			// that call AWS directly refuse to run themselves.
+			if demoMode {
+				return nil
+			}
 
-			awsCostClient = awscloud.NewCostClient(
-				cfg.AWS.AccessKey,
-				cfg.AWS.SecretKey,
-				cfg.AWS.SessionToken,
-				cfg.AWS.Region,
-			)
+			p, err := registry.Get("aws")
+			if err != nil {
+				return err
+			}
+			provider, ok := p.(*providers.AWS)
+			if !ok {
+				return fmt.Errorf("unexpected AWS provider %T", p)
+			}
+			awsCostClient = provider.Client
 
+			if awsCostClient.ConfigError != nil {
+				fmt.Printf("⚠️  %v\n", awsCostClient.ConfigError)
+			}
 			if !awsCostClient.IsConfigured() {
-				fmt.Println("⚠️  AWS credentials not found.")
+				fmt.Printf("⚠️  AWS credentials not found (profile: %s).\n", awsCostClient.Profile)
 				fmt.Println("Configure with: aws configure")
 				fmt.Println("Or set: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
 			}
@@ -52,6 +76,10 @@ Examples:
 	cmd.AddCommand(awsAlertsCmd())
 	cmd.AddCommand(awsResourcesCmd())
 	cmd.AddCommand(awsCostCmd())
+	cmd.AddCommand(awsFetchCmd())
+	cmd.AddCommand(awsForecastCmd())
+	cmd.AddCommand(awsBudgetCmd())
+	cmd.AddCommand(awsAnomaliesCmd())
 
 	return cmd
 }
@@ -61,80 +89,38 @@ func awsStatusCmd() *cobra.Command {
 		Use:   "status",
 		Short: "Check your AWS free tier usage summary",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-
-			fmt.Println("\n🛡️  AWS Free Tier Status")
-			fmt.Println("═══════════════════════════════")
-
-			if !awsCostClient.IsConfigured() {
-				return fmt.Errorf("AWS credentials not configured")
-			}
-
-			usages, err := awsCostClient.GetFreeTierUsage(ctx)
-			if err != nil {
-				fmt.Printf("Note: Could not fetch free tier data: %v\n", err)
-				fmt.Println("Falling back to cost-based analysis...")
-
-				// Fallback to Cost Explorer
-				startDate, endDate := cost.GetCurrentMonthDateRange()
-				result, costErr := awsCostClient.QueryCostsByService(ctx, startDate, endDate)
-				if costErr != nil {
-					return fmt.Errorf("failed to fetch AWS costs: %w", costErr)
-				}
-
-				fmt.Printf("Current Month Spend: $%.2f\n", result.TotalCost)
-				if result.TotalCost == 0 {
-					fmt.Println("✅ Status: No charges detected")
-				} else if result.TotalCost < 1.0 {
-					fmt.Println("⚠️  Status: Minor charges detected")
-				} else {
-					fmt.Println("❌ Status: Charges exceeding free tier")
-				}
-				fmt.Println()
-				return nil
-			}
-
-			// Summarize free tier usage
-			warningCount := 0
-			overCount := 0
-			for _, u := range usages {
-				if u.PercentUsed >= 100 {
-					overCount++
-				} else if u.PercentUsed >= 80 {
-					warningCount++
-				}
-			}
-
-			fmt.Printf("Services Tracked: %d\n", len(usages))
-			if overCount > 0 {
-				fmt.Printf("❌ Over Limit: %d services\n", overCount)
-			}
-			if warningCount > 0 {
-				fmt.Printf("⚠️  Warning: %d services approaching limits\n", warningCount)
-			}
-			if overCount == 0 && warningCount == 0 {
-				fmt.Println("✅ Status: All services within free tier limits")
-			}
-
-			// Show alerts
-			alerts, err := db.GetAlerts()
-			if err == nil && len(alerts) > 0 {
-				fmt.Printf("\n🔔 Active Alerts: %d\n", len(alerts))
-			}
-
-			fmt.Println()
-			return nil
+			return runStatus(context.Background(), "aws")
 		},
 	}
 }
 
 func awsScanCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		allAccounts bool
+		role        string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "scan",
 		Short: "Scan AWS services approaching free tier limits",
+		Long: `Scan free tier usage and store a snapshot per account. With --all-accounts,
+run from an organization's management account: every active member account is
+scanned by assuming --role in it.
+
+Examples:
+  azguard aws scan
+  azguard aws scan --all-accounts
+  azguard aws scan --all-accounts --role StudentReadOnly`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
+			if !allAccounts {
+				return runScan(ctx, "aws")
+			}
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
 			fmt.Println("\n🔍 AWS Free Tier Scan")
 			fmt.Println("═══════════════════════════════")
 
@@ -142,57 +128,142 @@ func awsScanCmd() *cobra.Command {
 				return fmt.Errorf("AWS credentials not configured")
 			}
 
-			usages, err := awsCostClient.GetFreeTierUsage(ctx)
+			catalog, err := cost.LoadFreeTierCatalog("aws")
 			if err != nil {
-				return fmt.Errorf("failed to fetch free tier usage: %w", err)
-			}
-
-			if len(usages) == 0 {
-				fmt.Println("No free tier usage data found.")
-				return nil
+				return err
 			}
+			return scanAWSAccounts(ctx, catalog, role)
+		},
+	}
 
-			issuesFound := false
-			fmt.Println("\nBy Service:")
-			fmt.Println("─────────────────────────────────")
+	cmd.Flags().BoolVar(&allAccounts, "all-accounts", false, "Scan every active account in the organization")
+	cmd.Flags().StringVar(&role, "role", awscloud.DefaultMemberRole, "Role to assume in member accounts")
 
-			for _, u := range usages {
-				status := "✅"
-				if u.PercentUsed >= 100 {
-					status = "❌ OVER"
-					issuesFound = true
-				} else if u.PercentUsed >= 80 {
-					status = "⚠️  WARN"
-					issuesFound = true
-				}
+	return cmd
+}
 
-				fmt.Printf("%s %-30s %.1f / %.0f %s (%.1f%%)\n",
-					status, u.ServiceName+":", u.ActualUsage, u.FreeTierLimit, u.Unit, u.PercentUsed)
+// scanAWSAccounts scans each active organization account, assuming role in
+// member accounts. A failing account is reported and skipped.
+func scanAWSAccounts(ctx context.Context, catalog *cost.FreeTierConfig, role string) error {
+	accounts, err := awsCostClient.ListAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	managementID, err := awsCostClient.GetCallerIdentity(ctx)
+	if err != nil {
+		return err
+	}
 
-				if u.Description != "" {
-					fmt.Printf("     %s\n", u.Description)
-				}
-			}
+	var withIssues, failed []string
+	for _, a := range accounts {
+		if a.Status != "ACTIVE" {
+			continue
+		}
+
+		fmt.Printf("\n%s (%s)\n", a.Name, a.ID)
+		fmt.Println("─────────────────────────────────")
+
+		client := awsCostClient
+		if a.ID != managementID {
+			client = awsCostClient.ForAccount(a.ID, role)
+		}
+		found, err := client.GetFreeTierUsage(ctx)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed = append(failed, a.Name)
+			continue
+		}
+		usages := providers.AWSFreeTierUsage(catalog, found)
+		if err := costSvc.StoreFreeTierUsage("aws", a.ID, usages); err != nil {
+			fmt.Printf("Note: %v\n", err)
+		}
+
+		if len(usages) == 0 {
+			fmt.Println("No free tier usage data found.")
+			continue
+		}
+		if printUsageRows(usages) {
+			withIssues = append(withIssues, a.Name)
+		}
+	}
 
-			if !issuesFound {
-				fmt.Println("\n✅ All AWS services within free tier limits!")
-			} else {
-				fmt.Println("\n⚠️  Some services are approaching or exceeding free tier limits.")
-				fmt.Println("Run 'azguard aws resources' for details on each service.")
-			}
-			fmt.Println()
-			return nil
-		},
+	fmt.Println()
+	if len(withIssues) == 0 && len(failed) == 0 {
+		fmt.Println("✅ All accounts within free tier limits!")
+	}
+	if len(withIssues) > 0 {
+		fmt.Printf("⚠️  Accounts approaching or exceeding limits: %s\n", strings.Join(withIssues, ", "))
 	}
+	if len(failed) > 0 {
+		fmt.Printf("❌ Could not scan: %s (check that role %s exists and trusts this account)\n", strings.Join(failed, ", "), role)
+	}
+	fmt.Println()
+	return nil
 }
 
 func awsAlertsCmd() *cobra.Command {
-	var threshold float64
+	var (
+		threshold float64
+		credit    float64
+		days      int
+		anomaly   float64
+	)
 
 	cmd := &cobra.Command{
 		Use:   "alerts",
 		Short: "Manage AWS free tier alert thresholds",
+		Long: `Manage AWS alert thresholds. --threshold applies to free tier usage;
+--credit and --days apply to the credit-based free plan and are checked by
+'azguard aws status'. --anomaly fires for cost anomalies with at least that
+dollar impact and is checked by 'azguard aws anomalies'.
+
+Examples:
+  azguard aws alerts --threshold 80
+  azguard aws alerts --credit 20
+  azguard aws alerts --days 14
+  azguard aws alerts --anomaly 5`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if credit > 0 {
+				alert := storage.Alert{
+					Name:      fmt.Sprintf("%s%g", awsCreditAlertPrefix, credit),
+					Threshold: credit,
+					Enabled:   true,
+				}
+				if err := db.SaveAlert(alert); err != nil {
+					return err
+				}
+				fmt.Printf("✅ AWS credit alert set: $%.2f\n", credit)
+				fmt.Println("   You'll be notified when free plan credits drop to this amount.")
+			}
+
+			if days > 0 {
+				alert := storage.Alert{
+					Name:      fmt.Sprintf("%s%d", awsPlanDaysAlertPrefix, days),
+					Threshold: float64(days),
+					Enabled:   true,
+				}
+				if err := db.SaveAlert(alert); err != nil {
+					return err
+				}
+				fmt.Printf("✅ AWS plan expiry alert set: %d days\n", days)
+				fmt.Println("   You'll be notified when the free plan has this many days left.")
+			}
+			if anomaly > 0 {
+				alert := storage.Alert{
+					Name:      fmt.Sprintf("%s%g", awsAnomalyAlertPrefix, anomaly),
+					Threshold: anomaly,
+					Enabled:   true,
+				}
+				if err := db.SaveAlert(alert); err != nil {
+					return err
+				}
+				fmt.Printf("✅ AWS anomaly alert set: $%.2f impact\n", anomaly)
+				fmt.Println("   You'll be notified of cost anomalies with at least this impact.")
+			}
+			if credit > 0 || days > 0 || anomaly > 0 {
+				return nil
+			}
+
 			if threshold > 0 {
 				// Set alert threshold
 				if threshold < 1 || threshold > 100 {
@@ -229,7 +300,16 @@ func awsAlertsCmd() *cobra.Command {
 					if !a.Enabled {
 						status = "❌ Disabled"
 					}
-					fmt.Printf("  %s: %.0f%% - %s\n", a.Name, a.Threshold, status)
+					switch {
+					case strings.HasPrefix(a.Name, awsCreditAlertPrefix):
+						fmt.Printf("  %s: $%.2f credit left - %s\n", a.Name, a.Threshold, status)
+					case strings.HasPrefix(a.Name, awsPlanDaysAlertPrefix):
+						fmt.Printf("  %s: %.0f days left - %s\n", a.Name, a.Threshold, status)
+					case strings.HasPrefix(a.Name, awsAnomalyAlertPrefix):
+						fmt.Printf("  %s: $%.2f anomaly impact - %s\n", a.Name, a.Threshold, status)
+					default:
+						fmt.Printf("  %s: %.0f%% - %s\n", a.Name, a.Threshold, status)
+					}
 				}
 			}
 
@@ -242,63 +322,126 @@ func awsAlertsCmd() *cobra.Command {
 	}
 
 	cmd.Flags().Float64Var(&threshold, "threshold", 0, "Alert at this percentage of free tier limit (1-100)")
+	cmd.Flags().Float64Var(&credit, "credit", 0, "Alert when free plan credits drop to this amount (USD)")
+	cmd.Flags().IntVar(&days, "days", 0, "Alert when the free plan has this many days left")
+	cmd.Flags().Float64Var(&anomaly, "anomaly", 0, "Alert on cost anomalies with at least this impact (USD)")
 
 	return cmd
 }
 
 func awsResourcesCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		regions  []string
+		billable bool
+		usage    bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "resources",
-		Short: "List AWS free tier eligible services and current usage",
+		Short: "Sweep every region for running resources and flag billable ones",
+		Long: `List EC2 instances, EBS volumes and snapshots, Elastic IPs, NAT gateways,
+RDS instances, load balancers and S3 buckets in every enabled region, and flag
+each as free tier eligible or billable. Use --usage for free tier usage rows.
+
+Examples:
+  azguard aws resources
+  azguard aws resources --billable
+  azguard aws resources --region us-east-1 --region eu-west-1
+  azguard aws resources --usage`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
-			fmt.Println("\n📋 AWS Free Tier Resources")
-			fmt.Println("═══════════════════════════════")
-
-			if !awsCostClient.IsConfigured() {
-				fmt.Println("AWS credentials not configured. Showing known free tier services:")
-				printAWSFreeTierServices()
-				return nil
+			if usage {
+				if err := refuseInDemo(cmd.CommandPath(), "AWS", "use 'azguard --demo aws scan' for stored usage"); err != nil {
+					return err
+				}
+				return printAWSFreeTierUsage(ctx)
 			}
 
-			usages, err := awsCostClient.GetFreeTierUsage(ctx)
+			p, err := registry.Get("aws")
 			if err != nil {
-				fmt.Printf("Could not fetch live data: %v\n", err)
-				fmt.Println("Showing known free tier services:")
-				printAWSFreeTierServices()
-				return nil
+				return err
 			}
+			if provider, ok := p.(*providers.AWS); ok {
+				provider.Regions = regions
+			}
+			return runResources(ctx, "aws", billable)
+		},
+	}
 
-			for _, u := range usages {
-				status := "✅ FREE"
-				if u.PercentUsed >= 100 {
-					status = "❌ OVER"
-				} else if u.PercentUsed >= 80 {
-					status = "⚠️  WARN"
-				}
+	cmd.Flags().StringSliceVar(&regions, "region", nil, "Only sweep these regions (repeatable; default all enabled regions)")
+	cmd.Flags().BoolVar(&billable, "billable", false, "Only show billable resources")
+	cmd.Flags().BoolVar(&usage, "usage", false, "Show free tier usage per service instead of the resource sweep")
 
-				fmt.Printf("\n%s %s\n", status, u.ServiceName)
-				fmt.Printf("  Usage: %.1f / %.0f %s (%.1f%%)\n", u.ActualUsage, u.FreeTierLimit, u.Unit, u.PercentUsed)
-				if u.ForecastUsage > 0 {
-					fmt.Printf("  Forecast: %.1f %s by end of month\n", u.ForecastUsage, u.Unit)
-				}
-				if u.Description != "" {
-					fmt.Printf("  %s\n", u.Description)
-				}
-			}
+	return cmd
+}
 
-			fmt.Println()
-			return nil
-		},
+// Alert name prefixes for free plan alerts; thresholds are dollars and days.
+const (
+	awsCreditAlertPrefix   = "aws" + cost.CreditAlertSuffix
+	awsPlanDaysAlertPrefix = "aws" + cost.PlanDaysAlertSuffix
+)
+
+func printAWSFreeTierUsage(ctx context.Context) error {
+	fmt.Println("\n📋 AWS Free Tier Resources")
+	fmt.Println("═══════════════════════════════")
+
+	catalog, err := cost.LoadFreeTierCatalog("aws")
+	if err != nil {
+		return err
 	}
+
+	if !awsCostClient.IsConfigured() {
+		fmt.Println("AWS credentials not configured. Showing known free tier services:")
+		printAWSFreeTierServices(catalog)
+		return nil
+	}
+
+	found, err := awsCostClient.GetFreeTierUsage(ctx)
+	if err != nil {
+		fmt.Printf("Could not fetch live data: %v\n", err)
+		fmt.Println("Showing known free tier services:")
+		printAWSFreeTierServices(catalog)
+		return nil
+	}
+
+	for _, u := range providers.AWSFreeTierUsage(catalog, found) {
+		status := "✅ FREE"
+		switch cost.UsageStatus(u) {
+		case cost.StatusOverage:
+			status = "❌ OVER"
+		case cost.StatusWarning:
+			status = "⚠️  WARN"
+		}
+
+		fmt.Printf("\n%s %s\n", status, u.Name)
+		fmt.Printf("  Usage: %.1f / %.0f %s (%.1f%%)\n", u.Used, u.Limit, u.Unit, u.PercentUsed)
+		if u.Forecast > 0 {
+			fmt.Printf("  Forecast: %.1f %s by end of month\n", u.Forecast, u.Unit)
+		}
+		if u.Description != "" {
+			fmt.Printf("  %s\n", u.Description)
+		}
+	}
+
+	fmt.Println()
+	return nil
 }
 
 func awsCostCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		withForecast bool
+		byAccount    bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "cost",
 		Short: "View AWS cost breakdown for current month",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
 			ctx := context.Background()
 
 			fmt.Println("\n📊 AWS Cost Breakdown")
@@ -309,21 +452,44 @@ func awsCostCmd() *cobra.Command {
 			}
 
 			startDate, endDate := cost.GetCurrentMonthDateRange()
+			if byAccount {
+				return printAWSCostByAccount(ctx, startDate, endDate)
+			}
+
 			result, err := awsCostClient.QueryCostsByService(ctx, startDate, endDate)
 			if err != nil {
 				return fmt.Errorf("failed to query AWS costs: %w", err)
 			}
 
 			fmt.Printf("Period: %s to %s\n", startDate, endDate)
-			fmt.Printf("Total: $%.2f %s\n", result.TotalCost, result.Currency)
+			fmt.Printf("Total: %s\n", formatMoney(result.TotalCost, result.Currency))
 
 			if len(result.Records) > 0 {
 				fmt.Println("\nBy Service:")
+				if withForecast {
+					fmt.Printf("  %-35s %10s %12s\n", "", "To date", "Month end")
+				}
 				fmt.Println("─────────────────────────────────")
+				forecastStart, forecastEnd := awscloud.RemainingMonth()
+				missing := false
 				for _, r := range result.Records {
-					if r.Cost > 0.001 {
+					if r.Cost <= 0.001 {
+						continue
+					}
+					if !withForecast {
 						fmt.Printf("  %-35s $%.4f\n", r.ServiceName+":", r.Cost)
+						continue
+					}
+					monthEnd := "—"
+					if f, err := awsCostClient.GetCostForecast(ctx, forecastStart, forecastEnd, r.ServiceName); err == nil {
+						monthEnd = fmt.Sprintf("$%.2f", r.Cost+f.Mean)
+					} else {
+						missing = true
 					}
+					fmt.Printf("  %-35s %10s %12s\n", r.ServiceName+":", fmt.Sprintf("$%.2f", r.Cost), monthEnd)
+				}
+				if missing {
+					fmt.Println("  (— means Cost Explorer has too little history to forecast the service)")
 				}
 			}
 
@@ -335,31 +501,235 @@ func awsCostCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&withForecast, "forecast", false, "Add a month-end forecast column per service")
+	cmd.Flags().BoolVar(&byAccount, "by-account", false, "Break costs down by linked account of the organization")
+	return cmd
 }
 
-func printAWSFreeTierServices() {
-	services := []struct {
-		name  string
-		limit string
-	}{
-		{"Amazon EC2", "750 hrs/month t2.micro (12 months)"},
-		{"Amazon S3", "5 GB standard storage (12 months)"},
-		{"AWS Lambda", "1M requests/month (always free)"},
-		{"Amazon RDS", "750 hrs/month db.t2.micro (12 months)"},
-		{"Amazon DynamoDB", "25 GB storage (always free)"},
-		{"Amazon CloudFront", "1 TB data transfer/month (always free)"},
-		{"Amazon SNS", "1M publishes/month (always free)"},
-		{"Amazon SQS", "1M requests/month (always free)"},
-		{"Amazon CloudWatch", "10 custom metrics (always free)"},
-		{"Amazon API Gateway", "1M REST API calls/month (12 months)"},
-		{"Amazon Cognito", "50K MAUs (always free)"},
-		{"Amazon EBS", "30 GB SSD storage (12 months)"},
+// printAWSCostByAccount groups the period's costs by linked account, naming
+// accounts through Organizations when the caller is allowed to list them.
+func printAWSCostByAccount(ctx context.Context, startDate, endDate string) error {
+	costs, err := awsCostClient.GetDailyCostsByAccount(ctx, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("failed to query AWS costs: %w", err)
 	}
 
-	for _, s := range services {
-		fmt.Printf("  %-25s %s\n", s.name, s.limit)
+	names := make(map[string]string)
+	if accounts, err := awsCostClient.ListAccounts(ctx); err == nil {
+		for _, a := range accounts {
+			names[a.ID] = a.Name
+		}
+	} else {
+		fmt.Printf("Note: Could not resolve account names: %v\n", err)
+	}
+
+	totals := make(map[string]float64)
+	byService := make(map[string]map[string]float64)
+	grand := 0.0
+	for _, r := range costs {
+		totals[r.AccountID] += r.Cost
+		if byService[r.AccountID] == nil {
+			byService[r.AccountID] = make(map[string]float64)
+		}
+		byService[r.AccountID][r.ServiceName] += r.Cost
+		grand += r.Cost
+	}
+
+	ids := make([]string, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return totals[ids[i]] > totals[ids[j]] })
+
+	fmt.Printf("Period: %s to %s\n", startDate, endDate)
+	fmt.Printf("Total: $%.2f\n", grand)
+
+	fmt.Println("\nBy Account:")
+	fmt.Println("─────────────────────────────────")
+	for _, id := range ids {
+		name := names[id]
+		if name == "" {
+			name = id
+		}
+		topService, topCost := "", 0.0
+		for svc, c := range byService[id] {
+			if c > topCost {
+				topService, topCost = svc, c
+			}
+		}
+		fmt.Printf("  %-25s %-14s $%8.2f", name, id, totals[id])
+		if topCost > 0.001 {
+			fmt.Printf("  (top: %s $%.2f)", topService, topCost)
+		}
+		fmt.Println()
+	}
+	if len(ids) == 0 {
+		fmt.Println("  No costs recorded for any account.")
+	}
+	fmt.Println()
+	return nil
+}
+
+func awsForecastCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "forecast",
+		Short: "Forecast this month's AWS bill with Cost Explorer",
+		Long: `Ask Cost Explorer for a forecast of the rest of the month, with an 80%
+prediction interval, and add it to month-to-date spend. When daily costs
+have been stored with 'aws fetch', the forecast is compared with azguard's
+own run-rate projection.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			if !awsCostClient.IsConfigured() {
+				return fmt.Errorf("AWS credentials not configured")
+			}
+
+			startDate, endDate := cost.GetCurrentMonthDateRange()
+			actual, err := awsCostClient.QueryCostsByService(ctx, startDate, endDate)
+			if err != nil {
+				return fmt.Errorf("failed to query AWS costs: %w", err)
+			}
+
+			forecastStart, forecastEnd := awscloud.RemainingMonth()
+			forecast, err := awsCostClient.GetCostForecast(ctx, forecastStart, forecastEnd, "")
+			if err != nil {
+				return err
+			}
+
+			local, err := costSvc.ProjectMonthEnd("aws")
+			if err != nil {
+				return err
+			}
+
+			monthEnd := actual.TotalCost + forecast.Mean
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(struct {
+					MonthToDate     float64                  `json:"month_to_date"`
+					Forecast        *awscloud.CostForecast   `json:"forecast"`
+					MonthEnd        float64                  `json:"month_end"`
+					LocalProjection *cost.MonthEndProjection `json:"local_projection,omitempty"`
+				}{actual.TotalCost, forecast, monthEnd, local}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Println("\n📈 AWS Cost Forecast")
+			fmt.Println("═══════════════════════════════")
+			fmt.Printf("Month to date:        %s\n", formatMoney(actual.TotalCost, forecast.Currency))
+			fmt.Printf("Rest of month:        $%.2f  (%d%% interval $%.2f – $%.2f)\n",
+				forecast.Mean, forecast.Confidence, forecast.Lower, forecast.Upper)
+			fmt.Printf("Month-end estimate:   $%.2f  ($%.2f – $%.2f)\n",
+				monthEnd, actual.TotalCost+forecast.Lower, actual.TotalCost+forecast.Upper)
+
+			fmt.Println("\nLocal Projection:")
+			fmt.Println("─────────────────────────────────")
+			if local == nil {
+				fmt.Println("  No AWS costs stored for this month. Run 'azguard aws fetch' to compare.")
+			} else {
+				fmt.Printf("  Run rate over %d of %d days: $%.2f\n", local.DaysCovered, local.DaysInMonth, local.Projected)
+				if monthEnd > 0 {
+					fmt.Printf("  Difference from Cost Explorer: %+.1f%%\n", (local.Projected-monthEnd)/monthEnd*100)
+				}
+			}
+
+			fmt.Println()
+			return nil
+		},
+	}
+}
+
+func awsFetchCmd() *cobra.Command {
+	var (
+		months     int
+		start, end string
+		byAccount  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Fetch daily AWS costs and store them locally",
+		Long: `Page through Cost Explorer at daily granularity and store the results, so
+'cost history --provider aws' and trends work for AWS. Re-fetching a period
+replaces what was stored for it. --end is exclusive. With --by-account, an
+organization's management account stores each linked account separately.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			if !awsCostClient.IsConfigured() {
+				return fmt.Errorf("AWS credentials not configured")
+			}
+
+			startDate, endDate := cost.GetCurrentMonthDateRange()
+			if months > 1 {
+				first, _ := time.Parse("2006-01-02", startDate)
+				startDate = first.AddDate(0, -(months - 1), 0).Format("2006-01-02")
+			}
+			if start != "" {
+				startDate = start
+			}
+			if end != "" {
+				endDate = end
+			}
+
+			var (
+				n   int
+				err error
+			)
+			if byAccount {
+				n, err = costSvc.FetchAndStoreAWSCostsByAccount(ctx, awsCostClient, startDate, endDate)
+			} else {
+				n, err = costSvc.FetchAndStoreCosts(ctx, "aws", startDate, endDate)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Stored %d AWS cost records (%s to %s)\n", n, startDate, endDate)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&months, "months", 1, "Number of months to fetch, including the current one")
+	cmd.Flags().StringVar(&start, "start", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&end, "end", "", "End date, exclusive (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&byAccount, "by-account", false, "Store costs per linked account of the organization")
+
+	return cmd
+}
+
+func printAWSFreeTierServices(catalog *cost.FreeTierConfig) {
+	for _, key := range catalog.Keys() {
+		s := catalog.Services[key]
+		name := s.Name
+		if name == "" {
+			name = key
+		}
+		fmt.Printf("  %-25s %s %s, %s (%s)\n", name, formatLimit(s.Limit), s.Unit, s.Description, s.Duration)
 	}
 
 	fmt.Println("\nConfigure AWS credentials to see your actual usage:")
 	fmt.Println("  aws configure")
 }
+
+// formatLimit shortens round limits, e.g. 1000000 to 1M and 50000 to 50K.
+func formatLimit(v float64) string {
+	switch {
+	case v >= 1e6 && math.Mod(v, 1e6) == 0:
+		return fmt.Sprintf("%.0fM", v/1e6)
+	case v >= 1e3 && math.Mod(v, 1e3) == 0:
+		return fmt.Sprintf("%.0fK", v/1e3)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	awscloud "github.com/azguard/azguard/internal/cloud/aws"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// awsAnomalyAlertPrefix names alerts on anomaly impact; the threshold is dollars.
+const awsAnomalyAlertPrefix = "aws" + cost.AnomalyAlertSuffix
+
+func awsAnomaliesCmd() *cobra.Command {
+	var (
+		days          int
+		cached        bool
+		createMonitor bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "anomalies",
+		Short: "List spend anomalies found by AWS Cost Anomaly Detection",
+		Long: `List anomalies detected by AWS Cost Anomaly Detection with their root
+causes (service, region, usage type) and impact, and store them locally.
+Alerts set with 'aws alerts --anomaly' are checked against the result.
+
+Anomaly Detection needs a monitor. When the account has none, azguard offers
+to create one that watches every service; --create-monitor skips the prompt.
+
+Examples:
+  azguard aws anomalies
+  azguard aws anomalies --days 90
+  azguard aws anomalies --cached
+  azguard aws anomalies --create-monitor`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			now := time.Now()
+			since := now.AddDate(0, 0, -days).Format("2006-01-02")
+
+			var anomalies []storage.Anomaly
+			if cached {
+				stored, err := db.GetAnomalies("aws", since)
+				if err != nil {
+					return err
+				}
+				anomalies = stored
+			} else {
+				if err := refuseInDemo(cmd.CommandPath(), "AWS", "use --cached for stored anomalies"); err != nil {
+					return err
+				}
+				if !awsCostClient.IsConfigured() {
+					return fmt.Errorf("AWS credentials not configured")
+				}
+				found, err := costSvc.SyncAWSAnomalies(ctx, awsCostClient, since, now.Format("2006-01-02"))
+				if err != nil {
+					return err
+				}
+				anomalies = found
+			}
+
+			alerts, err := db.GetAlerts()
+			if err != nil {
+				return err
+			}
+			triggered := awsAnomalyAlerts(alerts, anomalies)
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(anomalies, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Println("\n💥 AWS Cost Anomalies")
+			fmt.Println("═══════════════════════════════")
+			fmt.Printf("Since: %s\n", since)
+
+			if len(anomalies) == 0 {
+				fmt.Println("\n✅ No anomalies detected.")
+				if !cached {
+					if err := ensureAnomalyMonitor(ctx, createMonitor); err != nil {
+						return err
+					}
+				}
+				fmt.Println()
+				return nil
+			}
+
+			total := 0.0
+			for _, a := range anomalies {
+				total += a.Impact
+				period := a.StartDate
+				if a.EndDate != "" && a.EndDate != a.StartDate {
+					period += " → " + a.EndDate
+				} else if a.EndDate == "" {
+					period += " → ongoing"
+				}
+
+				fmt.Printf("\n%s  %s\n", period, a.ServiceName)
+				fmt.Printf("  Impact: $%.2f (+%.0f%%), $%.2f spent vs $%.2f expected\n",
+					a.Impact, a.ImpactPercent, a.ActualSpend, a.ExpectedSpend)
+				for _, rc := range a.RootCauses {
+					fmt.Printf("  Root cause: %s\n", formatRootCause(rc))
+				}
+			}
+
+			fmt.Println("\n─────────────────────────────────")
+			fmt.Printf("%d anomalies, $%.2f total impact\n", len(anomalies), total)
+			for _, msg := range triggered {
+				fmt.Printf("🔔 %s\n", msg)
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 30, "Look back this many days")
+	cmd.Flags().BoolVar(&cached, "cached", false, "Show stored anomalies without calling AWS")
+	cmd.Flags().BoolVar(&createMonitor, "create-monitor", false, "Create an anomaly monitor without asking if none exists")
+
+	return cmd
+}
+
+// ensureAnomalyMonitor explains an empty result when the account has no
+// monitor, and creates one if the user agrees.
+func ensureAnomalyMonitor(ctx context.Context, create bool) error {
+	monitors, err := awsCostClient.GetAnomalyMonitors(ctx)
+	if err != nil {
+		return err
+	}
+	if len(monitors) > 0 {
+		return nil
+	}
+
+	fmt.Println("\n⚠️  This account has no anomaly monitor, so AWS is not looking for anomalies.")
+	if !create && !confirm("Create a monitor that watches every AWS service?") {
+		fmt.Println("Run 'azguard aws anomalies --create-monitor' to create one later.")
+		return nil
+	}
+
+	arn, err := awsCostClient.CreateAnomalyMonitor(ctx, awscloud.AnomalyMonitorName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Created anomaly monitor %s\n", arn)
+	fmt.Println("   AWS needs about 10 days of history before it reports anomalies.")
+	return nil
+}
+
+// awsAnomalyAlerts returns a message for each enabled anomaly alert with at
+// least one anomaly at or above its impact threshold.
+func awsAnomalyAlerts(alerts []storage.Alert, anomalies []storage.Anomaly) []string {
+	var messages []string
+	for _, a := range alerts {
+		if !a.Enabled || !strings.HasPrefix(a.Name, awsAnomalyAlertPrefix) {
+			continue
+		}
+		count, impact := 0, 0.0
+		for _, an := range anomalies {
+			if an.Impact >= a.Threshold {
+				count++
+				impact += an.Impact
+			}
+		}
+		if count > 0 {
+			messages = append(messages, fmt.Sprintf("%s: %d anomalies with $%.2f impact (alert at $%.2f each)", a.Name, count, impact, a.Threshold))
+		}
+	}
+	return messages
+}
+
+func formatRootCause(rc storage.AnomalyCause) string {
+	var parts []string
+	for _, p := range []string{rc.Service, rc.Region, rc.UsageType} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if rc.LinkedAccount != "" {
+		parts = append(parts, "account "+rc.LinkedAccount)
+	}
+	return strings.Join(parts, " / ")
+}
+
+// confirm asks a yes/no question on stderr; anything but y or yes is no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestAWSAnomalyAlerts(t *testing.T) {
+	anomalies := []storage.Anomaly{
+		{ID: "a-1", Impact: 42.25},
+		{ID: "a-2", Impact: 10},
+		{ID: "a-3", Impact: 4.5},
+	}
+
+	tests := []struct {
+		name   string
+		alerts []storage.Alert
+		want   []string
+	}{
+		{
+			name:   "threshold counts anomalies at or above it",
+			alerts: []storage.Alert{{Name: "aws-anomaly-10", Threshold: 10, Enabled: true}},
+			want:   []string{"aws-anomaly-10: 2 anomalies with $52.25 impact (alert at $10.00 each)"},
+		},
+		{
+			name:   "no anomaly large enough",
+			alerts: []storage.Alert{{Name: "aws-anomaly-100", Threshold: 100, Enabled: true}},
+		},
+		{
+			name:   "disabled alert",
+			alerts: []storage.Alert{{Name: "aws-anomaly-1", Threshold: 1, Enabled: false}},
+		},
+		{
+			name: "other alerts are ignored",
+			alerts: []storage.Alert{
+				{Name: "aws-threshold-80", Threshold: 80, Enabled: true},
+				{Name: "budget-1", Threshold: 1, Enabled: true},
+				{Name: "azure-anomaly-1", Threshold: 1, Enabled: true},
+			},
+		},
+		{
+			name: "each anomaly alert is checked",
+			alerts: []storage.Alert{
+				{Name: "aws-anomaly-1", Threshold: 1, Enabled: true},
+				{Name: "aws-anomaly-40", Threshold: 40, Enabled: true},
+			},
+			want: []string{
+				"aws-anomaly-1: 3 anomalies with $56.75 impact (alert at $1.00 each)",
+				"aws-anomaly-40: 1 anomalies with $42.25 impact (alert at $40.00 each)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := awsAnomalyAlerts(tt.alerts, anomalies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("awsAnomalyAlerts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	awscloud "github.com/azguard/azguard/internal/cloud/aws"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func awsBudgetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Mirror local budgets as native AWS Budgets",
+		Long: `Keep local budget alerts and AWS Budgets in sync, so AWS emails you or
+publishes to SNS even when azguard is not running.
+
+Examples:
+  azguard aws budget list
+  azguard aws budget push --email me@example.com --thresholds 50,80,100
+  azguard aws budget push --sns arn:aws:sns:us-east-1:123456789012:billing
+  azguard aws budget pull
+  azguard aws budget drift
+  azguard aws budget zero-spend --email me@example.com`,
+	}
+
+	cmd.AddCommand(awsBudgetListCmd())
+	cmd.AddCommand(awsBudgetPushCmd())
+	cmd.AddCommand(awsBudgetPullCmd())
+	cmd.AddCommand(awsBudgetDriftCmd())
+	cmd.AddCommand(awsBudgetZeroSpendCmd())
+
+	return cmd
+}
+
+func awsBudgetListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List AWS cost budgets with current spend",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			budgets, err := awsCostClient.ListBudgets(ctx)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(budgets, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Println("\n💰 AWS Budgets")
+			fmt.Println("═══════════════════════════════")
+
+			if len(budgets) == 0 {
+				fmt.Println("No AWS cost budgets found.")
+				fmt.Println("Use 'azguard aws budget push' or 'azguard aws budget zero-spend' to create one.")
+				return nil
+			}
+
+			for _, b := range budgets {
+				pct := 0.0
+				if b.Amount > 0 {
+					pct = b.ActualSpend / b.Amount * 100
+				}
+				fmt.Printf("  %-25s $%8.2f / $%8.2f %s (%5.1f%%)", b.Name, b.ActualSpend, b.Amount, b.Currency, pct)
+				if b.Forecasted > 0 {
+					fmt.Printf("  forecast $%.2f", b.Forecasted)
+				}
+				fmt.Println()
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+}
+
+func awsBudgetPushCmd() *cobra.Command {
+	var (
+		emails     []string
+		topics     []string
+		thresholds []float64
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Create or update AWS Budgets from local budget alerts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			alerts, err := db.GetAlerts()
+			if err != nil {
+				return err
+			}
+			local := localBudgetAlerts(alerts)
+
+			remote, err := awsCostClient.ListBudgets(ctx)
+			if err != nil {
+				return err
+			}
+			remoteNames := make(map[string]bool, len(remote))
+			for _, b := range remote {
+				remoteNames[b.Name] = true
+			}
+
+			fmt.Println("\n☁️  Pushing budgets to AWS Budgets")
+			fmt.Println("═══════════════════════════════")
+
+			subscribers := awscloud.NewBudgetSubscribers(emails, topics)
+			if len(subscribers) == 0 {
+				fmt.Println("⚠️  No --email or --sns given; budgets are created without notifications.")
+			}
+
+			pushed := 0
+			for _, a := range local {
+				if !a.Enabled {
+					continue
+				}
+
+				b := awscloud.Budget{
+					Name:          a.Name,
+					Amount:        a.Threshold,
+					Notifications: awscloud.NewBudgetNotifications(thresholds, subscribers),
+				}
+				action, done := "create", "created"
+				if remoteNames[a.Name] {
+					action, done = "update", "updated"
+				}
+
+				if dryRun {
+					fmt.Printf("  would %s %-20s $%.2f\n", action, a.Name, a.Threshold)
+					continue
+				}
+				if remoteNames[a.Name] {
+					err = awsCostClient.UpdateBudget(ctx, b)
+				} else {
+					err = awsCostClient.CreateBudget(ctx, b)
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✅ %s %-20s $%.2f\n", done, a.Name, a.Threshold)
+				pushed++
+			}
+
+			if len(local) == 0 {
+				fmt.Println("No local budget alerts to push.")
+				fmt.Println("Use 'azguard budget add 5' to set a $5 budget.")
+			} else if !dryRun {
+				fmt.Printf("\n%d budget(s) pushed to AWS.\n", pushed)
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&emails, "email", nil, "Email address to notify (repeatable)")
+	cmd.Flags().StringSliceVar(&topics, "sns", nil, "SNS topic ARN to notify (repeatable)")
+	cmd.Flags().Float64SliceVar(&thresholds, "thresholds", []float64{80, 100}, "Notification thresholds as percent of the budget")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be pushed without changing AWS")
+
+	return cmd
+}
+
+func awsBudgetPullCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull",
+		Short: "Import AWS cost budgets into local budget alerts",
+		Long: `Import AWS cost budgets as local budget alerts. The zero-spend budget is
+skipped; it is managed with 'aws budget zero-spend'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			remote, err := awsCostClient.ListBudgets(ctx)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("\n☁️  Pulling budgets from AWS Budgets")
+			fmt.Println("═══════════════════════════════")
+
+			remote = withoutZeroSpend(remote)
+			if len(remote) == 0 {
+				fmt.Println("No AWS cost budgets found.")
+				return nil
+			}
+
+			for _, b := range remote {
+				existing, err := db.GetAlertByName(b.Name)
+				if err != nil {
+					return err
+				}
+
+				alert := storage.Alert{
+					Name:      b.Name,
+					Threshold: b.Amount,
+					Enabled:   true,
+				}
+				switch {
+				case existing == nil:
+					if err := db.SaveAlert(alert); err != nil {
+						return err
+					}
+					fmt.Printf("✅ imported %-20s $%.2f\n", b.Name, b.Amount)
+				case cost.AmountsDiffer(existing.Threshold, b.Amount) || !existing.Enabled:
+					alert.SubscriptionID = existing.SubscriptionID
+					if err := db.UpdateAlert(alert); err != nil {
+						return err
+					}
+					fmt.Printf("✅ updated  %-20s $%.2f (was $%.2f)\n", b.Name, b.Amount, existing.Threshold)
+				default:
+					fmt.Printf("   in sync  %-20s $%.2f\n", b.Name, b.Amount)
+				}
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+}
+
+func awsBudgetDriftCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "drift",
+		Short: "Report differences between local budget alerts and AWS Budgets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			alerts, err := db.GetAlerts()
+			if err != nil {
+				return err
+			}
+
+			budgets, err := awsCostClient.ListBudgets(ctx)
+			if err != nil {
+				return err
+			}
+			budgets = withoutZeroSpend(budgets)
+			remote := make([]cost.RemoteBudget, len(budgets))
+			for i, b := range budgets {
+				remote[i] = cost.RemoteBudget{Name: b.Name, Amount: b.Amount}
+			}
+
+			drift := cost.DiffBudgets(localBudgetAlerts(alerts), remote, nil)
+			return printBudgetDrift("AWS", "aws budget", drift)
+		},
+	}
+}
+
+func awsBudgetZeroSpendCmd() *cobra.Command {
+	var (
+		emails []string
+		topics []string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "zero-spend",
+		Short: "Create AWS's zero-spend budget: alert as soon as anything is charged",
+		Long: `Create the budget from AWS's zero-spend template: a $1.00 monthly budget
+that notifies when actual spend goes over $0.01. This is the simplest way for
+free tier users to hear about the first unexpected charge.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "AWS", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			subscribers := awscloud.NewBudgetSubscribers(emails, topics)
+			if len(subscribers) == 0 {
+				return fmt.Errorf("at least one --email or --sns subscriber is required")
+			}
+
+			remote, err := awsCostClient.ListBudgets(ctx)
+			if err != nil {
+				return err
+			}
+			for _, b := range remote {
+				if b.Name == awscloud.ZeroSpendBudgetName {
+					fmt.Printf("✅ %q already exists in this account.\n", b.Name)
+					return nil
+				}
+			}
+
+			budget := awscloud.ZeroSpendBudget(subscribers)
+			if dryRun {
+				fmt.Printf("  would create %q ($%.2f, notify above $0.01)\n", budget.Name, budget.Amount)
+				return nil
+			}
+			if err := awsCostClient.CreateBudget(ctx, budget); err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Created %q\n", budget.Name)
+			for _, s := range subscribers {
+				fmt.Printf("   notifying %s %s\n", s.Type, s.Address)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&emails, "email", nil, "Email address to notify (repeatable)")
+	cmd.Flags().StringSliceVar(&topics, "sns", nil, "SNS topic ARN to notify (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created without changing AWS")
+
+	return cmd
+}
+
+func withoutZeroSpend(budgets []awscloud.Budget) []awscloud.Budget {
+	var result []awscloud.Budget
+	for _, b := range budgets {
+		if b.Name != awscloud.ZeroSpendBudgetName {
+			result = append(result, b)
+		}
+	}
+	return result
+}
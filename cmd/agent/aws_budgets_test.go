@@ -0,0 +1,19 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	awscloud "github.com/azguard/azguard/internal/cloud/aws"
+)
+
+func TestWithoutZeroSpend(t *testing.T) {
+	budgets := []awscloud.Budget{
+		{Name: awscloud.ZeroSpendBudgetName, Amount: 1},
+		{Name: "budget-25", Amount: 25},
+	}
+	want := []awscloud.Budget{{Name: "budget-25", Amount: 25}}
+	if got := withoutZeroSpend(budgets); !reflect.DeepEqual(got, want) {
+		t.Errorf("withoutZeroSpend() = %+v, want %+v", got, want)
+	}
+}
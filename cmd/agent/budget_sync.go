@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func budgetPushCmd() *cobra.Command {
+	var (
+		resourceGroup string
+		emails        []string
+		actionGroups  []string
+		thresholds    []float64
+		dryRun        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Create or update native Azure budgets from local budget alerts",
+		Long: `Mirror local budget alerts as Microsoft.Consumption budgets so Azure
+notifies you even when azguard is not running.
+
+Examples:
+  azguard budget push --email me@example.com
+  azguard budget push --resource-group my-rg --thresholds 50,80,100
+  azguard budget push --action-group /subscriptions/.../actionGroups/ops`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return refuseInDemo(cmd.CommandPath(), "Azure", demoInstead)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			alerts, err := db.GetAlerts()
+			if err != nil {
+				return err
+			}
+			local := localBudgetAlerts(alerts)
+			for _, a := range local {
+				if !a.Enabled {
+					continue
+				}
+				if err := azure.ValidateBudgetName(a.Name); err != nil {
+					return fmt.Errorf("%w; rename the alert before pushing", err)
+				}
+			}
+
+			remote, err := azureCostClient.ListBudgets(ctx, resourceGroup)
+			if err != nil {
+				return err
+			}
+			remoteByName := make(map[string]azure.Budget, len(remote))
+			for _, b := range remote {
+				remoteByName[b.Name] = b
+			}
+
+			fmt.Printf("\n☁️  Pushing budgets to %s\n", azureCostClient.BudgetScope(resourceGroup))
+			fmt.Println("═══════════════════════════════")
+
+			pushed := 0
+			for _, a := range local {
+				if !a.Enabled {
+					continue
+				}
+
+				b := azure.Budget{
+					Name:          a.Name,
+					ResourceGroup: resourceGroup,
+					Amount:        a.Threshold,
+					Notifications: azure.NewBudgetNotifications(thresholds, emails, actionGroups),
+				}
+				action, done := "create", "created"
+				if existing, ok := remoteByName[a.Name]; ok {
+					b.ETag = existing.ETag
+					b.StartDate = existing.StartDate
+					b.EndDate = existing.EndDate
+					b.TimeGrain = existing.TimeGrain
+					action, done = "update", "updated"
+				}
+
+				if dryRun {
+					fmt.Printf("  would %s %-20s $%.2f\n", action, a.Name, a.Threshold)
+					continue
+				}
+				if _, err := azureCostClient.PutBudget(ctx, b); err != nil {
+					return err
+				}
+				fmt.Printf("✅ %s %-20s $%.2f\n", done, a.Name, a.Threshold)
+				pushed++
+			}
+
+			if len(local) == 0 {
+				fmt.Println("No local budget alerts to push.")
+				fmt.Println("Use 'azguard budget add 5' to set a $5 budget.")
+			} else if !dryRun {
+				fmt.Printf("\n%d budget(s) pushed to Azure.\n", pushed)
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&resourceGroup, "resource-group", "", "Scope budgets to a resource group instead of the subscription")
+	cmd.Flags().StringSliceVar(&emails, "email", nil, "Email address to notify (repeatable)")
+	cmd.Flags().StringSliceVar(&actionGroups, "action-group", nil, "Action group resource ID to notify (repeatable)")
+	cmd.Flags().Float64SliceVar(&thresholds, "thresholds", []float64{80, 100}, "Notification thresholds as percent of the budget")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be pushed without changing Azure")
+
+	return cmd
+}
+
+func budgetPullCmd() *cobra.Command {
+	var resourceGroup string
+
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Import native Azure budgets into local budget alerts",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return refuseInDemo(cmd.CommandPath(), "Azure", demoInstead)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			remote, err := azureCostClient.ListBudgets(ctx, resourceGroup)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\n☁️  Pulling budgets from %s\n", azureCostClient.BudgetScope(resourceGroup))
+			fmt.Println("═══════════════════════════════")
+
+			if len(remote) == 0 {
+				fmt.Println("No Azure budgets found.")
+				return nil
+			}
+
+			for _, b := range remote {
+				existing, err := db.GetAlertByName(b.Name)
+				if err != nil {
+					return err
+				}
+
+				alert := storage.Alert{
+					Name:           b.Name,
+					Threshold:      b.Amount,
+					SubscriptionID: azureCostClient.SubscriptionID,
+					Enabled:        true,
+				}
+				switch {
+				case existing == nil:
+					if err := db.SaveAlert(alert); err != nil {
+						return err
+					}
+					fmt.Printf("✅ imported %-20s $%.2f\n", b.Name, b.Amount)
+				case cost.AmountsDiffer(existing.Threshold, b.Amount) || !existing.Enabled:
+					if err := db.UpdateAlert(alert); err != nil {
+						return err
+					}
+					fmt.Printf("✅ updated  %-20s $%.2f (was $%.2f)\n", b.Name, b.Amount, existing.Threshold)
+				default:
+					fmt.Printf("   in sync  %-20s $%.2f\n", b.Name, b.Amount)
+				}
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&resourceGroup, "resource-group", "", "Read budgets from a resource group instead of the subscription")
+
+	return cmd
+}
+
+func budgetDriftCmd() *cobra.Command {
+	var (
+		resourceGroup string
+		emails        []string
+		actionGroups  []string
+		thresholds    []float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Report differences between local budget alerts and Azure budgets",
+		Long: `Compare local budget alerts with Azure budgets: missing budgets, amounts
+and the notifications 'budget push' would set with the same flags.
+
+Examples:
+  azguard budget drift
+  azguard budget drift --email me@example.com --thresholds 50,80,100`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return refuseInDemo(cmd.CommandPath(), "Azure", demoInstead)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			alerts, err := db.GetAlerts()
+			if err != nil {
+				return err
+			}
+
+			budgets, err := azureCostClient.ListBudgets(ctx, resourceGroup)
+			if err != nil {
+				return err
+			}
+			remote := make([]cost.RemoteBudget, len(budgets))
+			for i, b := range budgets {
+				remote[i] = cost.RemoteBudget{Name: b.Name, Amount: b.Amount, Notifications: budgetNotifications(b.Notifications)}
+			}
+
+			want := budgetNotifications(azure.NewBudgetNotifications(thresholds, emails, actionGroups))
+			drift := cost.DiffBudgets(localBudgetAlerts(alerts), remote, want)
+			return printBudgetDrift("Azure", "budget", drift)
+		},
+	}
+
+	cmd.Flags().StringVar(&resourceGroup, "resource-group", "", "Compare against a resource group instead of the subscription")
+	cmd.Flags().StringSliceVar(&emails, "email", nil, "Expected email address to notify (repeatable)")
+	cmd.Flags().StringSliceVar(&actionGroups, "action-group", nil, "Expected action group resource ID to notify (repeatable)")
+	cmd.Flags().Float64SliceVar(&thresholds, "thresholds", []float64{80, 100}, "Expected notification thresholds as percent of the budget")
+
+	return cmd
+}
+
+// budgetNotifications flattens the enabled notifications of an Azure budget;
+// roles are listed as "role:<name>".
+func budgetNotifications(notifications map[string]azure.BudgetNotification) *cost.BudgetNotifications {
+	result := &cost.BudgetNotifications{}
+	seen := make(map[string]bool)
+	for _, n := range notifications {
+		if !n.Enabled {
+			continue
+		}
+		result.Thresholds = append(result.Thresholds, n.Threshold)
+
+		var contacts []string
+		contacts = append(contacts, n.ContactEmails...)
+		contacts = append(contacts, n.ContactGroups...)
+		for _, r := range n.ContactRoles {
+			contacts = append(contacts, "role:"+r)
+		}
+		for _, c := range contacts {
+			if !seen[c] {
+				seen[c] = true
+				result.Contacts = append(result.Contacts, c)
+			}
+		}
+	}
+	sort.Float64s(result.Thresholds)
+	sort.Strings(result.Contacts)
+	return result
+}
+
+// localBudgetAlerts drops the provider alerts that share the alerts table,
+// leaving the dollar budgets that are mirrored to Azure and AWS.
+func localBudgetAlerts(alerts []storage.Alert) []storage.Alert {
+	var result []storage.Alert
+	for _, a := range alerts {
+		if cost.IsBudgetAlert(a.Name, registry.Names()) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// printBudgetDrift reports drift; command is the parent of push/pull, e.g. "budget" or "aws budget".
+func printBudgetDrift(provider, command string, drift []cost.BudgetDrift) error {
+	if outputFormat == "json" {
+		b, err := json.MarshalIndent(drift, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Printf("\n🔀 Budget Drift (local vs %s)\n", provider)
+	fmt.Println("─────────────────────────────")
+
+	if len(drift) == 0 {
+		fmt.Println("✅ Local budgets and " + provider + " budgets are in sync.")
+		return nil
+	}
+
+	for _, d := range drift {
+		switch d.Kind {
+		case cost.DriftMissingRemote:
+			fmt.Printf("  + %-20s $%.2f only in azguard (run '%s push')\n", d.Name, d.LocalAmount, command)
+		case cost.DriftMissingLocal:
+			fmt.Printf("  - %-20s $%.2f only in %s (run '%s pull')\n", d.Name, d.RemoteAmount, provider, command)
+		case cost.DriftAmount:
+			fmt.Printf("  ~ %-20s local $%.2f, %s $%.2f\n", d.Name, d.LocalAmount, provider, d.RemoteAmount)
+		case cost.DriftNotifications:
+			fmt.Printf("  ~ %-20s notifications differ: local %s, %s %s\n", d.Name, formatNotifications(d.LocalNotifications), provider, formatNotifications(d.RemoteNotifications))
+		case cost.DriftDisabled:
+			fmt.Printf("  ! %-20s disabled locally but still active in %s\n", d.Name, provider)
+		}
+	}
+	return nil
+}
+
+func formatNotifications(n *cost.BudgetNotifications) string {
+	if n == nil {
+		return "none"
+	}
+	thresholds := make([]string, len(n.Thresholds))
+	for i, t := range n.Thresholds {
+		thresholds[i] = fmt.Sprintf("%g%%", t)
+	}
+	contacts := "nobody"
+	if len(n.Contacts) > 0 {
+		contacts = strings.Join(n.Contacts, ", ")
+	}
+	return fmt.Sprintf("%s to %s", strings.Join(thresholds, "/"), contacts)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/demo"
+	"github.com/spf13/cobra"
+)
+
+// demoDBPath is the demo database, next to the real one so demo data never
+// mixes with real costs.
+func demoDBPath() string {
+	return filepath.Join(filepath.Dir(cfg.Storage.Path), "demo.db")
+}
+
+// isDemoCommand reports whether cmd is one of the demo commands, which always
+// work on the demo database.
+func isDemoCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "demo" && c.Parent() != nil && c.Parent().Parent() == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// refuseInDemo fails a command that calls a cloud API in demo mode, where it
+// would reach a real account or store real data in the demo database.
+// instead suggests what to run in its place.
+func refuseInDemo(command, cloudName, instead string) error {
+	if !demoMode {
+		return nil
+	}
+	return fmt.Errorf("'%s' talks to %s directly and is not available in demo mode; %s", command, cloudName, instead)
+}
+
+// demoInstead is the suggestion for commands with no offline mode.
+const demoInstead = "use 'azguard --demo status', 'scan' or 'cost' instead"
+
+// newDemoRegistry serves each provider from the demo database. Providers the
+// profile has no account for are left out like unconfigured ones.
+func newDemoRegistry() *cloud.Registry {
+	r := cloud.NewRegistry()
+	for _, name := range []string{"azure", "aws", "gcp"} {
+		name := name
+		r.Register(name, func() (cloud.Provider, error) {
+			return demo.NewProvider(name, db), nil
+		})
+	}
+	return r
+}
+
+func demoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Synthetic cost history for workshops and demos",
+	}
+
+	cmd.AddCommand(demoSeedCmd())
+
+	return cmd
+}
+
+func demoSeedCmd() *cobra.Command {
+	var (
+		months  int
+		profile string
+		seed    int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the demo database with a synthetic cost history",
+		Long: `Generate months of daily costs with noise, weekday effects and growth,
+free tier usage, credits, alerts and a planted overage, and write them to a
+separate demo database (demo.db next to the real one). Seeding again replaces
+the previous demo data.
+
+Run any command with --demo, or set AZGUARD_DEMO=1, to use the demo data
+instead of real accounts. 'aws' and 'gcp' status, scan and resources work on
+the demo data too. Commands that call a cloud API, such as 'budget push',
+'estimate', 'aws cost', 'aws budget' or 'gcp cost', refuse to run in demo
+mode; 'recommendations', 'cost by-tag' and 'aws anomalies' work with --cached.
+
+Profiles:
+` + profileList() + `
+Examples:
+  azguard demo seed --months 6 --profile student-vm-overrun
+  azguard --demo status
+  AZGUARD_DEMO=1 azguard cost history`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := demo.GetProfile(profile)
+			if err != nil {
+				return err
+			}
+
+			summary, err := demo.Seed(db, p, months, time.Now(), seed)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(summary, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Printf("\n🎓 Demo Data: %s\n", p.Name)
+			fmt.Println("═══════════════════════════════")
+			fmt.Println(p.Description)
+			fmt.Printf("\n%s to %s, %d cost records\n", summary.StartDate, summary.EndDate, summary.Records)
+
+			var names []string
+			for name := range summary.Totals {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Println("─────────────────────────────────")
+			for _, name := range names {
+				fmt.Printf("%-10s $%.2f\n", providerLabel(name)+":", summary.Totals[name])
+			}
+
+			for _, o := range summary.Overages {
+				fmt.Printf("\n💸 Planted overage: %s\n", o)
+			}
+			if len(summary.Alerts) > 0 {
+				fmt.Printf("🔔 Alerts: %s\n", strings.Join(summary.Alerts, ", "))
+			}
+			fmt.Printf("\n✅ Written to %s\n", cfg.Storage.Path)
+			fmt.Println("Try: azguard --demo status")
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&months, "months", 6, "Months of history to generate, including the current one")
+	cmd.Flags().StringVar(&profile, "profile", "student-vm-overrun", "Demo profile: "+strings.Join(demo.ProfileNames(), ", "))
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Random seed; the same seed generates the same history")
+
+	return cmd
+}
+
+func profileList() string {
+	var b strings.Builder
+	for _, name := range demo.ProfileNames() {
+		p, _ := demo.GetProfile(name)
+		fmt.Fprintf(&b, "  %-20s %s\n", name, p.Description)
+	}
+	return b.String()
+}
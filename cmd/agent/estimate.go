@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/spf13/cobra"
+)
+
+func estimateCmd() *cobra.Command {
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:   "estimate",
+		Short: "Estimate monthly cost of a resource before creating it",
+		Long: `Price a resource with the public Azure Retail Prices API and subtract any
+matching free tier allowance. Prices are cached locally, so estimates also
+work offline once a query has been run.
+
+Examples:
+  azguard estimate vm --sku Standard_B2s --region westeurope --hours 730
+  azguard estimate disk --sku P6 --region eastus --count 2
+  azguard estimate storage --gb 100 --tier hot --region eastus
+  azguard estimate appservice --sku B1 --region westeurope`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Root().PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+			if offline {
+				return nil
+			}
+			return refuseInDemo("azguard estimate", "the Azure Retail Prices API", "use --offline for cached prices")
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&offline, "offline", false, "Use cached prices only")
+
+	newEstimator := func() *cost.Estimator {
+		e := cost.NewEstimator(db, azure.NewPricesClient())
+		e.Offline = offline
+		return e
+	}
+
+	var (
+		vmSKU, vmRegion, vmOS string
+		vmHours               float64
+	)
+	vm := &cobra.Command{
+		Use:   "vm",
+		Short: "Estimate a virtual machine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			est, err := newEstimator().EstimateVM(context.Background(), vmSKU, vmRegion, vmOS, vmHours)
+			if err != nil {
+				return err
+			}
+			return printEstimate(est)
+		},
+	}
+	vm.Flags().StringVar(&vmSKU, "sku", "Standard_B1s", "VM size, e.g. Standard_B2s")
+	vm.Flags().StringVar(&vmRegion, "region", "eastus", "Azure region, e.g. westeurope")
+	vm.Flags().StringVar(&vmOS, "os", "linux", "Operating system: linux or windows")
+	vm.Flags().Float64Var(&vmHours, "hours", 730, "Hours running per month")
+
+	var (
+		diskSKU, diskRegion, diskRedundancy string
+		diskCount                           float64
+	)
+	disk := &cobra.Command{
+		Use:   "disk",
+		Short: "Estimate managed disks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			est, err := newEstimator().EstimateDisk(context.Background(), diskSKU, diskRegion, diskRedundancy, diskCount)
+			if err != nil {
+				return err
+			}
+			return printEstimate(est)
+		},
+	}
+	disk.Flags().StringVar(&diskSKU, "sku", "P6", "Disk tier, e.g. P6, E10, S4")
+	disk.Flags().StringVar(&diskRegion, "region", "eastus", "Azure region")
+	disk.Flags().StringVar(&diskRedundancy, "redundancy", "LRS", "Redundancy: LRS or ZRS")
+	disk.Flags().Float64Var(&diskCount, "count", 1, "Number of disks")
+
+	var (
+		storageTier, storageRedundancy, storageRegion string
+		storageGB                                     float64
+	)
+	storageEst := &cobra.Command{
+		Use:   "storage",
+		Short: "Estimate blob storage capacity",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			est, err := newEstimator().EstimateStorage(context.Background(), storageTier, storageRedundancy, storageRegion, storageGB)
+			if err != nil {
+				return err
+			}
+			return printEstimate(est)
+		},
+	}
+	storageEst.Flags().StringVar(&storageTier, "tier", "hot", "Access tier: hot, cool, cold or archive")
+	storageEst.Flags().StringVar(&storageRedundancy, "redundancy", "LRS", "Redundancy: LRS, ZRS, GRS, RA-GRS")
+	storageEst.Flags().StringVar(&storageRegion, "region", "eastus", "Azure region")
+	storageEst.Flags().Float64Var(&storageGB, "gb", 10, "Stored GB per month")
+
+	var (
+		planSKU, planRegion, planOS string
+		planHours                   float64
+	)
+	appService := &cobra.Command{
+		Use:     "appservice",
+		Aliases: []string{"app-service"},
+		Short:   "Estimate an App Service plan",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			est, err := newEstimator().EstimateAppService(context.Background(), planSKU, planRegion, planOS, planHours)
+			if err != nil {
+				return err
+			}
+			return printEstimate(est)
+		},
+	}
+	appService.Flags().StringVar(&planSKU, "sku", "B1", "Plan SKU, e.g. F1, B1, S1, P1v3")
+	appService.Flags().StringVar(&planRegion, "region", "eastus", "Azure region")
+	appService.Flags().StringVar(&planOS, "os", "linux", "Operating system: linux or windows")
+	appService.Flags().Float64Var(&planHours, "hours", 730, "Hours running per month")
+
+	cmd.AddCommand(vm, disk, storageEst, appService)
+
+	return cmd
+}
+
+func printEstimate(est *cost.Estimate) error {
+	if outputFormat == "json" {
+		b, err := json.MarshalIndent(est, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Printf("\n🧮 Cost Estimate - %s %s (%s)\n", est.Resource, est.SKU, est.Region)
+	fmt.Println("═══════════════════════════════")
+	fmt.Printf("Meter:          %s\n", est.Meter)
+	fmt.Printf("Unit price:     $%.4f %s\n", est.UnitPrice, est.Currency)
+	fmt.Printf("Quantity:       %.0f %s\n", est.Quantity, est.Unit)
+	fmt.Printf("Before free:    $%.2f/month\n", est.GrossCost)
+	if est.FreeAllowance > 0 {
+		fmt.Printf("Free allowance: -%.0f %s (%s)\n", est.FreeAllowance, est.Unit, est.FreeService)
+	} else {
+		fmt.Println("Free allowance: none for this SKU")
+	}
+	fmt.Printf("Monthly cost:   %s\n", formatMoney(est.MonthlyCost, est.Currency))
+	fmt.Printf("Prices from:    %s\n", est.PriceSource)
+
+	if est.FreeAllowance > 0 {
+		fmt.Println("\nNote: assumes nothing else in the subscription is using this free allowance.")
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/azguard/azguard/internal/focus"
+	"github.com/azguard/azguard/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func exportCmd() *cobra.Command {
+	var (
+		format    string
+		provider  string
+		startDate string
+		endDate   string
+		outPath   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export stored cost data in the FinOps FOCUS format",
+		Long: `Export stored cost records, from every provider, as one FinOps Open Cost
+and Usage Specification (FOCUS) CSV file that FinOps tools can read.
+
+Each daily record becomes one charge row with BilledCost, ServiceName,
+ProviderName, ChargePeriodStart and the other FOCUS columns azguard can fill.
+The file can be imported again with 'azguard import --format focus-csv';
+rows for days whose costs are already synced in that database are skipped.
+
+Examples:
+  azguard export --format focus-csv > costs.csv
+  azguard export --format focus-csv --provider aws --start 2024-01-01 --out aws.csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "focus-csv" {
+				return fmt.Errorf("unknown export format %q (use focus-csv)", format)
+			}
+			filter, err := providerFilter(provider)
+			if err != nil {
+				return err
+			}
+
+			records, err := db.GetCostRecords(storage.CostFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Provider:  filter,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read cost records: %w", err)
+			}
+
+			var out io.Writer = os.Stdout
+			if outPath != "" {
+				f, err := os.Create(outPath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+
+			w, err := focus.NewWriter(out)
+			if err != nil {
+				return err
+			}
+			// Records come newest first; write them in date order.
+			for i := len(records) - 1; i >= 0; i-- {
+				row, err := focus.FromRecord(records[i])
+				if err != nil {
+					return fmt.Errorf("record %d: %w", records[i].ID, err)
+				}
+				if err := w.Write(row); err != nil {
+					return err
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+
+			if outPath != "" {
+				fmt.Fprintf(os.Stderr, "✅ Exported %d rows to %s\n", len(records), outPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "focus-csv", "Export format: focus-csv")
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to export (azure, aws, gcp or all)")
+	cmd.Flags().StringVar(&startDate, "start", "", "First day to export (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&endDate, "end", "", "Last day to export (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&outPath, "out", "", "Write to this file instead of stdout")
+
+	return cmd
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	gcpcloud "github.com/azguard/azguard/internal/cloud/gcp"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/providers"
+	"github.com/azguard/azguard/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var gcpClient *gcpcloud.Client
+
+func gcpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gcp",
+		Short: "GCP free tier monitoring and costs",
+		Long: `Monitor a Google Cloud project's always-free usage and costs.
+
+Costs and usage come from the Cloud Billing export to BigQuery, which must be
+enabled for the billing account. Set gcp.billing_table to the export table.
+
+Examples:
+  azguard gcp status              Check spend and always-free usage
+  azguard gcp scan                List usage of each always-free allowance
+  azguard gcp cost                View and store this month's costs
+  azguard gcp resources           Flag resources outside the always-free tier`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Root().PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+			// The demo registry serves status, scan and resources; 'gcp cost'
+			// queries BigQuery and refuses to run itself.
+			if demoMode {
+				return nil
+			}
+
+			p, err := registry.Get("gcp")
+			if err != nil {
+				return err
+			}
+			provider, ok := p.(*providers.GCP)
+			if !ok {
+				return fmt.Errorf("unexpected GCP provider %T", p)
+			}
+			gcpClient = provider.Client
+
+			if !gcpClient.IsConfigured() {
+				fmt.Println("⚠️  GCP credentials not found.")
+				fmt.Println("Configure with: gcloud auth application-default login")
+				fmt.Println("Or set: GOOGLE_APPLICATION_CREDENTIALS to a service account key file")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(gcpStatusCmd())
+	cmd.AddCommand(gcpScanCmd())
+	cmd.AddCommand(gcpCostCmd())
+	cmd.AddCommand(gcpResourcesCmd())
+
+	return cmd
+}
+
+func gcpStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Check your GCP spend and always-free usage summary",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(context.Background(), "gcp")
+		},
+	}
+}
+
+func gcpScanCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scan",
+		Short: "Scan GCP always-free allowances approaching their limits",
+		Long: `Sum this month's usage of the SKUs behind each always-free allowance in
+gcp_free_tier_limits.yaml, store a snapshot and flag allowances at or above
+their warning threshold. Usage in the billing export lags by up to a day.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScan(context.Background(), "gcp")
+		},
+	}
+}
+
+func gcpCostCmd() *cobra.Command {
+	var months int
+
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "View GCP costs for the current month and store them locally",
+		Long: `Query daily costs, net of credits, from the billing export and store them,
+so 'cost history --provider gcp' and reports include GCP. Re-running replaces
+what was stored for the period. --months fetches earlier months as well.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := refuseInDemo(cmd.CommandPath(), "GCP", demoInstead); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			if !gcpClient.IsConfigured() {
+				return fmt.Errorf("GCP credentials not configured")
+			}
+
+			startDate, endDate := cost.GetCurrentMonthDateRange()
+			fetchStart := startDate
+			if months > 1 {
+				first, _ := time.Parse("2006-01-02", startDate)
+				fetchStart = first.AddDate(0, -(months - 1), 0).Format("2006-01-02")
+			}
+
+			n, err := costSvc.FetchAndStoreCosts(ctx, "gcp", fetchStart, endDate)
+			if err != nil {
+				return err
+			}
+
+			byService, err := db.GetAggregatedCosts(storage.CostFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Provider:  "gcp",
+				AccountID: gcpClient.ProjectID,
+				GroupBy:   "ServiceName",
+			})
+			if err != nil {
+				return err
+			}
+			total := 0.0
+			for _, c := range byService {
+				total += c
+			}
+			// Billing export costs are in the billing account's currency.
+			currency := "USD"
+			byCurrency, err := db.GetAggregatedCosts(storage.CostFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Provider:  "gcp",
+				AccountID: gcpClient.ProjectID,
+				GroupBy:   "Currency",
+			})
+			if err != nil {
+				return err
+			}
+			for c := range byCurrency {
+				currency = c
+			}
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(struct {
+					ProjectID string             `json:"project_id"`
+					StartDate string             `json:"start_date"`
+					EndDate   string             `json:"end_date"`
+					TotalCost float64            `json:"total_cost"`
+					Currency  string             `json:"currency"`
+					ByService map[string]float64 `json:"by_service"`
+				}{gcpClient.ProjectID, startDate, endDate, total, currency, byService}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Println("\n📊 GCP Cost Breakdown")
+			fmt.Println("═══════════════════════════════")
+			fmt.Printf("Project: %s\n", gcpClient.ProjectID)
+			fmt.Printf("Period: %s to %s\n", startDate, endDate)
+			fmt.Printf("Total: %s\n", formatMoney(total, currency))
+
+			services := make([]string, 0, len(byService))
+			for svc := range byService {
+				services = append(services, svc)
+			}
+			sort.Slice(services, func(i, j int) bool { return byService[services[i]] > byService[services[j]] })
+			if len(services) > 0 {
+				fmt.Println("\nBy Service:")
+				fmt.Println("─────────────────────────────────")
+				for _, svc := range services {
+					if c := byService[svc]; c > 0.001 || c < -0.001 {
+						fmt.Printf("  %-35s $%.4f %s\n", svc+":", c, currency)
+					}
+				}
+			}
+
+			if total <= 0 {
+				fmt.Println("\n✅ No charges this month — still within the free tier!")
+			}
+			fmt.Printf("\nStored %d daily records (%s to %s)\n", n, fetchStart, endDate)
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&months, "months", 1, "Number of months to store, including the current one")
+	return cmd
+}
+
+func gcpResourcesCmd() *cobra.Command {
+	var billable bool
+
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List Compute and Storage resources and flag those outside the always-free tier",
+		Long: `List VM instances, persistent disks, external static IPs and Cloud Storage
+buckets in the project, and check each against the machine type, disk type,
+storage class and region rules in gcp_free_tier_limits.yaml. The always-free
+e2-micro, standard persistent disk and Standard storage are only free in
+us-west1, us-central1 and us-east1.
+
+Examples:
+  azguard gcp resources
+  azguard gcp resources --billable`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runResources(context.Background(), "gcp", billable)
+		},
+	}
+
+	cmd.Flags().BoolVar(&billable, "billable", false, "Only show resources outside the always-free tier")
+
+	return cmd
+}
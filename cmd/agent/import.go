@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azguard/azguard/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+func importCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "import <file>...",
+		Short: "Import billing files downloaded from Azure, AWS or GCP",
+		Long: `Import billing export files for accounts that cannot grant API access.
+Files may be CSV or gzip-compressed CSV and are streamed, so large exports
+import without loading into memory.
+
+Formats:
+  azure-export  Azure Cost Management export (actual or amortized cost)
+  aws-cur       AWS Cost and Usage Report, legacy or CUR 2.0 columns
+  gcp-export    GCP Cloud Billing export or console cost table
+  focus-csv     FinOps FOCUS CSV, e.g. from 'azguard export'; the provider
+                is read from each row's ProviderName
+
+Importing the same file again, or a revised export of the same period,
+replaces the stored line items instead of adding them twice. Line items are
+matched on their date, account, resource, meter or SKU and charge type, never
+on their cost.
+
+Imported days take precedence over the API: 'cost fetch' and 'aws fetch' keep
+the imported line items of an account and skip the API's costs for those days.
+
+Examples:
+  azguard import --format azure-export costs-2024-05.csv
+  azguard import --format aws-cur cur-00001.csv.gz cur-00002.csv.gz
+  azguard import --format gcp-export billing.csv
+  azguard import --format focus-csv focus.csv`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				return fmt.Errorf("--format is required (%s)", strings.Join(importer.Formats(), ", "))
+			}
+
+			var results []*importer.Result
+			for _, path := range args {
+				result, err := importer.ImportFile(db, format, path)
+				if err != nil {
+					return fmt.Errorf("failed to import %s: %w", path, err)
+				}
+				results = append(results, result)
+
+				if outputFormat == "json" {
+					continue
+				}
+				fmt.Printf("✅ %s: %d new, %d updated, %d skipped of %d rows\n",
+					path, result.Added, result.Updated, result.Skipped, result.Rows)
+				if result.StartDate != "" {
+					fmt.Printf("   %s to %s, $%.2f total\n", result.StartDate, result.EndDate, result.TotalCost)
+				}
+			}
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "File format: "+strings.Join(importer.Formats(), ", "))
+
+	return cmd
+}
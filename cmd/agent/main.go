@@ -5,11 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
+	"sort"
 
 	"github.com/azguard/azguard/internal/cloud/azure"
 	"github.com/azguard/azguard/internal/config"
 	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/httprec"
 	"github.com/azguard/azguard/internal/storage"
 	"github.com/spf13/cobra"
 )
@@ -22,17 +23,31 @@ var (
 )
 
 var (
-	cfg          *config.Config
-	db           *storage.DB
-	costSvc      *cost.Service
-	outputFormat string
+	cfg             *config.Config
+	db              *storage.DB
+	costSvc         *cost.Service
+	azureCostClient *azure.CostClient
+	outputFormat    string
+	// demoMode serves every provider from the demo database.
+	demoMode bool
+	// exitCode is set by commands that report a status through it.
+	exitCode int
 )
 
+// exitCommandError is the exit code of a command that failed. Status exit
+// codes, see statusExitCodes, never use it.
+const exitCommandError = 1
+
 func main() {
+	if err := httprec.Install(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCommandError)
+	}
+
 	rootCmd := &cobra.Command{
 		Use:   "azguard",
-	Short: "azguard - Protect against Azure and AWS free tier bill shock",
-		Long: `One command to make sure your Azure and AWS free tiers don't surprise you with a bill.
+	Short: "azguard - Protect against Azure, AWS and GCP free tier bill shock",
+		Long: `One command to make sure your Azure, AWS and GCP free tiers don't surprise you with a bill.
 		
 Examples:
   azguard scan              Scan for free tier overages
@@ -46,6 +61,14 @@ Examples:
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
+			demo := demoMode || isDemoCommand(cmd)
+			if demo {
+				cfg.Storage.Path = demoDBPath()
+			}
+			if demoMode {
+				fmt.Fprintf(os.Stderr, "🎓 Demo mode: synthetic data from %s\n", cfg.Storage.Path)
+			}
+
 			db, err = storage.New(cfg.Storage.Path)
 			if err != nil {
 				return fmt.Errorf("failed to initialize database: %w", err)
@@ -60,8 +83,20 @@ Examples:
 				return fmt.Errorf("failed to create token provider: %w", err)
 			}
 
-			azureCostClient := azure.NewCostClient(cfg.Azure.SubscriptionID, tokenProvider)
-			costSvc = cost.NewService(db, azureCostClient)
+			// Replayed requests carry no real token or subscription.
+			if rep := httprec.Replaying(); rep != nil && rep.HasHost(".azure.com") {
+				tokenProvider = func() (string, error) { return httprec.Redacted, nil }
+				if cfg.Azure.SubscriptionID == "" {
+					cfg.Azure.SubscriptionID = httprec.ReplaySubscriptionID
+				}
+			}
+
+			azureCostClient = azure.NewCostClient(cfg.Azure.SubscriptionID, tokenProvider)
+			registry = newRegistry()
+			if demo {
+				registry = newDemoRegistry()
+			}
+			costSvc = cost.NewService(db, registry)
 
 			return nil
 		},
@@ -74,6 +109,8 @@ Examples:
 	}
 
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table, json, csv")
+	rootCmd.PersistentFlags().BoolVar(&demoMode, "demo", os.Getenv("AZGUARD_DEMO") != "", "Use the synthetic data from 'azguard demo seed' instead of real accounts (or set AZGUARD_DEMO=1)")
+	rootCmd.PersistentFlags().StringVar(&awsProfile, "aws-profile", "", "AWS shared config profile (overrides aws.profile and AWS_PROFILE)")
 
 	// Add version flag
 	var showVersion bool
@@ -94,15 +131,23 @@ Examples:
 	rootCmd.AddCommand(budgetCmd())
 	rootCmd.AddCommand(resourcesCmd())
 	rootCmd.AddCommand(cleanupCmd())
+	rootCmd.AddCommand(recommendationsCmd())
 	rootCmd.AddCommand(statusCmd())
 	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(costCmd())
+	rootCmd.AddCommand(estimateCmd())
 	rootCmd.AddCommand(awsCmd())
+	rootCmd.AddCommand(gcpCmd())
+	rootCmd.AddCommand(importCmd())
+	rootCmd.AddCommand(exportCmd())
+	rootCmd.AddCommand(devCmd())
+	rootCmd.AddCommand(demoCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCommandError)
 	}
+	os.Exit(exitCode)
 }
 
 func versionCmd() *cobra.Command {
@@ -118,139 +163,46 @@ func versionCmd() *cobra.Command {
 }
 
 func statusCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "status",
-		Short: "Quick overview of your Azure free tier status",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-			summary, err := costSvc.GetCurrentCosts(ctx)
-			if err != nil {
-				return err
-			}
-
-			// Calculate free tier status
-			limit := 200.0 // Approximate monthly free tier value in USD
-			percentUsed := (summary.TotalCost / limit) * 100
-
-			fmt.Println("\n🛡️  Azure Free Tier Status")
-			fmt.Println("═══════════════════════════════")
-			fmt.Printf("Subscription: %s\n", cfg.Azure.SubscriptionID)
-			fmt.Printf("Current Spend: $%.2f / $%.2f free\n", summary.TotalCost, limit)
+	var provider string
 
-			if percentUsed >= 100 {
-				fmt.Println("⚠️  Status: OVER LIMIT")
-			} else if percentUsed >= 80 {
-				fmt.Println("⚠️  Status: WARNING (>80%)")
-			} else {
-				fmt.Println("✅ Status: OK")
-			}
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Quick overview of your free tier status",
+		Long: `Fetch every configured provider in parallel and show each one's spend,
+free tier usage, credit left and triggered alerts, with the combined spend.
+Use --provider to check one provider only.
 
-			// Check alerts
-			alerts, err := db.GetAlerts()
-			if err == nil && len(alerts) > 0 {
-				fmt.Printf("\n🔔 Active Alerts: %d\n", len(alerts))
-				for _, a := range alerts {
-					if a.Enabled {
-						triggered := ""
-						if summary.TotalCost >= a.Threshold {
-							triggered = " (TRIGGERED)"
-						}
-						fmt.Printf("  • %s: $%.2f%s\n", a.Name, a.Threshold, triggered)
-					}
-				}
-			}
+The exit code is the worst status found, rising with severity: 0 OK,
+2 warning, 3 a provider could not be read, 4 over limit. 1 means the
+command itself failed.
 
-			fmt.Println()
-			return nil
+Spend in different currencies is totalled per currency, never converted,
+and budget alerts are checked against each total.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(context.Background(), provider)
 		},
 	}
+
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to check (azure, aws, gcp or all)")
+	return cmd
 }
 
 func scanCmd() *cobra.Command {
-	return &cobra.Command{
+	var provider string
+
+	cmd := &cobra.Command{
 		Use:   "scan",
-		Short: "Scan subscription for free tier overages",
-		Long: `Audit your subscription against Azure free tier limits.
-Shows which services are approaching or exceeding their free allocations.`,
+		Short: "Scan for free tier overages",
+		Long: `Audit each configured provider against its free tier limits and store a
+usage snapshot. Shows which allowances are approaching or exceeding their
+free allocations.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-
-			// Fetch latest costs
-			startDate, endDate := cost.GetCurrentMonthDateRange()
-			if err := costSvc.FetchAndStoreCosts(ctx, startDate, endDate); err != nil {
-				fmt.Printf("Note: Could not fetch live data: %v\n", err)
-			}
-
-			summary, err := costSvc.GetCostSummary(cost.CostFilter{})
-			if err != nil {
-				return err
-			}
-
-			_, err = cost.LoadFreeTierConfig()
-			if err != nil {
-				return err
-			}
-
-			fmt.Println("\n🔍 Azure Free Tier Scan")
-			fmt.Println("═══════════════════════════════")
-
-			if len(summary.ByService) == 0 {
-			fmt.Println("No costs recorded yet. Run 'azguard cost fetch' first.")
-				return nil
-			}
-
-			// Check each service against free tier limits
-			limit := 200.0 // Approximate monthly free tier value
-			percentUsed := (summary.TotalCost / limit) * 100
-
-			fmt.Printf("\nTotal Spend: $%.2f / $%.2f free tier\n", summary.TotalCost, limit)
-			fmt.Printf("Usage: %.1f%%\n\n", percentUsed)
-
-			fmt.Println("By Service:")
-			fmt.Println("─────────────────────────────────")
-
-			issuesFound := false
-			for service, c := range summary.ByService {
-				status := "✅"
-				limitAmount := 0.0
-
-				// Map Azure service names to free tier limits
-				switch strings.ToLower(service) {
-				case "virtual machines", "virtualmachine":
-					limitAmount = 0.01 * 750 // B1s VM approximation
-				case "storage", "blob storage":
-					limitAmount = 0.023 * 5 // 5GB storage
-				case "functions", "azure functions":
-					limitAmount = 0.0
-				case "sql database", "sql":
-					limitAmount = 0.0
-				case "app service", "appservice":
-					limitAmount = 0.05 * 750
-				}
-
-				if limitAmount > 0 {
-					servicePercent := (c / limitAmount) * 100
-					if servicePercent >= 100 {
-						status = "❌ OVER"
-						issuesFound = true
-					} else if servicePercent >= 80 {
-						status = "⚠️  WARNING"
-						issuesFound = true
-					}
-				}
-
-				fmt.Printf("%s %-20s $%.2f\n", status, service+":", c)
-			}
-
-			if !issuesFound {
-				fmt.Println("\n✅ All services within free tier limits!")
-			} else {
-				fmt.Println("\n⚠️  Some services may have overages. Run 'azguard resources' for details.")
-			}
-			fmt.Println()
-			return nil
+			return runScan(context.Background(), provider)
 		},
 	}
+
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to scan (azure, aws, gcp or all)")
+	return cmd
 }
 
 func watchCmd() *cobra.Command {
@@ -351,6 +303,10 @@ func budgetCmd() *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(budgetPushCmd())
+	cmd.AddCommand(budgetPullCmd())
+	cmd.AddCommand(budgetDriftCmd())
+
 	cmd.AddCommand(&cobra.Command{
 		Use:   "presets",
 		Short: "Show preset budget options",
@@ -374,23 +330,25 @@ func budgetCmd() *cobra.Command {
 }
 
 func resourcesCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		provider string
+		billable bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "resources",
 		Short: "List running resources with free tier status",
-		Long:  `Show all Azure resources and their free tier status.`,
+		Long: `List the resources of each configured provider and flag the ones outside
+the free tier. Azure resources are flagged free only for types Azure never
+charges for, such as virtual networks and network security groups.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("\n📋 Azure Resources")
-			fmt.Println("═══════════════════════════════")
-			fmt.Println("This feature requires Azure CLI integration.")
-			fmt.Println("Run: az resource list --output table")
-			fmt.Println()
-			fmt.Println("To check specific resources:")
-			fmt.Println("  az vm list -o table")
-			fmt.Println("  az storage account list -o table")
-			fmt.Println("  az functionapp list -o table")
-			return nil
+			return runResources(context.Background(), provider, billable)
 		},
 	}
+
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to list (azure, aws, gcp or all)")
+	cmd.Flags().BoolVar(&billable, "billable", false, "Only show billable resources")
+	return cmd
 }
 
 func cleanupCmd() *cobra.Command {
@@ -414,6 +372,13 @@ func cleanupCmd() *cobra.Command {
 			fmt.Println()
 			fmt.Println("4. Check for orphaned disks:")
 			fmt.Println("   az disk list -o table")
+
+			recs, err := costSvc.GetStoredRecommendations(cfg.Azure.SubscriptionID)
+			if err != nil {
+				return err
+			}
+			printSuggestedActions(recs, 5)
+			fmt.Println()
 			return nil
 		},
 	}
@@ -433,6 +398,12 @@ func configCmd() *cobra.Command {
 			fmt.Println("═══════════════════════════════")
 			fmt.Printf("Azure Subscription: %s\n", cfg.Azure.SubscriptionID)
 			fmt.Printf("Auth Method: %s\n", cfg.Azure.AuthMethod)
+			if cfg.AWS.Profile != "" {
+				fmt.Printf("AWS Profile: %s\n", cfg.AWS.Profile)
+			}
+			if cfg.GCP.ProjectID != "" {
+				fmt.Printf("GCP Project: %s\n", cfg.GCP.ProjectID)
+			}
 			fmt.Printf("Storage Path: %s\n", cfg.Storage.Path)
 			fmt.Println()
 			return nil
@@ -464,62 +435,286 @@ func costCmd() *cobra.Command {
 		Short: "Advanced cost management",
 	}
 
-	cmd.AddCommand(&cobra.Command{
+	cmd.AddCommand(costCurrentCmd())
+	cmd.AddCommand(costFetchCmd())
+	cmd.AddCommand(costByTagCmd())
+	cmd.AddCommand(costHistoryCmd())
+	cmd.AddCommand(costReportCmd())
+	cmd.AddCommand(costForecastCmd())
+
+	return cmd
+}
+
+func costCurrentCmd() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
 		Use:   "current",
 		Short: "Show current month costs",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-			summary, err := costSvc.GetCurrentCosts(ctx)
+			filter, err := providerFilter(provider)
+			if err != nil {
+				return err
+			}
+			summary, err := costSvc.GetCurrentCosts(context.Background(), filter)
 			if err != nil {
 				return err
 			}
 			return printCostSummary(summary)
 		},
-	})
+	}
 
-	cmd.AddCommand(&cobra.Command{
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to fetch (azure, aws, gcp or all)")
+	return cmd
+}
+
+func costFetchCmd() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
 		Use:   "fetch",
-		Short: "Fetch and store costs from Azure",
+		Short: "Fetch and store this month's costs",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			selected, err := selectProviders(provider)
+			if err != nil {
+				return err
+			}
+
 			ctx := context.Background()
 			startDate, endDate := cost.GetCurrentMonthDateRange()
-			if err := costSvc.FetchAndStoreCosts(ctx, startDate, endDate); err != nil {
+			for _, p := range selected {
+				n, err := costSvc.FetchAndStoreCosts(ctx, p.Name(), startDate, endDate)
+				if err != nil {
+					if len(selected) == 1 {
+						return err
+					}
+					fmt.Printf("Note: %s: %v\n", p.Name(), err)
+					continue
+				}
+				fmt.Printf("✅ Stored %d %s cost records\n", n, providerLabel(p.Name()))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to fetch (azure, aws, gcp or all)")
+	return cmd
+}
+
+func costForecastCmd() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:   "forecast",
+		Short: "Show cost forecast",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := providerFilter(provider)
+			if err != nil {
+				return err
+			}
+			forecast, err := costSvc.GetForecast(context.Background(), filter)
+			if err != nil {
 				return err
 			}
-			fmt.Println("✅ Costs fetched and stored")
+			fmt.Printf("Next month forecast: %s (confidence: %s)\n", formatMoney(forecast.NextMonth, forecast.Currency), forecast.Confidence)
 			return nil
 		},
-	})
+	}
 
-	cmd.AddCommand(&cobra.Command{
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to forecast (azure, aws, gcp or all)")
+	return cmd
+}
+
+func costHistoryCmd() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
 		Use:   "history",
 		Short: "Show cost history",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			summary, err := costSvc.GetCostHistory(30)
+			filter, err := providerFilter(provider)
+			if err != nil {
+				return err
+			}
+			summary, err := costSvc.GetCostHistory(30, filter)
 			if err != nil {
 				return err
 			}
 			return printCostSummary(summary)
 		},
-	})
+	}
 
-	cmd.AddCommand(&cobra.Command{
-		Use:   "forecast",
-		Short: "Show cost forecast",
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to show (azure, aws, gcp or all)")
+	return cmd
+}
+
+func costReportCmd() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show a 12 month cost report with top services and forecast",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := providerFilter(provider)
+			if err != nil {
+				return err
+			}
+			report, err := costSvc.GenerateReport(filter)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Printf("\n📊 %s Cost Report - %s\n", providerLabel(filter), report.Period)
+			fmt.Println("═══════════════════════════════")
+			printTotal("Total", report.TotalCost, report.Currency, report.Totals)
+			if report.Currency != "" {
+				fmt.Printf("Next month forecast: %s\n", formatMoney(report.Forecast, report.Currency))
+			}
+
+			if len(report.MonthlyData) > 0 {
+				fmt.Println("\nBy Month:")
+				fmt.Println("─────────────────────────────────")
+				for _, m := range report.MonthlyData {
+					fmt.Printf("  %-20s %s\n", m.Month+":", formatMoney(m.TotalCost, m.Currency))
+				}
+			}
+
+			sort.Slice(report.TopServices, func(i, j int) bool {
+				return report.TopServices[i].Cost > report.TopServices[j].Cost
+			})
+			if len(report.TopServices) > 0 {
+				fmt.Println("\nTop Services:")
+				fmt.Println("─────────────────────────────────")
+				for i, svc := range report.TopServices {
+					if i == 10 {
+						break
+					}
+					fmt.Printf("  %-35s $%.2f\n", svc.Service+":", svc.Cost)
+				}
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&provider, "provider", "all", "Provider to report (azure, aws, gcp or all)")
+	return cmd
+}
+
+func providerLabel(provider string) string {
+	switch provider {
+	case "azure":
+		return "Azure"
+	case "aws":
+		return "AWS"
+	case "gcp":
+		return "GCP"
+	}
+	return "Cloud"
+}
+
+func costByTagCmd() *cobra.Command {
+	var cached bool
+
+	cmd := &cobra.Command{
+		Use:   "by-tag [key]",
+		Short: "Show current month costs grouped by a tag key",
+		Long: `Break down this month's Azure spend by the values of a tag, e.g. owner or env.
+Spend on resources without the tag is shown as (untagged).`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
-			forecast, err := costSvc.GetForecast(ctx)
+			tagKey := args[0]
+			startDate, endDate := cost.GetCurrentMonthDateRange()
+
+			if !cached {
+				if err := refuseInDemo(cmd.CommandPath(), "Azure", "use --cached for stored costs"); err != nil {
+					return err
+				}
+				if err := costSvc.FetchAndStoreCostsByTag(ctx, azureCostClient, tagKey, startDate, endDate); err != nil {
+					return err
+				}
+			}
+
+			summary, err := costSvc.GetCostSummaryByTag(tagKey, cost.CostFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+			})
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Next month forecast: $%.2f (confidence: %s)\n", forecast.NextMonth, forecast.Confidence)
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(summary, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Printf("\n🏷️  Azure Costs by Tag '%s' - %s\n", summary.TagKey, summary.Period)
+			fmt.Printf("Total: %s\n", formatMoney(summary.TotalCost, summary.Currency))
+
+			if len(summary.ByValue) == 0 {
+				fmt.Println("\nNo costs recorded for this period.")
+				return nil
+			}
+
+			fmt.Println()
+			for _, v := range summary.ByValue {
+				fmt.Printf("  %-25s $%.2f\n", v.Value+":", v.Cost)
+			}
+
+			if summary.Untagged > 0 {
+				fmt.Printf("\n⚠️  $%.2f is not tagged with '%s' - nobody owns this spend.\n", summary.Untagged, summary.TagKey)
+			}
+			fmt.Println()
 			return nil
 		},
-	})
+	}
+
+	cmd.Flags().BoolVar(&cached, "cached", false, "Use stored records without calling Azure")
 
 	return cmd
 }
 
+// printTotal prints total in its currency, or each of totals when they are in
+// several currencies; amounts in different currencies are never converted.
+// formatMoney formats an amount with its currency code, with a dollar sign
+// only for US dollars.
+func formatMoney(amount float64, currency string) string {
+	if currency == "" || currency == "USD" {
+		return fmt.Sprintf("$%.2f USD", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+func printTotal(label string, total float64, currency string, totals map[string]float64) {
+	if currency != "" {
+		fmt.Printf("%s: %s\n", label, formatMoney(total, currency))
+		return
+	}
+	currencies := make([]string, 0, len(totals))
+	for c := range totals {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	fmt.Printf("%s (not converted between currencies):\n", label)
+	for _, c := range currencies {
+		fmt.Printf("  %s\n", formatMoney(totals[c], c))
+	}
+}
+
 func printCostSummary(summary *cost.CostSummary) error {
 	switch outputFormat {
 	case "json":
@@ -529,8 +724,8 @@ func printCostSummary(summary *cost.CostSummary) error {
 		}
 		fmt.Println(string(b))
 	default:
-		fmt.Printf("\n📊 Azure Costs - %s\n", summary.Period)
-		fmt.Printf("Total: $%.2f %s\n", summary.TotalCost, summary.Currency)
+		fmt.Printf("\n📊 %s Costs - %s\n", providerLabel(summary.Provider), summary.Period)
+		printTotal("Total", summary.TotalCost, summary.Currency, summary.Totals)
 
 		if len(summary.ByService) > 0 {
 			fmt.Println("\nBy Service:")
@@ -538,6 +733,21 @@ func printCostSummary(summary *cost.CostSummary) error {
 				fmt.Printf("  %-20s $%.2f\n", service+":", c)
 			}
 		}
+
+		if len(summary.MonthlyBreakdown) > 0 {
+			fmt.Println("\nBy Month:")
+			for _, m := range summary.MonthlyBreakdown {
+				fmt.Printf("  %-20s %s\n", m.Month+":", formatMoney(m.TotalCost, m.Currency))
+			}
+		}
+
+		if t := summary.Trend; t != nil {
+			fmt.Printf("\nTrend: %s (%+.1f%% vs last month), projected next month $%.2f\n", t.Trend, t.ChangePercent, t.Projection)
+		}
+
+		for _, e := range summary.Errors {
+			fmt.Printf("Note: %s\n", e)
+		}
 	}
 	return nil
 }
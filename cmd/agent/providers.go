@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	awscloud "github.com/azguard/azguard/internal/cloud/aws"
+	gcpcloud "github.com/azguard/azguard/internal/cloud/gcp"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/httprec"
+	"github.com/azguard/azguard/internal/providers"
+)
+
+// registry holds the Azure, AWS and GCP providers. Each is created the first
+// time a command looks it up.
+var registry *cloud.Registry
+
+func newRegistry() *cloud.Registry {
+	r := cloud.NewRegistry()
+	r.Register("azure", func() (cloud.Provider, error) {
+		return providers.NewAzure(azureCostClient), nil
+	})
+	r.Register("aws", func() (cloud.Provider, error) {
+		profile := awsProfile
+		if profile == "" {
+			profile = cfg.AWS.Profile
+		}
+		accessKey, secretKey := cfg.AWS.AccessKey, cfg.AWS.SecretKey
+		// Replayed requests are not checked, but still need signing keys.
+		if rep := httprec.Replaying(); rep != nil && rep.HasAWS() && accessKey == "" {
+			accessKey, secretKey = httprec.Redacted, httprec.Redacted
+		}
+		return providers.NewAWS(awscloud.NewCostClient(
+			accessKey,
+			secretKey,
+			cfg.AWS.SessionToken,
+			cfg.AWS.Region,
+			profile,
+		)), nil
+	})
+	r.Register("gcp", func() (cloud.Provider, error) {
+		client, err := gcpcloud.NewClient(cfg.GCP.ProjectID, cfg.GCP.CredentialsFile, cfg.GCP.BillingTable)
+		if err != nil {
+			return nil, err
+		}
+		return providers.NewGCP(client), nil
+	})
+	return r
+}
+
+// providerFilter validates a --provider value for commands that read stored
+// costs, where "all" means no filter.
+func providerFilter(provider string) (string, error) {
+	if _, err := registry.Select(provider); err != nil {
+		return "", err
+	}
+	if provider == "all" {
+		return "", nil
+	}
+	return provider, nil
+}
+
+// selectProviders resolves a --provider value to providers. With several
+// providers, ones that are not configured are left out and ones that cannot
+// be created are reported as a note.
+func selectProviders(provider string) ([]cloud.Provider, error) {
+	names, err := registry.Select(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []cloud.Provider
+	for _, name := range names {
+		p, err := registry.Get(name)
+		if err != nil {
+			if len(names) == 1 {
+				return nil, err
+			}
+			fmt.Printf("Note: %v\n", err)
+			continue
+		}
+		if len(names) > 1 && !p.IsConfigured() {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no provider is configured; see 'azguard config list'")
+	}
+	return selected, nil
+}
+
+// statusExitCodes are the exit codes of the status commands, by the worst
+// status found, so cron jobs and monitoring can act on them. They rise with
+// cost.WorseStatus's order and skip exitCommandError, which main uses when a
+// command fails.
+var statusExitCodes = map[cost.ResourceStatus]int{
+	cost.StatusFree:    0,
+	cost.StatusWarning: 2,
+	cost.StatusUnknown: 3,
+	cost.StatusOverage: 4,
+}
+
+// runStatus fetches the status of the selected providers in parallel, prints
+// each with the combined spend, and sets the exit code to the worst status.
+func runStatus(ctx context.Context, provider string) error {
+	filter, err := providerFilter(provider)
+	if err != nil {
+		return err
+	}
+	report, err := costSvc.GetStatus(ctx, filter)
+	if err != nil {
+		return err
+	}
+	exitCode = statusExitCodes[report.Status]
+
+	if outputFormat == "json" {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Println("\n🛡️  Free Tier Status")
+	fmt.Println("═══════════════════════════════")
+	for _, ps := range report.Providers {
+		printProviderStatus(ps)
+	}
+
+	fmt.Println("─────────────────────────────────")
+	printTotal("Total Spend", report.TotalSpend, report.Currency, report.Totals)
+	if len(report.Alerts) > 0 {
+		fmt.Printf("\n🔔 Budget Alerts: %d\n", len(report.Alerts))
+		for _, a := range report.Alerts {
+			fmt.Printf("  • %s\n", a)
+		}
+	}
+	fmt.Printf("\n%s\n\n", statusLabel(report.Status))
+	return nil
+}
+
+func printProviderStatus(ps cost.ProviderStatus) {
+	fmt.Printf("\n☁️  %s", providerLabel(ps.Provider))
+	if ps.Account != "" {
+		fmt.Printf(" (%s)", ps.Account)
+	}
+	fmt.Println()
+
+	if ps.Error != "" {
+		fmt.Printf("   ❓ %s\n\n", ps.Error)
+		return
+	}
+
+	fmt.Printf("   Spend:       %s\n", formatMoney(ps.Spend, ps.Currency))
+	if ps.Allowances > 0 {
+		fmt.Printf("   Free tier:   %.1f%% used at most (%d allowances", ps.FreeTierPercent, ps.Allowances)
+		if ps.Warnings > 0 {
+			fmt.Printf(", %d approaching", ps.Warnings)
+		}
+		if ps.Overages > 0 {
+			fmt.Printf(", %d over", ps.Overages)
+		}
+		fmt.Println(")")
+	}
+	if c := ps.Credit; c != nil && c.Paid {
+		fmt.Println("   Plan:        Paid (classic free tier limits apply)")
+	} else if c != nil {
+		if c.Plan != "" {
+			fmt.Printf("   Plan:        %s\n", c.Plan)
+		}
+		fmt.Printf("   Credit left: %s", formatMoney(c.Remaining, c.Currency))
+		if !c.Expires.IsZero() {
+			if days := cost.DaysUntil(c.Expires, time.Now()); days > 0 {
+				fmt.Printf(", expires %s (%d days)", c.Expires.Format("2006-01-02"), days)
+			} else {
+				fmt.Printf(", expired %s", c.Expires.Format("2006-01-02"))
+			}
+		}
+		fmt.Println()
+		if c.Expired {
+			fmt.Println("   ❌ Free plan expired. Upgrade to the paid plan to keep the account open.")
+		}
+	}
+	for _, a := range ps.Alerts {
+		fmt.Printf("   🔔 %s\n", a)
+	}
+	for _, n := range ps.Notes {
+		fmt.Printf("   Note: %s\n", n)
+	}
+	fmt.Printf("   %s\n\n", statusLabel(ps.Status))
+}
+
+func statusLabel(status cost.ResourceStatus) string {
+	switch status {
+	case cost.StatusOverage:
+		return "❌ Status: OVER LIMIT"
+	case cost.StatusWarning:
+		return "⚠️  Status: WARNING"
+	case cost.StatusUnknown:
+		return "❓ Status: UNKNOWN"
+	}
+	return "✅ Status: OK"
+}
+
+type providerScan struct {
+	Provider string                `json:"provider"`
+	Usage    []cloud.FreeTierUsage `json:"usage"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// runScan reads and stores the free tier usage of each selected provider and
+// flags allowances at or above their warning threshold.
+func runScan(ctx context.Context, provider string) error {
+	selected, err := selectProviders(provider)
+	if err != nil {
+		return err
+	}
+
+	var scans []providerScan
+	for _, p := range selected {
+		usages, err := costSvc.ScanFreeTier(ctx, p.Name())
+		if err != nil && usages == nil {
+			if len(selected) == 1 {
+				return err
+			}
+			scans = append(scans, providerScan{Provider: p.Name(), Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Note: %v\n", err)
+		}
+		scans = append(scans, providerScan{Provider: p.Name(), Usage: usages})
+	}
+
+	if outputFormat == "json" {
+		b, err := json.MarshalIndent(scans, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	for _, s := range scans {
+		label := providerLabel(s.Provider)
+		fmt.Printf("\n🔍 %s Free Tier Scan\n", label)
+		fmt.Println("═══════════════════════════════")
+
+		if s.Error != "" {
+			fmt.Printf("Note: %s\n\n", s.Error)
+			continue
+		}
+		if len(s.Usage) == 0 {
+			fmt.Println("No free tier usage found this month.")
+			fmt.Println()
+			continue
+		}
+
+		fmt.Println("\nBy Allowance:")
+		fmt.Println("─────────────────────────────────")
+		if !printUsageRows(s.Usage) {
+			fmt.Printf("\n✅ All %s usage within free tier limits!\n", label)
+		} else {
+			fmt.Println("\n⚠️  Some allowances are approaching or exceeding their limits.")
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// printUsageRows prints one line per usage row and reports whether any row
+// is at or above its warning threshold.
+func printUsageRows(usages []cloud.FreeTierUsage) bool {
+	issuesFound := false
+	for _, u := range usages {
+		status := "✅"
+		switch cost.UsageStatus(u) {
+		case cost.StatusOverage:
+			status = "❌ OVER"
+			issuesFound = true
+		case cost.StatusWarning:
+			status = fmt.Sprintf("⚠️  WARN (≥%.0f%%)", u.Threshold*100)
+			issuesFound = true
+		}
+
+		fmt.Printf("%s %-34s %.2f / %s %s (%.1f%%)\n",
+			status, u.Name+":", u.Used, formatLimit(u.Limit), u.Unit, u.PercentUsed)
+		if u.Description != "" {
+			fmt.Printf("     %s\n", u.Description)
+		}
+		if u.Forecast > u.Limit {
+			fmt.Printf("     Forecast: %.2f %s by end of month\n", u.Forecast, u.Unit)
+		}
+	}
+	return issuesFound
+}
+
+// runResources lists the resources of each selected provider. A provider
+// without an inventory is reported as a note.
+func runResources(ctx context.Context, provider string, billableOnly bool) error {
+	selected, err := selectProviders(provider)
+	if err != nil {
+		return err
+	}
+
+	var inventories []*cloud.Inventory
+	for _, p := range selected {
+		inv, err := p.Inventory(ctx)
+		if err != nil {
+			if len(selected) == 1 && !errors.Is(err, cloud.ErrNotSupported) {
+				return err
+			}
+			if outputFormat != "json" {
+				fmt.Printf("\n📋 %s Resources\n", providerLabel(p.Name()))
+				fmt.Println("═══════════════════════════════")
+				fmt.Printf("Note: %v\n", err)
+			}
+			continue
+		}
+		inventories = append(inventories, inv)
+	}
+
+	if outputFormat == "json" {
+		var v interface{} = inventories
+		if len(selected) == 1 && len(inventories) == 1 {
+			v = inventories[0]
+		}
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	for _, inv := range inventories {
+		printInventory(inv, billableOnly)
+	}
+	return nil
+}
+
+func printInventory(inv *cloud.Inventory, billableOnly bool) {
+	fmt.Printf("\n📋 %s Resources\n", providerLabel(inv.Provider))
+	fmt.Println("═══════════════════════════════")
+	if inv.Account != "" {
+		fmt.Printf("Account: %s\n", inv.Account)
+	}
+	if len(inv.Regions) > 0 {
+		fmt.Printf("Regions swept: %d\n", len(inv.Regions))
+	}
+
+	var (
+		region   string
+		shown    int
+		billable int
+	)
+	for _, r := range inv.Resources {
+		if !r.FreeTier {
+			billable++
+		}
+		if billableOnly && r.FreeTier {
+			continue
+		}
+
+		if r.Region != region || shown == 0 {
+			region = r.Region
+			label := region
+			if label == "" {
+				label = "unknown region"
+			}
+			fmt.Printf("\n%s\n", label)
+			fmt.Println("─────────────────────────────────")
+		}
+
+		status := "✅"
+		if !r.FreeTier {
+			status = "💸"
+		}
+		name := r.ID
+		if r.Name != "" && r.Name != r.ID {
+			name = r.Name + " (" + r.ID + ")"
+		}
+		fmt.Printf("%s %-14s %s\n", status, r.Type, name)
+		fmt.Printf("     %s\n", r.Detail)
+		if r.Note != "" {
+			fmt.Printf("     %s\n", r.Note)
+		}
+		shown++
+	}
+
+	if shown == 0 {
+		if billableOnly {
+			fmt.Println("\n✅ No billable resources found.")
+		} else {
+			fmt.Println("\nNo resources found.")
+		}
+	}
+
+	fmt.Printf("\nTotal: %d resources, %d billable\n", len(inv.Resources), billable)
+	for _, w := range inv.Warnings {
+		fmt.Printf("⚠️  %s\n", w)
+	}
+	for _, e := range inv.Errors {
+		fmt.Printf("Note: %s\n", e)
+	}
+	fmt.Println()
+}
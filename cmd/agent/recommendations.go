@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func recommendationsCmd() *cobra.Command {
+	var (
+		cached bool
+		limit  int
+	)
+
+	cmd := &cobra.Command{
+		Use:     "recommendations",
+		Aliases: []string{"recs"},
+		Short:   "Show Azure Advisor cost recommendations ranked by savings",
+		Long: `Pull Azure Advisor's Cost recommendations, match them to your resources
+and stored spend, and rank them by estimated monthly savings.
+
+The ranked list is saved locally and shown by 'azguard cleanup'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cached {
+				if err := refuseInDemo(cmd.CommandPath(), "Azure Advisor", "use --cached for stored recommendations"); err != nil {
+					return err
+				}
+			}
+
+			var (
+				recs []storage.Recommendation
+				err  error
+			)
+			if cached {
+				recs, err = costSvc.GetStoredRecommendations(cfg.Azure.SubscriptionID)
+			} else {
+				recs, err = costSvc.RefreshRecommendations(context.Background(), azureCostClient)
+			}
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(recs, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			fmt.Println("\n💡 Azure Advisor Cost Recommendations")
+			fmt.Println("═══════════════════════════════")
+
+			if len(recs) == 0 {
+				fmt.Println("✅ No cost recommendations. Nothing to optimize right now.")
+				return nil
+			}
+
+			var total float64
+			for i, r := range recs {
+				if limit > 0 && i >= limit {
+					break
+				}
+				total += r.MonthlySavings
+				fmt.Printf("\n%d. $%.2f/month  %s\n", i+1, r.MonthlySavings, recommendationTarget(r))
+				fmt.Printf("   Problem:  %s\n", r.Problem)
+				fmt.Printf("   Solution: %s\n", r.Solution)
+				switch {
+				case r.RecordedCost <= 0:
+				case r.CostScope == cost.CostScopeResource:
+					fmt.Printf("   Resource spend this month: $%.2f\n", r.RecordedCost)
+				default:
+					fmt.Printf("   Resource group spend this month: $%.2f (all resources in %s)\n", r.RecordedCost, r.ResourceGroup)
+				}
+			}
+
+			fmt.Printf("\nPotential savings: $%.2f/month\n\n", total)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&cached, "cached", false, "Show the last stored recommendations without calling Azure")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Show only the top N recommendations")
+
+	return cmd
+}
+
+func recommendationTarget(r storage.Recommendation) string {
+	target := r.ResourceName
+	if r.ResourceGroup != "" {
+		target = r.ResourceGroup + "/" + target
+	}
+	if r.Location != "" {
+		target += " (" + r.Location + ")"
+	}
+	return target
+}
+
+// printSuggestedActions lists stored Advisor recommendations with the Azure
+// CLI command most likely to act on each one.
+func printSuggestedActions(recs []storage.Recommendation, limit int) {
+	fmt.Println("\n💡 Suggested Actions (Azure Advisor)")
+	fmt.Println("─────────────────────────────────")
+
+	if len(recs) == 0 {
+		fmt.Println("Run 'azguard recommendations' to load suggestions for your subscription.")
+		return
+	}
+
+	for i, r := range recs {
+		if i >= limit {
+			fmt.Printf("...and %d more. Run 'azguard recommendations' for the full list.\n", len(recs)-limit)
+			break
+		}
+		fmt.Printf("%d. Save ~$%.2f/month: %s\n", i+1, r.MonthlySavings, r.Solution)
+		fmt.Printf("   %s\n", suggestedCommand(r))
+	}
+}
+
+func suggestedCommand(r storage.Recommendation) string {
+	switch strings.ToLower(r.ResourceType) {
+	case "microsoft.compute/virtualmachines":
+		return "az vm deallocate --ids " + r.ResourceID
+	case "microsoft.compute/disks":
+		return "az disk delete --ids " + r.ResourceID
+	case "microsoft.network/publicipaddresses":
+		return "az network public-ip delete --ids " + r.ResourceID
+	case "microsoft.web/serverfarms":
+		return "az appservice plan update --ids " + r.ResourceID + " --sku F1"
+	default:
+		return "az resource show --ids " + r.ResourceID
+	}
+}
@@ -0,0 +1,204 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// AnomalyMonitorName is the name of the monitor azguard creates when none exists.
+const AnomalyMonitorName = "azguard-services"
+
+// Anomaly is a spend spike found by Cost Anomaly Detection. Impact is the
+// spend above what the model expected over the anomaly's duration.
+type Anomaly struct {
+	ID            string      `json:"id"`
+	StartDate     string      `json:"start_date"`
+	EndDate       string      `json:"end_date"`
+	Dimension     string      `json:"dimension"`
+	MonitorARN    string      `json:"monitor_arn"`
+	Score         float64     `json:"score"`
+	Impact        float64     `json:"impact"`
+	ImpactPercent float64     `json:"impact_percent"`
+	ActualSpend   float64     `json:"actual_spend"`
+	ExpectedSpend float64     `json:"expected_spend"`
+	RootCauses    []RootCause `json:"root_causes,omitempty"`
+}
+
+// RootCause narrows an anomaly down to where the extra spend came from.
+type RootCause struct {
+	Service       string `json:"service,omitempty"`
+	Region        string `json:"region,omitempty"`
+	UsageType     string `json:"usage_type,omitempty"`
+	LinkedAccount string `json:"linked_account,omitempty"`
+}
+
+// AnomalyMonitor watches a slice of spend, e.g. every service, for anomalies.
+type AnomalyMonitor struct {
+	ARN       string `json:"arn"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Dimension string `json:"dimension,omitempty"`
+}
+
+// GetAnomalies returns anomalies detected between startDate and endDate,
+// largest impact first.
+func (c *CostClient) GetAnomalies(ctx context.Context, startDate, endDate string) ([]Anomaly, error) {
+	var (
+		anomalies []Anomaly
+		pageToken string
+	)
+	for {
+		request := map[string]interface{}{
+			"DateInterval": map[string]string{"StartDate": startDate, "EndDate": endDate},
+			"MaxResults":   100,
+		}
+		if pageToken != "" {
+			request["NextPageToken"] = pageToken
+		}
+
+		var resp struct {
+			Anomalies []struct {
+				AnomalyID        string `json:"AnomalyId"`
+				AnomalyStartDate string `json:"AnomalyStartDate"`
+				AnomalyEndDate   string `json:"AnomalyEndDate"`
+				DimensionValue   string `json:"DimensionValue"`
+				MonitorArn       string `json:"MonitorArn"`
+				RootCauses       []struct {
+					Service       string `json:"Service"`
+					Region        string `json:"Region"`
+					UsageType     string `json:"UsageType"`
+					LinkedAccount string `json:"LinkedAccount"`
+				} `json:"RootCauses"`
+				AnomalyScore struct {
+					MaxScore float64 `json:"MaxScore"`
+				} `json:"AnomalyScore"`
+				Impact struct {
+					TotalImpact           float64 `json:"TotalImpact"`
+					TotalImpactPercentage float64 `json:"TotalImpactPercentage"`
+					TotalActualSpend      float64 `json:"TotalActualSpend"`
+					TotalExpectedSpend    float64 `json:"TotalExpectedSpend"`
+				} `json:"Impact"`
+			} `json:"Anomalies"`
+			NextPageToken string `json:"NextPageToken"`
+		}
+		if err := c.ceCall(ctx, "GetAnomalies", request, &resp); err != nil {
+			return nil, fmt.Errorf("failed to get anomalies: %w", err)
+		}
+
+		for _, a := range resp.Anomalies {
+			anomaly := Anomaly{
+				ID:            a.AnomalyID,
+				StartDate:     a.AnomalyStartDate,
+				EndDate:       a.AnomalyEndDate,
+				Dimension:     a.DimensionValue,
+				MonitorARN:    a.MonitorArn,
+				Score:         a.AnomalyScore.MaxScore,
+				Impact:        a.Impact.TotalImpact,
+				ImpactPercent: a.Impact.TotalImpactPercentage,
+				ActualSpend:   a.Impact.TotalActualSpend,
+				ExpectedSpend: a.Impact.TotalExpectedSpend,
+			}
+			for _, rc := range a.RootCauses {
+				anomaly.RootCauses = append(anomaly.RootCauses, RootCause{
+					Service:       rc.Service,
+					Region:        rc.Region,
+					UsageType:     rc.UsageType,
+					LinkedAccount: rc.LinkedAccount,
+				})
+			}
+			anomalies = append(anomalies, anomaly)
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].Impact > anomalies[j].Impact })
+	return anomalies, nil
+}
+
+// GetAnomalyMonitors lists the account's anomaly monitors.
+func (c *CostClient) GetAnomalyMonitors(ctx context.Context) ([]AnomalyMonitor, error) {
+	var (
+		monitors  []AnomalyMonitor
+		pageToken string
+	)
+	for {
+		request := map[string]interface{}{"MaxResults": 100}
+		if pageToken != "" {
+			request["NextPageToken"] = pageToken
+		}
+
+		var resp struct {
+			AnomalyMonitors []struct {
+				MonitorArn       string `json:"MonitorArn"`
+				MonitorName      string `json:"MonitorName"`
+				MonitorType      string `json:"MonitorType"`
+				MonitorDimension string `json:"MonitorDimension"`
+			} `json:"AnomalyMonitors"`
+			NextPageToken string `json:"NextPageToken"`
+		}
+		if err := c.ceCall(ctx, "GetAnomalyMonitors", request, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list anomaly monitors: %w", err)
+		}
+
+		for _, m := range resp.AnomalyMonitors {
+			monitors = append(monitors, AnomalyMonitor{
+				ARN:       m.MonitorArn,
+				Name:      m.MonitorName,
+				Type:      m.MonitorType,
+				Dimension: m.MonitorDimension,
+			})
+		}
+
+		if resp.NextPageToken == "" {
+			return monitors, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// CreateAnomalyMonitor creates a monitor that watches every AWS service
+// separately, the monitor type AWS recommends for a single account, and
+// returns its ARN.
+func (c *CostClient) CreateAnomalyMonitor(ctx context.Context, name string) (string, error) {
+	request := map[string]interface{}{
+		"AnomalyMonitor": map[string]string{
+			"MonitorName":      name,
+			"MonitorType":      "DIMENSIONAL",
+			"MonitorDimension": "SERVICE",
+		},
+	}
+
+	var resp struct {
+		MonitorArn string `json:"MonitorArn"`
+	}
+	if err := c.ceCall(ctx, "CreateAnomalyMonitor", request, &resp); err != nil {
+		return "", fmt.Errorf("failed to create anomaly monitor: %w", err)
+	}
+	return resp.MonitorArn, nil
+}
+
+// ceCall sends a Cost Explorer action and decodes the response into out.
+func (c *CostClient) ceCall(ctx context.Context, action string, request, out interface{}) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	body, err := c.callAPI(ctx, "ce", "AWSInsightsIndexService."+action, string(payload))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", action, err)
+	}
+	return nil
+}
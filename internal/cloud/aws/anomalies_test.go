@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// Recorded GetAnomalies pages: an EC2 spike with two root causes, then a
+// smaller S3 one on the next page.
+var anomalyPages = map[string]string{
+	"": `{
+		"Anomalies": [{
+			"AnomalyId": "a-small",
+			"AnomalyStartDate": "2024-05-02T00:00:00Z",
+			"AnomalyEndDate": "2024-05-03T00:00:00Z",
+			"DimensionValue": "Amazon Simple Storage Service",
+			"MonitorArn": "arn:aws:ce::123456789012:anomalymonitor/m-1",
+			"RootCauses": [{"Service": "Amazon Simple Storage Service", "Region": "eu-west-1", "UsageType": "EU-TimedStorage-ByteHrs"}],
+			"AnomalyScore": {"MaxScore": 0.62, "CurrentScore": 0.1},
+			"Impact": {"MaxImpact": 3.1, "TotalImpact": 4.5, "TotalImpactPercentage": 150, "TotalActualSpend": 7.5, "TotalExpectedSpend": 3}
+		}],
+		"NextPageToken": "page-2"
+	}`,
+	"page-2": `{
+		"Anomalies": [{
+			"AnomalyId": "a-large",
+			"AnomalyStartDate": "2024-05-04T00:00:00Z",
+			"DimensionValue": "Amazon Elastic Compute Cloud - Compute",
+			"MonitorArn": "arn:aws:ce::123456789012:anomalymonitor/m-1",
+			"RootCauses": [
+				{"Service": "Amazon Elastic Compute Cloud - Compute", "Region": "us-east-1", "UsageType": "BoxUsage:m5.large", "LinkedAccount": "210987654321"},
+				{"Service": "Amazon Elastic Compute Cloud - Compute", "Region": "us-west-2"}
+			],
+			"AnomalyScore": {"MaxScore": 0.91},
+			"Impact": {"TotalImpact": 42.25, "TotalImpactPercentage": 845, "TotalActualSpend": 47.25, "TotalExpectedSpend": 5}
+		}]
+	}`,
+}
+
+func TestGetAnomalies(t *testing.T) {
+	var tokens []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "AWSInsightsIndexService.GetAnomalies" {
+			t.Errorf("target = %q", target)
+		}
+		var req struct {
+			DateInterval  map[string]string
+			NextPageToken string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.DateInterval["StartDate"] != "2024-05-01" || req.DateInterval["EndDate"] != "2024-05-31" {
+			t.Errorf("DateInterval = %v", req.DateInterval)
+		}
+		tokens = append(tokens, req.NextPageToken)
+		_, _ = w.Write([]byte(anomalyPages[req.NextPageToken]))
+	})
+
+	anomalies, err := c.GetAnomalies(context.Background(), "2024-05-01", "2024-05-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"", "page-2"}) {
+		t.Errorf("page tokens = %q, want both pages", tokens)
+	}
+
+	want := []Anomaly{
+		{
+			ID:            "a-large",
+			StartDate:     "2024-05-04T00:00:00Z",
+			Dimension:     "Amazon Elastic Compute Cloud - Compute",
+			MonitorARN:    "arn:aws:ce::123456789012:anomalymonitor/m-1",
+			Score:         0.91,
+			Impact:        42.25,
+			ImpactPercent: 845,
+			ActualSpend:   47.25,
+			ExpectedSpend: 5,
+			RootCauses: []RootCause{
+				{Service: "Amazon Elastic Compute Cloud - Compute", Region: "us-east-1", UsageType: "BoxUsage:m5.large", LinkedAccount: "210987654321"},
+				{Service: "Amazon Elastic Compute Cloud - Compute", Region: "us-west-2"},
+			},
+		},
+		{
+			ID:            "a-small",
+			StartDate:     "2024-05-02T00:00:00Z",
+			EndDate:       "2024-05-03T00:00:00Z",
+			Dimension:     "Amazon Simple Storage Service",
+			MonitorARN:    "arn:aws:ce::123456789012:anomalymonitor/m-1",
+			Score:         0.62,
+			Impact:        4.5,
+			ImpactPercent: 150,
+			ActualSpend:   7.5,
+			ExpectedSpend: 3,
+			RootCauses: []RootCause{
+				{Service: "Amazon Simple Storage Service", Region: "eu-west-1", UsageType: "EU-TimedStorage-ByteHrs"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(anomalies, want) {
+		t.Errorf("GetAnomalies() = %+v\nwant %+v (largest impact first)", anomalies, want)
+	}
+}
+
+func TestGetAnomaliesWithoutRootCauses(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Anomalies": [{"AnomalyId": "a-1", "AnomalyStartDate": "2024-05-02", "Impact": {"TotalImpact": 1.5}}]}`))
+	})
+	anomalies, err := c.GetAnomalies(context.Background(), "2024-05-01", "2024-05-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anomalies) != 1 || anomalies[0].Impact != 1.5 || anomalies[0].RootCauses != nil {
+		t.Errorf("GetAnomalies() = %+v, want one anomaly without root causes", anomalies)
+	}
+}
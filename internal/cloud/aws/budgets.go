@@ -0,0 +1,315 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// ZeroSpendBudgetName matches the budget the AWS console creates from its
+// zero-spend template, so an existing one is recognized.
+const ZeroSpendBudgetName = "My Zero-Spend Budget"
+
+// Budget is a monthly AWS cost budget.
+type Budget struct {
+	Name          string               `json:"name"`
+	Amount        float64              `json:"amount"`
+	Currency      string               `json:"currency"`
+	TimeUnit      string               `json:"time_unit"`
+	ActualSpend   float64              `json:"actual_spend"`
+	Forecasted    float64              `json:"forecasted_spend"`
+	Notifications []BudgetNotification `json:"notifications,omitempty"`
+}
+
+// BudgetNotification fires when actual or forecasted spend passes Threshold.
+// ThresholdType is PERCENTAGE of the budget or ABSOLUTE_VALUE in its currency.
+type BudgetNotification struct {
+	NotificationType string             `json:"notification_type"`
+	Threshold        float64            `json:"threshold"`
+	ThresholdType    string             `json:"threshold_type"`
+	Subscribers      []BudgetSubscriber `json:"subscribers,omitempty"`
+}
+
+// BudgetSubscriber is an EMAIL address or an SNS topic ARN.
+type BudgetSubscriber struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// NewBudgetSubscribers builds subscribers from email addresses and SNS topic ARNs.
+func NewBudgetSubscribers(emails, topics []string) []BudgetSubscriber {
+	var subs []BudgetSubscriber
+	for _, e := range emails {
+		subs = append(subs, BudgetSubscriber{Type: "EMAIL", Address: e})
+	}
+	for _, t := range topics {
+		subs = append(subs, BudgetSubscriber{Type: "SNS", Address: t})
+	}
+	return subs
+}
+
+// NewBudgetNotifications creates one actual-spend notification per percent
+// threshold. AWS requires a subscriber on every notification, so none are
+// returned when there are no subscribers.
+func NewBudgetNotifications(thresholds []float64, subscribers []BudgetSubscriber) []BudgetNotification {
+	if len(subscribers) == 0 {
+		return nil
+	}
+	notifications := make([]BudgetNotification, len(thresholds))
+	for i, t := range thresholds {
+		notifications[i] = BudgetNotification{
+			NotificationType: "ACTUAL",
+			Threshold:        t,
+			ThresholdType:    "PERCENTAGE",
+			Subscribers:      subscribers,
+		}
+	}
+	return notifications
+}
+
+// ZeroSpendBudget returns AWS's zero-spend template: a $1 budget that notifies
+// as soon as actual spend passes one cent.
+func ZeroSpendBudget(subscribers []BudgetSubscriber) Budget {
+	return Budget{
+		Name:     ZeroSpendBudgetName,
+		Amount:   1,
+		Currency: "USD",
+		TimeUnit: "MONTHLY",
+		Notifications: []BudgetNotification{{
+			NotificationType: "ACTUAL",
+			Threshold:        0.01,
+			ThresholdType:    "ABSOLUTE_VALUE",
+			Subscribers:      subscribers,
+		}},
+	}
+}
+
+type budgetAmount struct {
+	Amount string `json:"Amount"`
+	Unit   string `json:"Unit"`
+}
+
+type budgetPayload struct {
+	BudgetName      string       `json:"BudgetName"`
+	BudgetLimit     budgetAmount `json:"BudgetLimit"`
+	TimeUnit        string       `json:"TimeUnit"`
+	BudgetType      string       `json:"BudgetType"`
+	CalculatedSpend *struct {
+		ActualSpend     budgetAmount  `json:"ActualSpend"`
+		ForecastedSpend *budgetAmount `json:"ForecastedSpend"`
+	} `json:"CalculatedSpend,omitempty"`
+}
+
+type notificationPayload struct {
+	NotificationType   string  `json:"NotificationType"`
+	ComparisonOperator string  `json:"ComparisonOperator"`
+	Threshold          float64 `json:"Threshold"`
+	ThresholdType      string  `json:"ThresholdType"`
+}
+
+type subscriberPayload struct {
+	SubscriptionType string `json:"SubscriptionType"`
+	Address          string `json:"Address"`
+}
+
+// ListBudgets returns the account's cost budgets sorted by name.
+func (c *CostClient) ListBudgets(ctx context.Context) ([]Budget, error) {
+	accountID, err := c.GetCallerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		budgets   []Budget
+		nextToken string
+	)
+	for {
+		request := map[string]interface{}{"AccountId": accountID, "MaxResults": 100}
+		if nextToken != "" {
+			request["NextToken"] = nextToken
+		}
+
+		var resp struct {
+			Budgets   []budgetPayload `json:"Budgets"`
+			NextToken string          `json:"NextToken"`
+		}
+		if err := c.budgetsCall(ctx, "DescribeBudgets", request, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list budgets: %w", err)
+		}
+
+		for _, b := range resp.Budgets {
+			if b.BudgetType != "COST" {
+				continue
+			}
+			budget := Budget{
+				Name:     b.BudgetName,
+				Amount:   parseAmount(b.BudgetLimit.Amount),
+				Currency: b.BudgetLimit.Unit,
+				TimeUnit: b.TimeUnit,
+			}
+			if cs := b.CalculatedSpend; cs != nil {
+				budget.ActualSpend = parseAmount(cs.ActualSpend.Amount)
+				if cs.ForecastedSpend != nil {
+					budget.Forecasted = parseAmount(cs.ForecastedSpend.Amount)
+				}
+			}
+			budgets = append(budgets, budget)
+		}
+
+		if resp.NextToken == "" {
+			break
+		}
+		nextToken = resp.NextToken
+	}
+
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Name < budgets[j].Name })
+	return budgets, nil
+}
+
+// CreateBudget creates a monthly cost budget with its notifications.
+func (c *CostClient) CreateBudget(ctx context.Context, b Budget) error {
+	accountID, err := c.GetCallerIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var withSubscribers []map[string]interface{}
+	for _, n := range b.Notifications {
+		withSubscribers = append(withSubscribers, map[string]interface{}{
+			"Notification": n.payload(),
+			"Subscribers":  subscriberPayloads(n.Subscribers),
+		})
+	}
+
+	request := map[string]interface{}{
+		"AccountId": accountID,
+		"Budget":    b.payload(),
+	}
+	if len(withSubscribers) > 0 {
+		request["NotificationsWithSubscribers"] = withSubscribers
+	}
+
+	if err := c.budgetsCall(ctx, "CreateBudget", request, nil); err != nil {
+		return fmt.Errorf("failed to create budget %s: %w", b.Name, err)
+	}
+	return nil
+}
+
+// UpdateBudget changes a budget's limit and adds any notification that does
+// not exist yet. Existing notifications are left alone.
+func (c *CostClient) UpdateBudget(ctx context.Context, b Budget) error {
+	accountID, err := c.GetCallerIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := map[string]interface{}{
+		"AccountId": accountID,
+		"NewBudget": b.payload(),
+	}
+	if err := c.budgetsCall(ctx, "UpdateBudget", request, nil); err != nil {
+		return fmt.Errorf("failed to update budget %s: %w", b.Name, err)
+	}
+
+	if len(b.Notifications) == 0 {
+		return nil
+	}
+
+	var existing struct {
+		Notifications []notificationPayload `json:"Notifications"`
+	}
+	request = map[string]interface{}{"AccountId": accountID, "BudgetName": b.Name, "MaxResults": 100}
+	if err := c.budgetsCall(ctx, "DescribeNotificationsForBudget", request, &existing); err != nil {
+		return fmt.Errorf("failed to list notifications for %s: %w", b.Name, err)
+	}
+
+	for _, n := range b.Notifications {
+		if hasNotification(existing.Notifications, n.payload()) {
+			continue
+		}
+		request := map[string]interface{}{
+			"AccountId":    accountID,
+			"BudgetName":   b.Name,
+			"Notification": n.payload(),
+			"Subscribers":  subscriberPayloads(n.Subscribers),
+		}
+		if err := c.budgetsCall(ctx, "CreateNotification", request, nil); err != nil {
+			return fmt.Errorf("failed to add notification to %s: %w", b.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *CostClient) budgetsCall(ctx context.Context, action string, request, out interface{}) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	body, err := c.callAPI(ctx, "budgets", "AWSBudgetServiceGateway."+action, string(payload))
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", action, err)
+	}
+	return nil
+}
+
+func (b Budget) payload() budgetPayload {
+	currency := b.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	timeUnit := b.TimeUnit
+	if timeUnit == "" {
+		timeUnit = "MONTHLY"
+	}
+	return budgetPayload{
+		BudgetName:  b.Name,
+		BudgetLimit: budgetAmount{Amount: strconv.FormatFloat(b.Amount, 'f', 2, 64), Unit: currency},
+		TimeUnit:    timeUnit,
+		BudgetType:  "COST",
+	}
+}
+
+func (n BudgetNotification) payload() notificationPayload {
+	return notificationPayload{
+		NotificationType:   n.NotificationType,
+		ComparisonOperator: "GREATER_THAN",
+		Threshold:          n.Threshold,
+		ThresholdType:      n.ThresholdType,
+	}
+}
+
+func subscriberPayloads(subs []BudgetSubscriber) []subscriberPayload {
+	result := make([]subscriberPayload, len(subs))
+	for i, s := range subs {
+		result[i] = subscriberPayload{SubscriptionType: s.Type, Address: s.Address}
+	}
+	return result
+}
+
+func hasNotification(existing []notificationPayload, n notificationPayload) bool {
+	for _, e := range existing {
+		if e.NotificationType == n.NotificationType && e.ComparisonOperator == n.ComparisonOperator &&
+			e.ThresholdType == n.ThresholdType && math.Abs(e.Threshold-n.Threshold) < 0.005 {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAmount(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
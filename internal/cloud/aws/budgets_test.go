@@ -0,0 +1,210 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// budgetsServer records the body of each Budgets action and answers with
+// the canned response for it, or {}.
+func budgetsServer(t *testing.T, responses map[string]string) (*CostClient, map[string][]map[string]interface{}) {
+	t.Helper()
+	requests := make(map[string][]map[string]interface{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AWSBudgetServiceGateway.")
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		requests[action] = append(requests[action], body)
+		if resp, ok := responses[action]; ok {
+			_, _ = w.Write([]byte(resp))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	// Skip the STS lookup of the account ID.
+	c.accountID = "123456789012"
+	return c, requests
+}
+
+// jsonValue round-trips v through JSON so it compares equal to a decoded
+// request body.
+func jsonValue(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestNewBudgetSubscribers(t *testing.T) {
+	got := NewBudgetSubscribers([]string{"ops@example.com"}, []string{"arn:aws:sns:us-east-1:123456789012:billing"})
+	want := []BudgetSubscriber{
+		{Type: "EMAIL", Address: "ops@example.com"},
+		{Type: "SNS", Address: "arn:aws:sns:us-east-1:123456789012:billing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewBudgetSubscribers() = %+v, want %+v", got, want)
+	}
+	if got := NewBudgetSubscribers(nil, nil); got != nil {
+		t.Errorf("NewBudgetSubscribers(nil, nil) = %+v, want nil", got)
+	}
+}
+
+func TestNewBudgetNotifications(t *testing.T) {
+	subs := NewBudgetSubscribers([]string{"ops@example.com"}, nil)
+	got := NewBudgetNotifications([]float64{80, 100}, subs)
+	want := []BudgetNotification{
+		{NotificationType: "ACTUAL", Threshold: 80, ThresholdType: "PERCENTAGE", Subscribers: subs},
+		{NotificationType: "ACTUAL", Threshold: 100, ThresholdType: "PERCENTAGE", Subscribers: subs},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewBudgetNotifications() = %+v, want %+v", got, want)
+	}
+	// AWS rejects notifications without subscribers.
+	if got := NewBudgetNotifications([]float64{80}, nil); got != nil {
+		t.Errorf("NewBudgetNotifications() without subscribers = %+v, want nil", got)
+	}
+}
+
+func TestCreateBudgetRequest(t *testing.T) {
+	c, requests := budgetsServer(t, nil)
+	subs := NewBudgetSubscribers([]string{"ops@example.com"}, []string{"arn:aws:sns:us-east-1:123456789012:billing"})
+	budget := Budget{Name: "budget-25", Amount: 25, Notifications: NewBudgetNotifications([]float64{80}, subs)}
+	if err := c.CreateBudget(context.Background(), budget); err != nil {
+		t.Fatal(err)
+	}
+
+	want := jsonValue(t, map[string]interface{}{
+		"AccountId": "123456789012",
+		"Budget": map[string]interface{}{
+			"BudgetName":  "budget-25",
+			"BudgetLimit": map[string]string{"Amount": "25.00", "Unit": "USD"},
+			"TimeUnit":    "MONTHLY",
+			"BudgetType":  "COST",
+		},
+		"NotificationsWithSubscribers": []interface{}{map[string]interface{}{
+			"Notification": map[string]interface{}{
+				"NotificationType":   "ACTUAL",
+				"ComparisonOperator": "GREATER_THAN",
+				"Threshold":          80,
+				"ThresholdType":      "PERCENTAGE",
+			},
+			"Subscribers": []map[string]string{
+				{"SubscriptionType": "EMAIL", "Address": "ops@example.com"},
+				{"SubscriptionType": "SNS", "Address": "arn:aws:sns:us-east-1:123456789012:billing"},
+			},
+		}},
+	})
+	if got := requests["CreateBudget"]; len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("CreateBudget request = %v\nwant %v", got, want)
+	}
+}
+
+func TestCreateBudgetWithoutNotifications(t *testing.T) {
+	c, requests := budgetsServer(t, nil)
+	if err := c.CreateBudget(context.Background(), Budget{Name: "budget-5", Amount: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := requests["CreateBudget"][0]["NotificationsWithSubscribers"]; ok {
+		t.Errorf("CreateBudget request = %v, want no NotificationsWithSubscribers", requests["CreateBudget"][0])
+	}
+}
+
+func TestZeroSpendBudgetRequest(t *testing.T) {
+	c, requests := budgetsServer(t, nil)
+	subs := NewBudgetSubscribers(nil, []string{"arn:aws:sns:us-east-1:123456789012:billing"})
+	if err := c.CreateBudget(context.Background(), ZeroSpendBudget(subs)); err != nil {
+		t.Fatal(err)
+	}
+
+	req := requests["CreateBudget"][0]
+	wantBudget := jsonValue(t, map[string]interface{}{
+		"BudgetName":  ZeroSpendBudgetName,
+		"BudgetLimit": map[string]string{"Amount": "1.00", "Unit": "USD"},
+		"TimeUnit":    "MONTHLY",
+		"BudgetType":  "COST",
+	})
+	if !reflect.DeepEqual(req["Budget"], wantBudget) {
+		t.Errorf("Budget = %v, want %v", req["Budget"], wantBudget)
+	}
+	wantNotifications := jsonValue(t, []interface{}{map[string]interface{}{
+		"Notification": map[string]interface{}{
+			"NotificationType":   "ACTUAL",
+			"ComparisonOperator": "GREATER_THAN",
+			"Threshold":          0.01,
+			"ThresholdType":      "ABSOLUTE_VALUE",
+		},
+		"Subscribers": []map[string]string{
+			{"SubscriptionType": "SNS", "Address": "arn:aws:sns:us-east-1:123456789012:billing"},
+		},
+	}})
+	if !reflect.DeepEqual(req["NotificationsWithSubscribers"], wantNotifications) {
+		t.Errorf("NotificationsWithSubscribers = %v, want %v", req["NotificationsWithSubscribers"], wantNotifications)
+	}
+}
+
+func TestUpdateBudgetAddsMissingNotifications(t *testing.T) {
+	c, requests := budgetsServer(t, map[string]string{
+		"DescribeNotificationsForBudget": `{"Notifications": [
+			{"NotificationType": "ACTUAL", "ComparisonOperator": "GREATER_THAN", "Threshold": 80, "ThresholdType": "PERCENTAGE"}
+		]}`,
+	})
+	subs := NewBudgetSubscribers([]string{"ops@example.com"}, nil)
+	budget := Budget{Name: "budget-25", Amount: 30, Notifications: NewBudgetNotifications([]float64{80, 100}, subs)}
+	if err := c.UpdateBudget(context.Background(), budget); err != nil {
+		t.Fatal(err)
+	}
+
+	update := requests["UpdateBudget"]
+	if len(update) != 1 || !reflect.DeepEqual(update[0]["NewBudget"], jsonValue(t, budget.payload())) {
+		t.Errorf("UpdateBudget request = %v", update)
+	}
+	created := requests["CreateNotification"]
+	if len(created) != 1 {
+		t.Fatalf("CreateNotification requests = %v, want only the 100%% one", created)
+	}
+	n := created[0]["Notification"].(map[string]interface{})
+	if n["Threshold"] != 100.0 || created[0]["BudgetName"] != "budget-25" {
+		t.Errorf("CreateNotification request = %v, want budget-25 at 100%%", created[0])
+	}
+	if !reflect.DeepEqual(created[0]["Subscribers"], jsonValue(t, []map[string]string{{"SubscriptionType": "EMAIL", "Address": "ops@example.com"}})) {
+		t.Errorf("subscribers = %v", created[0]["Subscribers"])
+	}
+}
+
+func TestListBudgets(t *testing.T) {
+	c, requests := budgetsServer(t, map[string]string{
+		"DescribeBudgets": `{"Budgets": [
+			{"BudgetName": "budget-25", "BudgetLimit": {"Amount": "25.0", "Unit": "USD"}, "TimeUnit": "MONTHLY", "BudgetType": "COST",
+			 "CalculatedSpend": {"ActualSpend": {"Amount": "12.5", "Unit": "USD"}, "ForecastedSpend": {"Amount": "30.1", "Unit": "USD"}}},
+			{"BudgetName": "ec2-hours", "BudgetLimit": {"Amount": "750", "Unit": "Hrs"}, "TimeUnit": "MONTHLY", "BudgetType": "USAGE"},
+			{"BudgetName": "My Zero-Spend Budget", "BudgetLimit": {"Amount": "1.0", "Unit": "USD"}, "TimeUnit": "MONTHLY", "BudgetType": "COST",
+			 "CalculatedSpend": {"ActualSpend": {"Amount": "0", "Unit": "USD"}}}
+		]}`,
+	})
+	budgets, err := c.ListBudgets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := requests["DescribeBudgets"][0]["AccountId"]; got != "123456789012" {
+		t.Errorf("AccountId = %v", got)
+	}
+	want := []Budget{
+		{Name: "My Zero-Spend Budget", Amount: 1, Currency: "USD", TimeUnit: "MONTHLY"},
+		{Name: "budget-25", Amount: 25, Currency: "USD", TimeUnit: "MONTHLY", ActualSpend: 12.5, Forecasted: 30.1},
+	}
+	if !reflect.DeepEqual(budgets, want) {
+		t.Errorf("ListBudgets() = %+v\nwant %+v (cost budgets only, by name)", budgets, want)
+	}
+}
@@ -3,44 +3,64 @@ package aws
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
-)
 
-const (
-	CostExplorerEndpoint = "https://ce.us-east-1.amazonaws.com"
-	FreeTierEndpoint     = "https://freetier.us-east-1.amazonaws.com"
+	"github.com/azguard/azguard/internal/cloud/aws/sigv4"
 )
 
 // CostClient communicates with the AWS Cost Explorer API.
 type CostClient struct {
-	Region    string
-	AccessKey string
-	SecretKey string
-	Token     string
-	HTTP      *http.Client
+	Region      string
+	Profile     string
+	Credentials CredentialsProvider
+	Endpoints   *EndpointResolver
+	HTTP        *http.Client
+	// ConfigError is set when the shared config or credentials file could
+	// not be read, in which case the client works as if neither file existed,
+	// or when the selected profile cannot provide credentials.
+	ConfigError error
+
+	mu        sync.Mutex
+	creds     Credentials
+	accountID string
 }
 
 // NewCostClient creates a new AWS cost client.
-// It resolves credentials from explicit config, env vars, or AWS CLI.
-func NewCostClient(accessKey, secretKey, sessionToken, region string) *CostClient {
-	if accessKey == "" {
-		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+// Credentials are resolved in order from explicit config, env vars (static
+// keys, then a web identity token file), the shared config and credentials
+// files (static keys, AssumeRole, web identity, SSO or credential_process),
+// and finally the AWS CLI. Env vars are skipped when a profile is named
+// explicitly, so the profile wins.
+// The region comes from the profile when it sets one, then config, then env.
+func NewCostClient(accessKey, secretKey, sessionToken, region, profile string) *CostClient {
+	explicitProfile := profile != ""
+	if profile == "" {
+		profile = DefaultProfileName()
 	}
-	if secretKey == "" {
-		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+
+	shared, configErr := LoadSharedConfig()
+	var sharedProfile *Profile
+	if shared != nil {
+		if p, err := shared.Profile(profile); err == nil {
+			sharedProfile = p
+			if p.Region != "" {
+				region = p.Region
+			}
+		}
 	}
-	if sessionToken == "" {
-		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
 	}
 	if region == "" {
 		region = os.Getenv("AWS_DEFAULT_REGION")
@@ -49,37 +69,51 @@ func NewCostClient(accessKey, secretKey, sessionToken, region string) *CostClien
 		region = "us-east-1"
 	}
 
-	// Fallback: try AWS CLI credentials
-	if accessKey == "" || secretKey == "" {
-		if ak, sk, tok, err := getCredentialsFromCLI(); err == nil {
-			if accessKey == "" {
-				accessKey = ak
-			}
-			if secretKey == "" {
-				secretKey = sk
-			}
-			if sessionToken == "" && tok != "" {
-				sessionToken = tok
-			}
-		}
-	}
-
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	creds, credsErr := resolveCredentials(accessKey, secretKey, sessionToken, profile, explicitProfile, shared, region, httpClient)
 	return &CostClient{
-		Region:    region,
-		AccessKey: accessKey,
-		SecretKey: secretKey,
-		Token:     sessionToken,
-		HTTP:      &http.Client{Timeout: 60 * time.Second},
+		Region:      region,
+		Profile:     profile,
+		Credentials: creds,
+		Endpoints:   NewEndpointResolver(sharedProfile),
+		HTTP:        httpClient,
+		ConfigError: errors.Join(configErr, credsErr),
 	}
 }
 
-// IsConfigured returns true if the client has valid credentials.
+// IsConfigured returns true if the client has a way to obtain credentials.
 func (c *CostClient) IsConfigured() bool {
-	return c.AccessKey != "" && c.SecretKey != ""
+	return c.Credentials != nil
+}
+
+// credentials returns the cached credentials, retrieving new ones when they
+// are missing or within RefreshWindow of expiring.
+func (c *CostClient) credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.creds.NeedsRefresh() {
+		return c.creds, nil
+	}
+	if c.Credentials == nil {
+		if c.ConfigError != nil {
+			return Credentials{}, fmt.Errorf("AWS credentials not configured: %w", c.ConfigError)
+		}
+		return Credentials{}, fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	creds, err := c.Credentials.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to resolve AWS credentials: %w", err)
+	}
+	c.creds = creds
+	return creds, nil
 }
 
-// CostRecord represents a single cost entry from AWS.
+// CostRecord represents a single cost entry from AWS. AccountID is set for
+// costs grouped by linked account.
 type CostRecord struct {
+	AccountID   string
 	ServiceName string
 	Cost        float64
 	Currency    string
@@ -114,7 +148,7 @@ func (c *CostClient) GetFreeTierUsage(ctx context.Context) ([]FreeTierUsage, err
 
 	// Build GetFreeTierUsage request
 	payload := `{}`
-	body, err := c.callAPI(ctx, FreeTierEndpoint, "AWSFreeTierService.GetFreeTierUsage", payload)
+	body, err := c.callAPI(ctx, "freetier", "AWSFreeTierService.GetFreeTierUsage", payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get free tier usage: %w", err)
 	}
@@ -170,7 +204,7 @@ func (c *CostClient) QueryCostsByService(ctx context.Context, startDate, endDate
 		"GroupBy": [{"Type": "DIMENSION", "Key": "SERVICE"}]
 	}`, startDate, endDate)
 
-	body, err := c.callAPI(ctx, CostExplorerEndpoint, "AWSInsightsIndexService.GetCostAndUsage", payload)
+	body, err := c.callAPI(ctx, "ce", "AWSInsightsIndexService.GetCostAndUsage", payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query costs: %w", err)
 	}
@@ -202,8 +236,10 @@ func (c *CostClient) QueryCostsByService(ctx context.Context, startDate, endDate
 				serviceName = group.Keys[0]
 			}
 			if metric, ok := group.Metrics["UnblendedCost"]; ok {
-				var costVal float64
-				fmt.Sscanf(metric.Amount, "%f", &costVal)
+				costVal, err := parseCost(metric.Amount)
+				if err != nil {
+					return nil, err
+				}
 				result.Records = append(result.Records, CostRecord{
 					ServiceName: serviceName,
 					Cost:        costVal,
@@ -218,21 +254,135 @@ func (c *CostClient) QueryCostsByService(ctx context.Context, startDate, endDate
 	return result, nil
 }
 
-// callAPI makes a signed request to an AWS JSON API.
-func (c *CostClient) callAPI(ctx context.Context, endpoint, target, payload string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader([]byte(payload)))
+// GetDailyCosts queries Cost Explorer for daily costs grouped by service,
+// following NextPageToken until every page has been read.
+func (c *CostClient) GetDailyCosts(ctx context.Context, startDate, endDate string) ([]CostRecord, error) {
+	return c.dailyCosts(ctx, startDate, endDate, "SERVICE")
+}
+
+// GetDailyCostsByAccount is GetDailyCosts for every linked account of an
+// organization's management account, grouped by account and service.
+func (c *CostClient) GetDailyCostsByAccount(ctx context.Context, startDate, endDate string) ([]CostRecord, error) {
+	return c.dailyCosts(ctx, startDate, endDate, "LINKED_ACCOUNT", "SERVICE")
+}
+
+// dailyCosts groups by the given dimensions; SERVICE must be the last one.
+func (c *CostClient) dailyCosts(ctx context.Context, startDate, endDate string, dimensions ...string) ([]CostRecord, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	groupBy := make([]map[string]string, len(dimensions))
+	for i, d := range dimensions {
+		groupBy[i] = map[string]string{"Type": "DIMENSION", "Key": d}
+	}
+
+	var (
+		records   []CostRecord
+		pageToken string
+	)
+	for {
+		request := map[string]interface{}{
+			"TimePeriod":  map[string]string{"Start": startDate, "End": endDate},
+			"Granularity": "DAILY",
+			"Metrics":     []string{"UnblendedCost"},
+			"GroupBy":     groupBy,
+		}
+		if pageToken != "" {
+			request["NextPageToken"] = pageToken
+		}
+		payload, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.callAPI(ctx, "ce", "AWSInsightsIndexService.GetCostAndUsage", string(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query costs: %w", err)
+		}
+
+		var resp struct {
+			NextPageToken string `json:"NextPageToken"`
+			ResultsByTime []struct {
+				TimePeriod struct {
+					Start string `json:"Start"`
+				} `json:"TimePeriod"`
+				Groups []struct {
+					Keys    []string `json:"Keys"`
+					Metrics map[string]struct {
+						Amount string `json:"Amount"`
+						Unit   string `json:"Unit"`
+					} `json:"Metrics"`
+				} `json:"Groups"`
+			} `json:"ResultsByTime"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse cost response: %w", err)
+		}
+
+		for _, period := range resp.ResultsByTime {
+			for _, group := range period.Groups {
+				metric, ok := group.Metrics["UnblendedCost"]
+				if !ok || len(group.Keys) != len(dimensions) {
+					continue
+				}
+				costVal, err := parseCost(metric.Amount)
+				if err != nil {
+					return nil, err
+				}
+				record := CostRecord{
+					ServiceName: group.Keys[len(group.Keys)-1],
+					Cost:        costVal,
+					Currency:    metric.Unit,
+					Date:        period.TimePeriod.Start,
+				}
+				if len(group.Keys) > 1 {
+					record.AccountID = group.Keys[0]
+				}
+				records = append(records, record)
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			return records, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// parseCost parses an amount, which Cost Explorer sends as a string.
+func parseCost(amount string) (float64, error) {
+	v, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cost amount %q: %w", amount, err)
+	}
+	return v, nil
+}
+
+// callAPI makes a signed request to an AWS JSON API. service is the signing
+// name: ce, freetier, budgets or organizations.
+func (c *CostClient) callAPI(ctx context.Context, service, target, payload string) ([]byte, error) {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := c.endpoint(service, c.homeRegion())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ep.URL+"/", bytes.NewReader([]byte(payload)))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
 	req.Header.Set("X-Amz-Target", target)
-	if c.Token != "" {
-		req.Header.Set("X-Amz-Security-Token", c.Token)
-	}
 
-	// Sign the request with AWS Signature V4
-	c.signRequest(req, []byte(payload))
+	if err := signV4(req, []byte(payload), creds, ep.SigningRegion, ep.SigningName); err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
@@ -252,83 +402,33 @@ func (c *CostClient) callAPI(ctx context.Context, endpoint, target, payload stri
 	return body, nil
 }
 
-// signRequest applies AWS Signature V4 to an HTTP request.
-func (c *CostClient) signRequest(req *http.Request, payload []byte) {
-	now := time.Now().UTC()
-	datestamp := now.Format("20060102")
-	amzdate := now.Format("20060102T150405Z")
-
-	// Determine service from endpoint
-	service := "ce"
-	if strings.Contains(req.URL.Host, "freetier") {
-		service = "freetier"
-	}
-
-	req.Header.Set("X-Amz-Date", amzdate)
-	req.Header.Set("Host", req.URL.Host)
-
-	// Create canonical request
-	payloadHash := sha256Hex(payload)
-	signedHeaders := "content-type;host;x-amz-date;x-amz-target"
-	if c.Token != "" {
-		signedHeaders = "content-type;host;x-amz-date;x-amz-security-token;x-amz-target"
-	}
-
-	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\n",
-		req.Header.Get("Content-Type"), req.URL.Host, amzdate)
-	if c.Token != "" {
-		canonicalHeaders = fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\nx-amz-security-token:%s\n",
-			req.Header.Get("Content-Type"), req.URL.Host, amzdate, c.Token)
-	}
-	canonicalHeaders += fmt.Sprintf("x-amz-target:%s\n", req.Header.Get("X-Amz-Target"))
-
-	canonicalRequest := fmt.Sprintf("POST\n/\n\n%s\n%s\n%s",
-		canonicalHeaders, signedHeaders, payloadHash)
-
-	// Create string to sign
-	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", datestamp, c.Region, service)
-	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s",
-		amzdate, credentialScope, sha256Hex([]byte(canonicalRequest)))
-
-	// Calculate signature
-	signingKey := getSignatureKey(c.SecretKey, datestamp, c.Region, service)
-	signature := hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
-
-	// Set Authorization header
-	req.Header.Set("Authorization", fmt.Sprintf(
-		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
-		c.AccessKey, credentialScope, signedHeaders, signature))
-}
-
-func sha256Hex(data []byte) string {
-	h := sha256.Sum256(data)
-	return hex.EncodeToString(h[:])
-}
-
-func hmacSHA256(key, data []byte) []byte {
-	h := hmac.New(sha256.New, key)
-	h.Write(data)
-	return h.Sum(nil)
-}
-
-func getSignatureKey(secret, datestamp, region, service string) []byte {
-	kDate := hmacSHA256([]byte("AWS4"+secret), []byte(datestamp))
-	kRegion := hmacSHA256(kDate, []byte(region))
-	kService := hmacSHA256(kRegion, []byte(service))
-	return hmacSHA256(kService, []byte("aws4_request"))
+// signV4 signs req with the given credentials for a service and region.
+func signV4(req *http.Request, payload []byte, creds Credentials, region, service string) error {
+	signer := sigv4.NewSigner(sigv4.Credentials{
+		AccessKey:    creds.AccessKey,
+		SecretKey:    creds.SecretKey,
+		SessionToken: creds.SessionToken,
+	}, region, service)
+	return signer.Sign(req, payload)
 }
 
 // getCredentialsFromCLI attempts to get AWS credentials from the AWS CLI.
-func getCredentialsFromCLI() (accessKey, secretKey, sessionToken string, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Temporary credentials carry the expiry the CLI exports with them.
+func getCredentialsFromCLI(ctx context.Context, profile string) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "aws", "configure", "export-credentials", "--format", "env")
+	args := []string{"configure", "export-credentials", "--format", "env"}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	cmd := exec.CommandContext(ctx, "aws", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to get AWS CLI credentials: %w", err)
+		return Credentials{}, fmt.Errorf("failed to get AWS CLI credentials: %w", err)
 	}
 
+	var creds Credentials
 	for _, line := range strings.Split(string(output), "\n") {
 		line = strings.TrimSpace(line)
 		line = strings.TrimPrefix(line, "export ")
@@ -340,17 +440,22 @@ func getCredentialsFromCLI() (accessKey, secretKey, sessionToken string, err err
 		val := strings.TrimSpace(parts[1])
 		switch key {
 		case "AWS_ACCESS_KEY_ID":
-			accessKey = val
+			creds.AccessKey = val
 		case "AWS_SECRET_ACCESS_KEY":
-			secretKey = val
+			creds.SecretKey = val
 		case "AWS_SESSION_TOKEN":
-			sessionToken = val
+			creds.SessionToken = val
+		case "AWS_CREDENTIAL_EXPIRATION":
+			creds.Expires, err = time.Parse(time.RFC3339, val)
+			if err != nil {
+				return Credentials{}, fmt.Errorf("invalid AWS CLI credential expiration %q: %w", val, err)
+			}
 		}
 	}
 
-	if accessKey == "" || secretKey == "" {
-		return "", "", "", fmt.Errorf("no credentials found in AWS CLI output")
+	if creds.AccessKey == "" || creds.SecretKey == "" {
+		return Credentials{}, fmt.Errorf("no credentials found in AWS CLI output")
 	}
 
-	return accessKey, secretKey, sessionToken, nil
+	return creds, nil
 }
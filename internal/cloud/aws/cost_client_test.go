@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a client with static credentials whose every
+// endpoint is a test server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *CostClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+	return &CostClient{
+		Region:      "us-east-1",
+		Credentials: StaticProvider{AccessKey: "AKID", SecretKey: "secret"},
+		Endpoints:   NewEndpointResolver(nil),
+		HTTP:        server.Client(),
+	}
+}
+
+func TestParseCost(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"1.2345", 1.2345, false},
+		{"0", 0, false},
+		{"-0.01", -0.01, false},
+		{"1e-10", 1e-10, false},
+		{"", 0, true},
+		{"12,50", 0, true},
+		{"1.5USD", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCost(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseCost(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGetDailyCostsRejectsMalformedAmounts(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-03-01"}, "Groups": [
+			{"Keys": ["Amazon Simple Storage Service"], "Metrics": {"UnblendedCost": {"Amount": "n/a", "Unit": "USD"}}}
+		]}]}`))
+	})
+	_, err := c.GetDailyCosts(context.Background(), "2024-03-01", "2024-03-02")
+	if err == nil || !strings.Contains(err.Error(), `invalid cost amount "n/a"`) {
+		t.Errorf("GetDailyCosts() error = %v, want an invalid amount error", err)
+	}
+}
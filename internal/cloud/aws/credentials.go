@@ -0,0 +1,321 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CLIProbeTimeout bounds the AWS CLI call that checks for credentials when
+// nothing else is configured.
+const CLIProbeTimeout = 5 * time.Second
+
+// RefreshWindow is how long before expiry temporary credentials are renewed,
+// so long-running commands never sign a request with expired keys.
+const RefreshWindow = 5 * time.Minute
+
+// Credentials is a set of AWS keys. Expires is zero for long-lived keys.
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Expires      time.Time
+}
+
+// NeedsRefresh reports whether the credentials are missing or about to expire.
+func (c Credentials) NeedsRefresh() bool {
+	if c.AccessKey == "" || c.SecretKey == "" {
+		return true
+	}
+	return !c.Expires.IsZero() && time.Until(c.Expires) < RefreshWindow
+}
+
+// CredentialsProvider retrieves credentials, possibly by calling STS or SSO.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// StaticProvider returns a fixed set of keys.
+type StaticProvider Credentials
+
+func (p StaticProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	return Credentials(p), nil
+}
+
+// ProcessProvider runs a credential_process command and parses its JSON output.
+type ProcessProvider struct {
+	Command string
+}
+
+func (p *ProcessProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	args := splitCommandLine(p.Command)
+	if len(args) == 0 {
+		return Credentials{}, fmt.Errorf("credential_process is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("credential_process failed: %w", err)
+	}
+
+	var result struct {
+		Version         int    `json:"Version"`
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+		Expiration      string `json:"Expiration"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse credential_process output: %w", err)
+	}
+	if result.Version != 1 {
+		return Credentials{}, fmt.Errorf("unsupported credential_process version %d", result.Version)
+	}
+	if result.AccessKeyID == "" || result.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("credential_process returned no keys")
+	}
+
+	creds := Credentials{
+		AccessKey:    result.AccessKeyID,
+		SecretKey:    result.SecretAccessKey,
+		SessionToken: result.SessionToken,
+	}
+	if result.Expiration != "" {
+		creds.Expires, err = time.Parse(time.RFC3339, result.Expiration)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("invalid credential_process expiration: %w", err)
+		}
+	}
+	return creds, nil
+}
+
+// CLIProvider asks the AWS CLI to export whatever credentials it resolves.
+type CLIProvider struct {
+	Profile string
+
+	mu    sync.Mutex
+	creds Credentials
+}
+
+func (p *CLIProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.creds.NeedsRefresh() {
+		return p.creds, nil
+	}
+	creds, err := getCredentialsFromCLI(ctx, p.Profile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	p.creds = creds
+	return p.creds, nil
+}
+
+// PromptMFAToken reads an MFA code for the given device from the terminal.
+func PromptMFAToken(serial string) (string, error) {
+	fmt.Fprintf(os.Stderr, "Enter MFA code for %s: ", serial)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read MFA code: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// resolveCredentials builds the provider for a client following the order
+// documented on NewCostClient. It returns nil when nothing is configured.
+// An invalid profile is reported as an error; when the profile was named
+// explicitly the AWS CLI is not tried, since it reads the same files.
+func resolveCredentials(accessKey, secretKey, sessionToken, profile string, explicitProfile bool, shared *SharedConfig, region string, httpClient *http.Client) (CredentialsProvider, error) {
+	if accessKey != "" && secretKey != "" {
+		return StaticProvider{AccessKey: accessKey, SecretKey: secretKey, SessionToken: sessionToken}, nil
+	}
+
+	if !explicitProfile {
+		if ak, sk := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); ak != "" && sk != "" {
+			return StaticProvider{AccessKey: ak, SecretKey: sk, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+		}
+		if tokenFile, roleARN := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN"); tokenFile != "" && roleARN != "" {
+			return &WebIdentityProvider{
+				RoleARN:     roleARN,
+				TokenFile:   tokenFile,
+				SessionName: os.Getenv("AWS_ROLE_SESSION_NAME"),
+				Region:      region,
+				HTTP:        httpClient,
+			}, nil
+		}
+	}
+
+	var profileErr error
+	if shared != nil {
+		p, err := shared.Profile(profile)
+		switch {
+		case err == nil:
+			provider, err := profileProvider(shared, p, region, httpClient, 0)
+			if err != nil {
+				profileErr = err
+			} else if provider != nil {
+				return provider, nil
+			}
+		case explicitProfile:
+			profileErr = err
+		}
+	}
+	if profileErr != nil && explicitProfile {
+		return nil, profileErr
+	}
+
+	if _, err := exec.LookPath("aws"); err == nil {
+		cliProfile := ""
+		if explicitProfile || profile != "default" {
+			cliProfile = profile
+		}
+		// The CLI only counts when it can export credentials, so a machine
+		// with the CLI installed but never configured stays unconfigured.
+		provider := &CLIProvider{Profile: cliProfile}
+		ctx, cancel := context.WithTimeout(context.Background(), CLIProbeTimeout)
+		defer cancel()
+		if _, err := provider.Retrieve(ctx); err == nil {
+			return provider, nil
+		}
+	}
+	return nil, profileErr
+}
+
+// profileProvider maps a shared config profile to a provider. Role chains
+// through source_profile are followed a few levels deep.
+func profileProvider(shared *SharedConfig, p *Profile, region string, httpClient *http.Client, depth int) (CredentialsProvider, error) {
+	if depth > 5 {
+		return nil, fmt.Errorf("AWS profile %q: source_profile chain is too deep", p.Name)
+	}
+
+	if p.RoleARN != "" {
+		if p.WebIdentityTokenFile != "" {
+			return &WebIdentityProvider{
+				RoleARN:     p.RoleARN,
+				TokenFile:   p.WebIdentityTokenFile,
+				SessionName: p.RoleSessionName,
+				Region:      region,
+				HTTP:        httpClient,
+			}, nil
+		}
+
+		var source CredentialsProvider
+		switch {
+		case p.SourceProfile == p.Name && p.AccessKey != "":
+			source = StaticProvider{AccessKey: p.AccessKey, SecretKey: p.SecretKey, SessionToken: p.SessionToken}
+		case p.SourceProfile != "":
+			sp, err := shared.Profile(p.SourceProfile)
+			if err != nil {
+				return nil, err
+			}
+			if source, err = profileProvider(shared, sp, region, httpClient, depth+1); err != nil {
+				return nil, err
+			}
+			if source == nil {
+				return nil, fmt.Errorf("AWS profile %q: source_profile %q has no credentials", p.Name, p.SourceProfile)
+			}
+		case p.CredentialSource == "Environment":
+			source = StaticProvider{
+				AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			}
+		default:
+			return nil, fmt.Errorf("AWS profile %q: role_arn needs source_profile, credential_source or web_identity_token_file", p.Name)
+		}
+
+		provider := &AssumeRoleProvider{
+			Source:      source,
+			RoleARN:     p.RoleARN,
+			ExternalID:  p.ExternalID,
+			SessionName: p.RoleSessionName,
+			MFASerial:   p.MFASerial,
+			Region:      region,
+			HTTP:        httpClient,
+			CacheDir:    RoleCacheDir(),
+		}
+		if p.DurationSeconds != "" {
+			if secs, err := strconv.Atoi(p.DurationSeconds); err == nil {
+				provider.Duration = time.Duration(secs) * time.Second
+			}
+		}
+		return provider, nil
+	}
+
+	if p.SSOAccountID != "" && p.SSORoleName != "" {
+		startURL, ssoRegion, cacheKey := p.SSOStartURL, p.SSORegion, p.SSOStartURL
+		if p.SSOSession != "" {
+			session, ok := shared.SSOSessions[p.SSOSession]
+			if !ok {
+				return nil, fmt.Errorf("AWS profile %q: sso-session %q not found", p.Name, p.SSOSession)
+			}
+			startURL, ssoRegion, cacheKey = session["sso_start_url"], session["sso_region"], p.SSOSession
+		}
+		return &SSOProvider{
+			StartURL:  startURL,
+			Region:    ssoRegion,
+			AccountID: p.SSOAccountID,
+			RoleName:  p.SSORoleName,
+			CacheKey:  cacheKey,
+			HTTP:      httpClient,
+		}, nil
+	}
+
+	if p.CredentialProcess != "" {
+		return &ProcessProvider{Command: p.CredentialProcess}, nil
+	}
+
+	if p.AccessKey != "" && p.SecretKey != "" {
+		return StaticProvider{AccessKey: p.AccessKey, SecretKey: p.SecretKey, SessionToken: p.SessionToken}, nil
+	}
+
+	return nil, nil
+}
+
+// splitCommandLine splits a command on whitespace, honoring single and double quotes.
+func splitCommandLine(s string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inArg   bool
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAWSCLI puts an aws script on PATH that runs body and counts its calls.
+func fakeAWSCLI(t *testing.T, body string) (calls func() int) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script stand-in for the AWS CLI")
+	}
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "calls")
+	script := fmt.Sprintf("#!/bin/sh\necho x >> %q\n%s\n", counter, body)
+	if err := os.WriteFile(filepath.Join(dir, "aws"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "x")
+	}
+}
+
+func clearAWSEnv(t *testing.T) {
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestResolveCredentialsSkipsUnconfiguredCLI(t *testing.T) {
+	clearAWSEnv(t)
+	fakeAWSCLI(t, "echo 'Unable to locate credentials' >&2; exit 255")
+
+	if p, err := resolveCredentials("", "", "", "default", false, nil, "us-east-1", nil); p != nil || err != nil {
+		t.Errorf("resolveCredentials() = %T, %v, want nil when the CLI has no credentials", p, err)
+	}
+}
+
+func TestResolveCredentialsUsesConfiguredCLI(t *testing.T) {
+	clearAWSEnv(t)
+	calls := fakeAWSCLI(t, "echo export AWS_ACCESS_KEY_ID=AKIDCLI; echo export AWS_SECRET_ACCESS_KEY=secret")
+
+	p, err := resolveCredentials("", "", "", "default", false, nil, "us-east-1", nil)
+	if _, ok := p.(*CLIProvider); !ok || err != nil {
+		t.Fatalf("resolveCredentials() = %T, %v, want *CLIProvider", p, err)
+	}
+	creds, err := p.Retrieve(context.Background())
+	if err != nil || creds.AccessKey != "AKIDCLI" {
+		t.Fatalf("Retrieve() = %+v, %v", creds, err)
+	}
+	if n := calls(); n != 1 {
+		t.Errorf("the CLI ran %d times, want once for the probe", n)
+	}
+}
+
+func TestCLIProviderRefreshesExpiringCredentials(t *testing.T) {
+	expires := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	calls := fakeAWSCLI(t, "echo export AWS_ACCESS_KEY_ID=ASIACLI; echo export AWS_SECRET_ACCESS_KEY=secret; echo export AWS_SESSION_TOKEN=token; echo export AWS_CREDENTIAL_EXPIRATION="+expires)
+
+	p := &CLIProvider{}
+	creds, err := p.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Expires.Format(time.RFC3339) != expires {
+		t.Errorf("Expires = %v, want %s", creds.Expires, expires)
+	}
+	// Credentials inside RefreshWindow are fetched again.
+	if _, err := p.Retrieve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls(); n != 2 {
+		t.Errorf("the CLI ran %d times, want twice for expiring credentials", n)
+	}
+}
+
+func TestResolveCredentialsReportsInvalidProfile(t *testing.T) {
+	clearAWSEnv(t)
+	calls := fakeAWSCLI(t, "echo export AWS_ACCESS_KEY_ID=AKIDCLI; echo export AWS_SECRET_ACCESS_KEY=secret")
+	writeSharedFiles(t, `[profile empty]
+region = us-west-2
+
+[profile role]
+role_arn = arn:aws:iam::123456789012:role/admin
+source_profile = empty
+
+[profile orphan]
+role_arn = arn:aws:iam::123456789012:role/admin
+`, "")
+	shared, err := LoadSharedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{"role", `source_profile "empty" has no credentials`},
+		{"orphan", "role_arn needs source_profile"},
+		{"missing", `profile "missing" not found`},
+	}
+	for _, tt := range tests {
+		p, err := resolveCredentials("", "", "", tt.profile, true, shared, "us-east-1", nil)
+		if p != nil || err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("resolveCredentials(%q) = %T, %v, want error containing %q", tt.profile, p, err, tt.want)
+		}
+	}
+	if n := calls(); n != 0 {
+		t.Errorf("the CLI ran %d times, want no fallback for an explicit profile", n)
+	}
+
+	// A profile picked by AWS_PROFILE may still be resolved by the CLI.
+	t.Setenv("AWS_PROFILE", "role")
+	p, err := resolveCredentials("", "", "", "role", false, shared, "us-east-1", nil)
+	if _, ok := p.(*CLIProvider); !ok || err != nil {
+		t.Errorf("resolveCredentials() = %T, %v, want *CLIProvider", p, err)
+	}
+}
+
+func TestAssumeRoleProviderWithoutSource(t *testing.T) {
+	p := &AssumeRoleProvider{RoleARN: "arn:aws:iam::123456789012:role/admin"}
+	if _, err := p.Retrieve(context.Background()); err == nil {
+		t.Error("Retrieve() with no source succeeded, want an error")
+	}
+}
+
+func TestAssumeRoleProviderCachesMFACredentials(t *testing.T) {
+	stsCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stsCalls++
+		_ = r.ParseForm()
+		if r.Form.Get("TokenCode") != "123456" {
+			t.Errorf("TokenCode = %q", r.Form.Get("TokenCode"))
+		}
+		fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>
+			<AccessKeyId>ASIAROLE</AccessKeyId><SecretAccessKey>secret</SecretAccessKey>
+			<SessionToken>token</SessionToken><Expiration>%s</Expiration>
+		</Credentials></AssumeRoleResult></AssumeRoleResponse>`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+	prompts := 0
+	cacheDir := t.TempDir()
+	newProvider := func() *AssumeRoleProvider {
+		return &AssumeRoleProvider{
+			Source:    StaticProvider{AccessKey: "AKIDSOURCE", SecretKey: "secret"},
+			RoleARN:   "arn:aws:iam::123456789012:role/admin",
+			MFASerial: "arn:aws:iam::123456789012:mfa/me",
+			Region:    "us-east-1",
+			HTTP:      server.Client(),
+			CacheDir:  cacheDir,
+			TokenCode: func(string) (string, error) {
+				prompts++
+				return "123456", nil
+			},
+		}
+	}
+
+	p := newProvider()
+	for i := 0; i < 3; i++ {
+		creds, err := p.Retrieve(context.Background())
+		if err != nil || creds.AccessKey != "ASIAROLE" {
+			t.Fatalf("Retrieve() = %+v, %v", creds, err)
+		}
+	}
+	// A later run reads the cache file.
+	if creds, err := newProvider().Retrieve(context.Background()); err != nil || creds.SessionToken != "token" {
+		t.Fatalf("Retrieve() from cache = %+v, %v", creds, err)
+	}
+	if prompts != 1 || stsCalls != 1 {
+		t.Errorf("prompted %d times and called STS %d times, want once each", prompts, stsCalls)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("cache files = %v", files)
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Other source keys do not reuse the cached role credentials.
+	other := newProvider()
+	other.Source = StaticProvider{AccessKey: "AKIDOTHER", SecretKey: "secret"}
+	if _, err := other.Retrieve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if prompts != 2 || stsCalls != 2 {
+		t.Errorf("prompted %d times and called STS %d times after a source change, want twice each", prompts, stsCalls)
+	}
+}
@@ -0,0 +1,232 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrUnsupportedService is returned when a service does not exist in the
+// partition of the requested region.
+var ErrUnsupportedService = errors.New("unsupported service")
+
+// Partition is a group of regions that share a DNS suffix and an ARN namespace.
+type Partition struct {
+	ID                 string
+	DNSSuffix          string
+	DualStackDNSSuffix string
+	// GlobalRegion hosts the partition's global services, such as Cost
+	// Explorer and Organizations, and is the region their requests are signed for.
+	GlobalRegion string
+
+	regions *regexp.Regexp
+}
+
+var partitions = []Partition{
+	{
+		ID:                 "aws-us-gov",
+		DNSSuffix:          "amazonaws.com",
+		DualStackDNSSuffix: "api.aws",
+		GlobalRegion:       "us-gov-west-1",
+		regions:            regexp.MustCompile(`^us-gov-\w+-\d+$`),
+	},
+	{
+		ID:                 "aws-cn",
+		DNSSuffix:          "amazonaws.com.cn",
+		DualStackDNSSuffix: "api.amazonwebservices.com.cn",
+		GlobalRegion:       "cn-northwest-1",
+		regions:            regexp.MustCompile(`^cn-\w+-\d+$`),
+	},
+	{
+		ID:                 "aws",
+		DNSSuffix:          "amazonaws.com",
+		DualStackDNSSuffix: "api.aws",
+		GlobalRegion:       "us-east-1",
+		regions:            regexp.MustCompile(`^(us|eu|ap|sa|ca|me|af|il|mx)-\w+-\d+$`),
+	},
+}
+
+// PartitionForRegion returns the partition a region belongs to. Unknown
+// regions are assumed to be in the commercial aws partition.
+func PartitionForRegion(region string) Partition {
+	for _, p := range partitions {
+		if p.regions.MatchString(region) {
+			return p
+		}
+	}
+	return partitions[len(partitions)-1]
+}
+
+// serviceEndpoint describes how the endpoint of one AWS API is built.
+type serviceEndpoint struct {
+	// id is the SDK service ID used in AWS_ENDPOINT_URL_<ID> overrides.
+	id string
+	// hostPrefix is the first part of the host name when it is not the
+	// signing name, e.g. portal.sso.
+	hostPrefix string
+	// global services have one endpoint per partition, in its global region.
+	global bool
+	// regionless global endpoints have no region in the host name.
+	regionless bool
+	// legacyDualStack services put "dualstack" before the region instead of
+	// using the partition's dual-stack suffix.
+	legacyDualStack bool
+	// partitions lists the only partitions the service exists in; nil means all.
+	partitions []string
+}
+
+// serviceEndpoints is keyed by signing name.
+var serviceEndpoints = map[string]serviceEndpoint{
+	"ce":                   {id: "COST_EXPLORER", global: true},
+	"freetier":             {id: "FREETIER", global: true, partitions: []string{"aws"}},
+	"budgets":              {id: "BUDGETS", global: true, regionless: true},
+	"organizations":        {id: "ORGANIZATIONS", global: true},
+	"ec2":                  {id: "EC2"},
+	"rds":                  {id: "RDS"},
+	"elasticloadbalancing": {id: "ELASTIC_LOAD_BALANCING_V2"},
+	"s3":                   {id: "S3", legacyDualStack: true},
+	"sts":                  {id: "STS"},
+	"sso":                  {id: "SSO", hostPrefix: "portal.sso"},
+}
+
+// Endpoint is where to send a request and how to sign it.
+type Endpoint struct {
+	// URL has no trailing slash.
+	URL           string
+	SigningName   string
+	SigningRegion string
+}
+
+// EndpointResolver builds service endpoints for a region. The zero value
+// resolves the standard endpoints of the region's partition.
+type EndpointResolver struct {
+	UseFIPS      bool
+	UseDualStack bool
+	// URL replaces the endpoint of every service, e.g. to point azguard at a
+	// local stand-in. ServiceURLs does the same per signing name and wins.
+	URL         string
+	ServiceURLs map[string]string
+}
+
+// NewEndpointResolver reads endpoint settings from the environment and then
+// the profile: AWS_ENDPOINT_URL_<SERVICE>, AWS_ENDPOINT_URL or endpoint_url,
+// and AWS_USE_FIPS_ENDPOINT / use_fips_endpoint and
+// AWS_USE_DUALSTACK_ENDPOINT / use_dualstack_endpoint. Setting
+// AWS_IGNORE_CONFIGURED_ENDPOINT_URLS=true turns the URL overrides off.
+// profile may be nil.
+func NewEndpointResolver(profile *Profile) *EndpointResolver {
+	if profile == nil {
+		profile = &Profile{}
+	}
+	r := &EndpointResolver{
+		UseFIPS:      envBool("AWS_USE_FIPS_ENDPOINT", profile.UseFIPSEndpoint),
+		UseDualStack: envBool("AWS_USE_DUALSTACK_ENDPOINT", profile.UseDualStackEndpoint),
+	}
+	if envBool("AWS_IGNORE_CONFIGURED_ENDPOINT_URLS", "") {
+		return r
+	}
+
+	r.URL = os.Getenv("AWS_ENDPOINT_URL")
+	if r.URL == "" {
+		r.URL = profile.EndpointURL
+	}
+	for name, svc := range serviceEndpoints {
+		if u := os.Getenv("AWS_ENDPOINT_URL_" + svc.id); u != "" {
+			if r.ServiceURLs == nil {
+				r.ServiceURLs = make(map[string]string)
+			}
+			r.ServiceURLs[name] = u
+		}
+	}
+	return r
+}
+
+// envEndpoints is used where no client is at hand, while resolving
+// credentials through STS and SSO.
+func envEndpoints() *EndpointResolver {
+	return NewEndpointResolver(nil)
+}
+
+// Resolve returns the endpoint of a service, named by its signing name, for
+// region. Global services ignore the region except to pick the partition.
+// A nil resolver resolves the standard endpoints.
+func (r *EndpointResolver) Resolve(service, region string) (Endpoint, error) {
+	if r == nil {
+		r = &EndpointResolver{}
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	svc, ok := serviceEndpoints[service]
+	if !ok {
+		svc = serviceEndpoint{id: strings.ToUpper(service)}
+	}
+	partition := PartitionForRegion(region)
+	if !svc.availableIn(partition.ID) {
+		return Endpoint{}, fmt.Errorf("%s: %w in the %s partition (region %s)", service, ErrUnsupportedService, partition.ID, region)
+	}
+	if svc.global {
+		region = partition.GlobalRegion
+	}
+	ep := Endpoint{SigningName: service, SigningRegion: region}
+
+	override := r.ServiceURLs[service]
+	if override == "" {
+		override = r.URL
+	}
+	if override != "" {
+		u, err := url.Parse(override)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return Endpoint{}, fmt.Errorf("invalid endpoint URL %q for %s: must be an absolute URL", override, service)
+		}
+		ep.URL = strings.TrimSuffix(override, "/")
+		return ep, nil
+	}
+
+	prefix := svc.hostPrefix
+	if prefix == "" {
+		prefix = service
+	}
+	if r.UseFIPS {
+		prefix += "-fips"
+	}
+
+	suffix := partition.DNSSuffix
+	host := prefix + "." + region
+	switch {
+	case r.UseDualStack && svc.legacyDualStack:
+		host = prefix + ".dualstack." + region
+	case r.UseDualStack:
+		suffix = partition.DualStackDNSSuffix
+	}
+	if svc.regionless && !r.UseFIPS && !r.UseDualStack {
+		host = prefix
+	}
+
+	ep.URL = "https://" + host + "." + suffix
+	return ep, nil
+}
+
+func (s serviceEndpoint) availableIn(partition string) bool {
+	if s.partitions == nil {
+		return true
+	}
+	for _, p := range s.partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
+func envBool(name, fallback string) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		v = fallback
+	}
+	return strings.EqualFold(strings.TrimSpace(v), "true")
+}
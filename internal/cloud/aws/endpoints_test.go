@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	tests := map[string]string{
+		"us-east-1":      "aws",
+		"eu-central-2":   "aws",
+		"il-central-1":   "aws",
+		"us-gov-west-1":  "aws-us-gov",
+		"us-gov-east-1":  "aws-us-gov",
+		"cn-north-1":     "aws-cn",
+		"cn-northwest-1": "aws-cn",
+		"xx-unknown-1":   "aws",
+	}
+	for region, want := range tests {
+		if got := PartitionForRegion(region).ID; got != want {
+			t.Errorf("PartitionForRegion(%q) = %s, want %s", region, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver *EndpointResolver
+		service  string
+		region   string
+		url      string
+		signing  string
+	}{
+		{"regional", nil, "ec2", "eu-west-1", "https://ec2.eu-west-1.amazonaws.com", "eu-west-1"},
+		{"empty region", nil, "sts", "", "https://sts.us-east-1.amazonaws.com", "us-east-1"},
+		{"global", nil, "ce", "eu-west-1", "https://ce.us-east-1.amazonaws.com", "us-east-1"},
+		{"global in gov", nil, "ce", "us-gov-east-1", "https://ce.us-gov-west-1.amazonaws.com", "us-gov-west-1"},
+		{"global in china", nil, "organizations", "cn-north-1", "https://organizations.cn-northwest-1.amazonaws.com.cn", "cn-northwest-1"},
+		{"regionless", nil, "budgets", "eu-west-1", "https://budgets.amazonaws.com", "us-east-1"},
+		{"china", nil, "ec2", "cn-north-1", "https://ec2.cn-north-1.amazonaws.com.cn", "cn-north-1"},
+		{"host prefix", nil, "sso", "eu-west-1", "https://portal.sso.eu-west-1.amazonaws.com", "eu-west-1"},
+		{"unknown service", nil, "lambda", "us-west-2", "https://lambda.us-west-2.amazonaws.com", "us-west-2"},
+		{"fips", &EndpointResolver{UseFIPS: true}, "ec2", "us-gov-west-1", "https://ec2-fips.us-gov-west-1.amazonaws.com", "us-gov-west-1"},
+		{"fips regionless", &EndpointResolver{UseFIPS: true}, "budgets", "us-east-1", "https://budgets-fips.us-east-1.amazonaws.com", "us-east-1"},
+		{"dual-stack", &EndpointResolver{UseDualStack: true}, "ec2", "eu-west-1", "https://ec2.eu-west-1.api.aws", "eu-west-1"},
+		{"dual-stack china", &EndpointResolver{UseDualStack: true}, "ec2", "cn-north-1", "https://ec2.cn-north-1.api.amazonwebservices.com.cn", "cn-north-1"},
+		{"dual-stack s3", &EndpointResolver{UseDualStack: true}, "s3", "eu-west-1", "https://s3.dualstack.eu-west-1.amazonaws.com", "eu-west-1"},
+		{"fips and dual-stack", &EndpointResolver{UseFIPS: true, UseDualStack: true}, "sts", "us-east-1", "https://sts-fips.us-east-1.api.aws", "us-east-1"},
+		{"url override", &EndpointResolver{URL: "http://localhost:4566/"}, "ce", "eu-west-1", "http://localhost:4566", "us-east-1"},
+		{
+			"service url wins",
+			&EndpointResolver{URL: "http://localhost:4566", ServiceURLs: map[string]string{"sts": "http://localhost:5000"}},
+			"sts", "eu-west-1", "http://localhost:5000", "eu-west-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep, err := tt.resolver.Resolve(tt.service, tt.region)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ep.URL != tt.url || ep.SigningRegion != tt.signing || ep.SigningName != tt.service {
+				t.Errorf("Resolve(%s, %s) = %+v, want %s signed for %s", tt.service, tt.region, ep, tt.url, tt.signing)
+			}
+		})
+	}
+}
+
+func TestResolveFreeTierOnlyInCommercialPartition(t *testing.T) {
+	ep, err := (&EndpointResolver{}).Resolve("freetier", "eu-west-1")
+	if err != nil || ep.URL != "https://freetier.us-east-1.amazonaws.com" {
+		t.Errorf("Resolve(freetier, eu-west-1) = %+v, %v", ep, err)
+	}
+
+	for _, region := range []string{"cn-north-1", "us-gov-west-1"} {
+		_, err := (&EndpointResolver{}).Resolve("freetier", region)
+		if !errors.Is(err, ErrUnsupportedService) {
+			t.Errorf("Resolve(freetier, %s) error = %v, want ErrUnsupportedService", region, err)
+		}
+	}
+}
+
+func TestResolveRejectsRelativeOverride(t *testing.T) {
+	r := &EndpointResolver{URL: "localhost:4566"}
+	if _, err := r.Resolve("ce", "us-east-1"); err == nil {
+		t.Error("Resolve() accepted an endpoint URL without a scheme")
+	}
+}
+
+func TestNewEndpointResolver(t *testing.T) {
+	t.Setenv("AWS_ENDPOINT_URL", "http://localhost:4566")
+	t.Setenv("AWS_ENDPOINT_URL_COST_EXPLORER", "http://localhost:5000")
+	t.Setenv("AWS_USE_FIPS_ENDPOINT", "TRUE")
+	t.Setenv("AWS_USE_DUALSTACK_ENDPOINT", "")
+
+	profile := &Profile{EndpointURL: "http://profile:1", UseDualStackEndpoint: "true", UseFIPSEndpoint: "false"}
+	r := NewEndpointResolver(profile)
+	if !r.UseFIPS || !r.UseDualStack {
+		t.Errorf("UseFIPS = %v, UseDualStack = %v, want the environment's then the profile's setting", r.UseFIPS, r.UseDualStack)
+	}
+	if r.URL != "http://localhost:4566" {
+		t.Errorf("URL = %q, want AWS_ENDPOINT_URL over the profile", r.URL)
+	}
+
+	ce, _ := r.Resolve("ce", "us-east-1")
+	sts, _ := r.Resolve("sts", "us-east-1")
+	if ce.URL != "http://localhost:5000" || sts.URL != "http://localhost:4566" {
+		t.Errorf("ce = %s, sts = %s", ce.URL, sts.URL)
+	}
+
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	if r := NewEndpointResolver(profile); r.URL != "http://profile:1" {
+		t.Errorf("URL = %q, want the profile's endpoint_url", r.URL)
+	}
+
+	t.Setenv("AWS_IGNORE_CONFIGURED_ENDPOINT_URLS", "true")
+	r = NewEndpointResolver(profile)
+	if r.URL != "" || r.ServiceURLs != nil {
+		t.Errorf("overrides kept with AWS_IGNORE_CONFIGURED_ENDPOINT_URLS: %+v", r)
+	}
+	if !r.UseFIPS {
+		t.Error("AWS_IGNORE_CONFIGURED_ENDPOINT_URLS turned off FIPS")
+	}
+}
@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ForecastConfidence is the prediction interval level requested from Cost Explorer.
+const ForecastConfidence = 80
+
+// CostForecast is a Cost Explorer forecast for the rest of a period, with
+// the bounds of its prediction interval.
+type CostForecast struct {
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	Mean       float64 `json:"mean"`
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	Currency   string  `json:"currency"`
+	Confidence int     `json:"confidence"`
+}
+
+// GetCostForecast forecasts unblended cost from startDate to endDate
+// (exclusive). startDate must not be in the past. A non-empty service limits
+// the forecast to that Cost Explorer SERVICE value.
+func (c *CostClient) GetCostForecast(ctx context.Context, startDate, endDate, service string) (*CostForecast, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	request := map[string]interface{}{
+		"TimePeriod":              map[string]string{"Start": startDate, "End": endDate},
+		"Metric":                  "UNBLENDED_COST",
+		"Granularity":             "MONTHLY",
+		"PredictionIntervalLevel": ForecastConfidence,
+	}
+	if service != "" {
+		request["Filter"] = map[string]interface{}{
+			"Dimensions": map[string]interface{}{"Key": "SERVICE", "Values": []string{service}},
+		}
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.callAPI(ctx, "ce", "AWSInsightsIndexService.GetCostForecast", string(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost forecast: %w", err)
+	}
+
+	type amount struct {
+		Amount string `json:"Amount"`
+		Unit   string `json:"Unit"`
+	}
+	var resp struct {
+		Total                 amount `json:"Total"`
+		ForecastResultsByTime []struct {
+			MeanValue                    string `json:"MeanValue"`
+			PredictionIntervalLowerBound string `json:"PredictionIntervalLowerBound"`
+			PredictionIntervalUpperBound string `json:"PredictionIntervalUpperBound"`
+		} `json:"ForecastResultsByTime"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
+	}
+
+	forecast := &CostForecast{
+		Start:      startDate,
+		End:        endDate,
+		Currency:   resp.Total.Unit,
+		Confidence: ForecastConfidence,
+	}
+	if forecast.Mean, err = parseCost(resp.Total.Amount); err != nil {
+		return nil, err
+	}
+	for _, r := range resp.ForecastResultsByTime {
+		lower, err := parseBound(r.PredictionIntervalLowerBound)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := parseBound(r.PredictionIntervalUpperBound)
+		if err != nil {
+			return nil, err
+		}
+		forecast.Lower += lower
+		forecast.Upper += upper
+	}
+	if forecast.Currency == "" {
+		forecast.Currency = "USD"
+	}
+
+	return forecast, nil
+}
+
+// parseBound parses a prediction interval bound, which is only sent when an
+// interval was requested.
+func parseBound(amount string) (float64, error) {
+	if amount == "" {
+		return 0, nil
+	}
+	return parseCost(amount)
+}
+
+// RemainingMonth returns the forecast window for the current month: today
+// through the first day of next month.
+func RemainingMonth() (startDate, endDate string) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Format("2006-01-02"), end.Format("2006-01-02")
+}
@@ -0,0 +1,633 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ec2APIVersion   = "2016-11-15"
+	rdsAPIVersion   = "2014-10-31"
+	elbv2APIVersion = "2015-12-01"
+
+	// FreeTierEBSGB is the EBS storage included in the free tier each month.
+	FreeTierEBSGB = 30
+
+	// FreeTierPublicIPv4Hours is the public IPv4 address time included in the
+	// free tier each month of the first year; beyond it every public IPv4
+	// address is billed hourly, in use or not.
+	FreeTierPublicIPv4Hours = 750
+
+	// regionConcurrency bounds how many regions are swept at once.
+	regionConcurrency = 6
+)
+
+// Instance classes covered by the 750 hours/month free tier allowance.
+var (
+	freeTierInstanceTypes = map[string]bool{"t2.micro": true, "t3.micro": true}
+	freeTierDBClasses     = map[string]bool{"db.t2.micro": true, "db.t3.micro": true, "db.t4g.micro": true}
+)
+
+// Resource is one billable-or-free AWS resource found by an inventory sweep.
+type Resource struct {
+	Region   string `json:"region"`
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Detail   string `json:"detail"`
+	SizeGB   int    `json:"size_gb,omitempty"`
+	FreeTier bool   `json:"free_tier"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Inventory is the result of a sweep. Errors lists the region/service calls
+// that failed; the rest of the sweep still completes.
+type Inventory struct {
+	Regions   []string   `json:"regions"`
+	Resources []Resource `json:"resources"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// PublicIPv4Hours estimates a month of public IPv4 hours for the Elastic IPs
+// in the inventory.
+func (inv *Inventory) PublicIPv4Hours() int {
+	hours := 0
+	for _, r := range inv.Resources {
+		if r.Type == "Elastic IP" {
+			hours += 730
+		}
+	}
+	return hours
+}
+
+// EBSTotalGB sums the size of all EBS volumes in the inventory.
+func (inv *Inventory) EBSTotalGB() int {
+	total := 0
+	for _, r := range inv.Resources {
+		if r.Type == "EBS volume" {
+			total += r.SizeGB
+		}
+	}
+	return total
+}
+
+// ListResources sweeps EC2 instances, EBS volumes and snapshots, Elastic IPs,
+// NAT gateways, RDS instances and load balancers in every enabled region (or
+// only the given ones), plus S3 buckets, and flags each as free tier or billable.
+func (c *CostClient) ListResources(ctx context.Context, regions []string) (*Inventory, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	if len(regions) == 0 {
+		var err error
+		if regions, err = c.ListRegions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	inv := &Inventory{Regions: regions}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, regionConcurrency)
+	)
+	collect := func(region, what string, resources []Resource, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			inv.Errors = append(inv.Errors, fmt.Sprintf("%s %s: %v", region, what, err))
+			return
+		}
+		inv.Resources = append(inv.Resources, resources...)
+	}
+
+	for _, region := range regions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r, err := c.listInstances(ctx, region)
+			collect(region, "instances", r, err)
+			r, err = c.listVolumes(ctx, region)
+			collect(region, "volumes", r, err)
+			r, err = c.listSnapshots(ctx, region)
+			collect(region, "snapshots", r, err)
+			r, err = c.listAddresses(ctx, region)
+			collect(region, "elastic IPs", r, err)
+			r, err = c.listNATGateways(ctx, region)
+			collect(region, "NAT gateways", r, err)
+			r, err = c.listDBInstances(ctx, region)
+			collect(region, "RDS", r, err)
+			r, err = c.listLoadBalancers(ctx, region)
+			collect(region, "load balancers", r, err)
+		}(region)
+	}
+
+	buckets, err := c.listBuckets(ctx, regions)
+	collect("global", "S3", buckets, err)
+
+	wg.Wait()
+
+	sort.Slice(inv.Resources, func(i, j int) bool {
+		a, b := inv.Resources[i], inv.Resources[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.ID < b.ID
+	})
+	sort.Strings(inv.Errors)
+	return inv, nil
+}
+
+// ListRegions returns the regions enabled for the account.
+func (c *CostClient) ListRegions(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Regions []string `xml:"regionInfo>item>regionName"`
+	}
+	params := url.Values{"Action": {"DescribeRegions"}, "Version": {ec2APIVersion}}
+	if err := c.queryAPI(ctx, "ec2", c.homeRegion(), params, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+	sort.Strings(resp.Regions)
+	return resp.Regions, nil
+}
+
+type ec2Tag struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+func tagName(tags []ec2Tag) string {
+	for _, t := range tags {
+		if t.Key == "Name" {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+func (c *CostClient) listInstances(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+	err := c.ec2Pages(ctx, region, url.Values{"Action": {"DescribeInstances"}}, func(body []byte) (string, error) {
+		var resp struct {
+			Instances []struct {
+				ID    string   `xml:"instanceId"`
+				Type  string   `xml:"instanceType"`
+				State string   `xml:"instanceState>name"`
+				Tags  []ec2Tag `xml:"tagSet>item"`
+			} `xml:"reservationSet>item>instancesSet>item"`
+			NextToken string `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return "", err
+		}
+		for _, i := range resp.Instances {
+			if i.State == "terminated" || i.State == "shutting-down" {
+				continue
+			}
+			r := Resource{
+				Region:   region,
+				Type:     "EC2 instance",
+				ID:       i.ID,
+				Name:     tagName(i.Tags),
+				Detail:   i.Type + " " + i.State,
+				FreeTier: freeTierInstanceTypes[i.Type] || i.State == "stopped",
+			}
+			switch {
+			case i.State == "stopped":
+				r.Note = "stopped: no compute charge, attached volumes still billed"
+			case !r.FreeTier:
+				r.Note = i.Type + " is not free tier eligible"
+			}
+			resources = append(resources, r)
+		}
+		return resp.NextToken, nil
+	})
+	return resources, err
+}
+
+func (c *CostClient) listVolumes(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+	err := c.ec2Pages(ctx, region, url.Values{"Action": {"DescribeVolumes"}}, func(body []byte) (string, error) {
+		var resp struct {
+			Volumes []struct {
+				ID     string   `xml:"volumeId"`
+				Size   int      `xml:"size"`
+				Type   string   `xml:"volumeType"`
+				Status string   `xml:"status"`
+				Tags   []ec2Tag `xml:"tagSet>item"`
+			} `xml:"volumeSet>item"`
+			NextToken string `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return "", err
+		}
+		for _, v := range resp.Volumes {
+			r := Resource{
+				Region:   region,
+				Type:     "EBS volume",
+				ID:       v.ID,
+				Name:     tagName(v.Tags),
+				Detail:   fmt.Sprintf("%d GB %s %s", v.Size, v.Type, v.Status),
+				SizeGB:   v.Size,
+				FreeTier: v.Type == "gp2" || v.Type == "gp3" || v.Type == "standard",
+			}
+			switch {
+			case !r.FreeTier:
+				r.Note = v.Type + " volumes are not covered by the free tier"
+			case v.Status == "available":
+				r.Note = "unattached"
+			}
+			resources = append(resources, r)
+		}
+		return resp.NextToken, nil
+	})
+	return resources, err
+}
+
+func (c *CostClient) listSnapshots(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+	params := url.Values{"Action": {"DescribeSnapshots"}, "Owner.1": {"self"}}
+	err := c.ec2Pages(ctx, region, params, func(body []byte) (string, error) {
+		var resp struct {
+			Snapshots []struct {
+				ID          string   `xml:"snapshotId"`
+				VolumeSize  int      `xml:"volumeSize"`
+				StartTime   string   `xml:"startTime"`
+				Description string   `xml:"description"`
+				Tags        []ec2Tag `xml:"tagSet>item"`
+			} `xml:"snapshotSet>item"`
+			NextToken string `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return "", err
+		}
+		for _, s := range resp.Snapshots {
+			name := tagName(s.Tags)
+			if name == "" {
+				name = s.Description
+			}
+			resources = append(resources, Resource{
+				Region: region,
+				Type:   "EBS snapshot",
+				ID:     s.ID,
+				Name:   name,
+				Detail: fmt.Sprintf("of %d GB volume, taken %s", s.VolumeSize, strings.SplitN(s.StartTime, "T", 2)[0]),
+				Note:   "snapshot storage beyond 1 GB is billed",
+			})
+		}
+		return resp.NextToken, nil
+	})
+	return resources, err
+}
+
+func (c *CostClient) listAddresses(ctx context.Context, region string) ([]Resource, error) {
+	var resp struct {
+		Addresses []struct {
+			PublicIP      string   `xml:"publicIp"`
+			AllocationID  string   `xml:"allocationId"`
+			AssociationID string   `xml:"associationId"`
+			InstanceID    string   `xml:"instanceId"`
+			Tags          []ec2Tag `xml:"tagSet>item"`
+		} `xml:"addressesSet>item"`
+	}
+	params := url.Values{"Action": {"DescribeAddresses"}, "Version": {ec2APIVersion}}
+	if err := c.queryAPI(ctx, "ec2", region, params, &resp); err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	for _, a := range resp.Addresses {
+		r := Resource{
+			Region: region,
+			Type:   "Elastic IP",
+			ID:     a.AllocationID,
+			Name:   tagName(a.Tags),
+			Detail: a.PublicIP,
+			Note:   fmt.Sprintf("public IPv4 is billed hourly beyond %d free hours/month in the first year", FreeTierPublicIPv4Hours),
+		}
+		if a.AssociationID == "" {
+			r.Detail += " unassociated"
+		} else if a.InstanceID != "" {
+			r.Detail += " → " + a.InstanceID
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+func (c *CostClient) listNATGateways(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+	err := c.ec2Pages(ctx, region, url.Values{"Action": {"DescribeNatGateways"}}, func(body []byte) (string, error) {
+		var resp struct {
+			Gateways []struct {
+				ID    string   `xml:"natGatewayId"`
+				State string   `xml:"state"`
+				VPC   string   `xml:"vpcId"`
+				Tags  []ec2Tag `xml:"tagSet>item"`
+			} `xml:"natGatewaySet>item"`
+			NextToken string `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return "", err
+		}
+		for _, g := range resp.Gateways {
+			if g.State == "deleted" || g.State == "failed" {
+				continue
+			}
+			resources = append(resources, Resource{
+				Region: region,
+				Type:   "NAT gateway",
+				ID:     g.ID,
+				Name:   tagName(g.Tags),
+				Detail: g.State + " in " + g.VPC,
+				Note:   "NAT gateways are billed hourly plus per GB",
+			})
+		}
+		return resp.NextToken, nil
+	})
+	return resources, err
+}
+
+func (c *CostClient) listDBInstances(ctx context.Context, region string) ([]Resource, error) {
+	var (
+		resources []Resource
+		marker    string
+	)
+	for {
+		params := url.Values{"Action": {"DescribeDBInstances"}, "Version": {rdsAPIVersion}}
+		if marker != "" {
+			params.Set("Marker", marker)
+		}
+		var resp struct {
+			Instances []struct {
+				ID      string `xml:"DBInstanceIdentifier"`
+				Class   string `xml:"DBInstanceClass"`
+				Engine  string `xml:"Engine"`
+				Status  string `xml:"DBInstanceStatus"`
+				MultiAZ bool   `xml:"MultiAZ"`
+				Storage int    `xml:"AllocatedStorage"`
+			} `xml:"DescribeDBInstancesResult>DBInstances>DBInstance"`
+			Marker string `xml:"DescribeDBInstancesResult>Marker"`
+		}
+		if err := c.queryAPI(ctx, "rds", region, params, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, db := range resp.Instances {
+			r := Resource{
+				Region:   region,
+				Type:     "RDS instance",
+				ID:       db.ID,
+				Detail:   fmt.Sprintf("%s %s %d GB %s", db.Class, db.Engine, db.Storage, db.Status),
+				SizeGB:   db.Storage,
+				FreeTier: freeTierDBClasses[db.Class] && !db.MultiAZ && !strings.HasPrefix(db.Engine, "aurora"),
+			}
+			switch {
+			case db.MultiAZ:
+				r.Note = "Multi-AZ is not free tier eligible"
+			case strings.HasPrefix(db.Engine, "aurora"):
+				r.Note = "Aurora is not free tier eligible"
+			case !r.FreeTier:
+				r.Note = db.Class + " is not free tier eligible"
+			case db.Storage > 20:
+				r.Note = "storage beyond 20 GB is billed"
+			}
+			resources = append(resources, r)
+		}
+
+		if resp.Marker == "" {
+			return resources, nil
+		}
+		marker = resp.Marker
+	}
+}
+
+func (c *CostClient) listLoadBalancers(ctx context.Context, region string) ([]Resource, error) {
+	var (
+		resources []Resource
+		marker    string
+	)
+	for {
+		params := url.Values{"Action": {"DescribeLoadBalancers"}, "Version": {elbv2APIVersion}}
+		if marker != "" {
+			params.Set("Marker", marker)
+		}
+		var resp struct {
+			LoadBalancers []struct {
+				Name  string `xml:"LoadBalancerName"`
+				ARN   string `xml:"LoadBalancerArn"`
+				Type  string `xml:"Type"`
+				State string `xml:"State>Code"`
+			} `xml:"DescribeLoadBalancersResult>LoadBalancers>member"`
+			NextMarker string `xml:"DescribeLoadBalancersResult>NextMarker"`
+		}
+		if err := c.queryAPI(ctx, "elasticloadbalancing", region, params, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, lb := range resp.LoadBalancers {
+			r := Resource{
+				Region:   region,
+				Type:     "Load balancer",
+				ID:       lb.ARN,
+				Name:     lb.Name,
+				Detail:   lb.Type + " " + lb.State,
+				FreeTier: lb.Type == "application",
+			}
+			if !r.FreeTier {
+				r.Note = lb.Type + " load balancers are not free tier eligible"
+			}
+			resources = append(resources, r)
+		}
+
+		if resp.NextMarker == "" {
+			return resources, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// listBuckets lists S3 buckets once, keeping those in the swept regions.
+func (c *CostClient) listBuckets(ctx context.Context, regions []string) ([]Resource, error) {
+	body, err := c.restGet(ctx, "s3", c.s3Region(), "/")
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Buckets []struct {
+			Name         string `xml:"Name"`
+			CreationDate string `xml:"CreationDate"`
+			Region       string `xml:"BucketRegion"`
+		} `xml:"Buckets>Bucket"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse ListBuckets response: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(regions))
+	for _, r := range regions {
+		wanted[r] = true
+	}
+
+	var resources []Resource
+	for _, b := range resp.Buckets {
+		region := b.Region
+		if region == "" {
+			region = c.bucketRegion(ctx, b.Name)
+		}
+		if region != "" && !wanted[region] {
+			continue
+		}
+		resources = append(resources, Resource{
+			Region:   region,
+			Type:     "S3 bucket",
+			ID:       b.Name,
+			Detail:   "created " + strings.SplitN(b.CreationDate, "T", 2)[0],
+			FreeTier: true,
+			Note:     "5 GB of Standard storage is free",
+		})
+	}
+	return resources, nil
+}
+
+// bucketRegion asks GetBucketLocation for older responses without BucketRegion.
+func (c *CostClient) bucketRegion(ctx context.Context, bucket string) string {
+	body, err := c.restGet(ctx, "s3", c.s3Region(), "/"+url.PathEscape(bucket)+"?location")
+	if err != nil {
+		return ""
+	}
+	var loc struct {
+		Constraint string `xml:",chardata"`
+	}
+	if err := xml.Unmarshal(body, &loc); err != nil {
+		return ""
+	}
+	return locationRegion(loc.Constraint)
+}
+
+// locationRegion maps a GetBucketLocation constraint to a region. Buckets in
+// us-east-1 have none, and old eu-west-1 buckets report the legacy "EU".
+func locationRegion(constraint string) string {
+	switch constraint {
+	case "":
+		return "us-east-1"
+	case "EU":
+		return "eu-west-1"
+	}
+	return constraint
+}
+
+// ec2Pages calls an EC2 Describe action until nextToken is empty. page parses
+// one response body and returns its nextToken.
+func (c *CostClient) ec2Pages(ctx context.Context, region string, params url.Values, page func([]byte) (string, error)) error {
+	params.Set("Version", ec2APIVersion)
+	for {
+		body, err := c.restGet(ctx, "ec2", region, "/?"+params.Encode())
+		if err != nil {
+			return err
+		}
+		next, err := page(body)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s response: %w", params.Get("Action"), err)
+		}
+		if next == "" {
+			return nil
+		}
+		params.Set("NextToken", next)
+	}
+}
+
+// queryAPI calls a query-protocol API with GET and decodes the XML response.
+func (c *CostClient) queryAPI(ctx context.Context, service, region string, params url.Values, out interface{}) error {
+	body, err := c.restGet(ctx, service, region, "/?"+params.Encode())
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", params.Get("Action"), err)
+	}
+	return nil
+}
+
+// restGet sends a signed GET for path, which includes the query, to a
+// service in region and returns the body of a 200 response.
+func (c *CostClient) restGet(ctx context.Context, service, region, path string) ([]byte, error) {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ep, err := c.endpoint(service, region)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ep.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := signV4(req, nil, creds, ep.SigningRegion, ep.SigningName); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// EC2 nests errors in Errors>Error, RDS and ELB in Error, S3 at the root.
+		var apiErr struct {
+			EC2Code    string `xml:"Errors>Error>Code"`
+			EC2Message string `xml:"Errors>Error>Message"`
+			Code       string `xml:"Error>Code"`
+			Message    string `xml:"Error>Message"`
+			S3Code     string `xml:"Code"`
+			S3Message  string `xml:"Message"`
+		}
+		if xml.Unmarshal(body, &apiErr) == nil {
+			for _, e := range [][2]string{{apiErr.EC2Code, apiErr.EC2Message}, {apiErr.Code, apiErr.Message}, {apiErr.S3Code, apiErr.S3Message}} {
+				if e[0] != "" {
+					return nil, fmt.Errorf("%s: %s", e[0], e[1])
+				}
+			}
+		}
+		return nil, fmt.Errorf("AWS API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+func (c *CostClient) endpoint(service, region string) (Endpoint, error) {
+	return c.Endpoints.Resolve(service, region)
+}
+
+// s3Region is where bucket listing is sent: any region lists every bucket,
+// and the partition's global region matches the old s3.amazonaws.com endpoint.
+func (c *CostClient) s3Region() string {
+	return PartitionForRegion(c.homeRegion()).GlobalRegion
+}
+
+func (c *CostClient) homeRegion() string {
+	if c.Region != "" {
+		return c.Region
+	}
+	return "us-east-1"
+}
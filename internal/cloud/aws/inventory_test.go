@@ -0,0 +1,28 @@
+package aws
+
+import "testing"
+
+func TestLocationRegion(t *testing.T) {
+	tests := map[string]string{
+		"":             "us-east-1",
+		"EU":           "eu-west-1",
+		"eu-central-1": "eu-central-1",
+		"us-west-2":    "us-west-2",
+	}
+	for constraint, want := range tests {
+		if got := locationRegion(constraint); got != want {
+			t.Errorf("locationRegion(%q) = %q, want %q", constraint, got, want)
+		}
+	}
+}
+
+func TestPublicIPv4Hours(t *testing.T) {
+	inv := &Inventory{Resources: []Resource{
+		{Type: "Elastic IP", ID: "eipalloc-1"},
+		{Type: "Elastic IP", ID: "eipalloc-2"},
+		{Type: "EBS volume", ID: "vol-1", SizeGB: 8},
+	}}
+	if got := inv.PublicIPv4Hours(); got != 1460 || got <= FreeTierPublicIPv4Hours {
+		t.Errorf("PublicIPv4Hours() = %d, want 1460, above the free %d", got, FreeTierPublicIPv4Hours)
+	}
+}
@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DefaultMemberRole is the role AWS Organizations creates in every account it
+// creates, trusted by the management account.
+const DefaultMemberRole = "OrganizationAccountAccessRole"
+
+// Account is a member account of an AWS Organization.
+type Account struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+}
+
+// ListAccounts returns every account in the organization, sorted by name.
+// It must be called with management or delegated administrator credentials.
+func (c *CostClient) ListAccounts(ctx context.Context) ([]Account, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	var (
+		accounts  []Account
+		nextToken string
+	)
+	for {
+		request := map[string]interface{}{}
+		if nextToken != "" {
+			request["NextToken"] = nextToken
+		}
+		payload, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.callAPI(ctx, "organizations", "AWSOrganizationsV20161128.ListAccounts", string(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization accounts: %w", err)
+		}
+
+		var resp struct {
+			Accounts []struct {
+				ID     string `json:"Id"`
+				Name   string `json:"Name"`
+				Email  string `json:"Email"`
+				Status string `json:"Status"`
+			} `json:"Accounts"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse accounts response: %w", err)
+		}
+
+		for _, a := range resp.Accounts {
+			accounts = append(accounts, Account{ID: a.ID, Name: a.Name, Email: a.Email, Status: a.Status})
+		}
+
+		if resp.NextToken == "" {
+			break
+		}
+		nextToken = resp.NextToken
+	}
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Name < accounts[j].Name })
+	return accounts, nil
+}
+
+// ForAccount returns a client that assumes role in the member account, using
+// this client's credentials as the source. An empty role uses DefaultMemberRole.
+func (c *CostClient) ForAccount(accountID, role string) *CostClient {
+	if role == "" {
+		role = DefaultMemberRole
+	}
+	return &CostClient{
+		Region:  c.Region,
+		Profile: c.Profile,
+		Credentials: &AssumeRoleProvider{
+			Source:      clientCredentials{c},
+			RoleARN:     fmt.Sprintf("arn:%s:iam::%s:role/%s", PartitionForRegion(c.Region).ID, accountID, role),
+			SessionName: "azguard-" + accountID,
+			Region:      c.Region,
+			HTTP:        c.HTTP,
+		},
+		Endpoints: c.Endpoints,
+		HTTP:      c.HTTP,
+		accountID: accountID,
+	}
+}
+
+// clientCredentials reuses a client's cached credentials as a role source.
+type clientCredentials struct {
+	client *CostClient
+}
+
+func (p clientCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	return p.client.credentials(ctx)
+}
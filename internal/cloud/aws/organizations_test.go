@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetDailyCostsByAccount(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			GroupBy []map[string]string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]string{{"Type": "DIMENSION", "Key": "LINKED_ACCOUNT"}, {"Type": "DIMENSION", "Key": "SERVICE"}}
+		if !reflect.DeepEqual(req.GroupBy, want) {
+			t.Errorf("GroupBy = %v, want linked account then service", req.GroupBy)
+		}
+		_, _ = w.Write([]byte(`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+			{"Keys": ["111111111111", "Amazon Simple Storage Service"], "Metrics": {"UnblendedCost": {"Amount": "1.5", "Unit": "USD"}}},
+			{"Keys": ["222222222222", "AWS Lambda"], "Metrics": {"UnblendedCost": {"Amount": "0.25", "Unit": "USD"}}},
+			{"Keys": ["Tax"], "Metrics": {"UnblendedCost": {"Amount": "9", "Unit": "USD"}}}
+		]}]}`))
+	})
+
+	records, err := c.GetDailyCostsByAccount(context.Background(), "2024-05-01", "2024-05-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CostRecord{
+		{AccountID: "111111111111", ServiceName: "Amazon Simple Storage Service", Cost: 1.5, Currency: "USD", Date: "2024-05-01"},
+		{AccountID: "222222222222", ServiceName: "AWS Lambda", Cost: 0.25, Currency: "USD", Date: "2024-05-01"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("GetDailyCostsByAccount() = %+v\nwant %+v (groups without both keys dropped)", records, want)
+	}
+}
+
+func TestListAccountsFollowsPages(t *testing.T) {
+	pages := map[string]string{
+		"": `{"Accounts": [
+			{"Id": "333333333333", "Name": "sandbox", "Email": "sandbox@example.com", "Status": "ACTIVE"},
+			{"Id": "111111111111", "Name": "management", "Email": "root@example.com", "Status": "ACTIVE"}
+		], "NextToken": "t-2"}`,
+		"t-2": `{"Accounts": [
+			{"Id": "222222222222", "Name": "prod", "Email": "prod@example.com", "Status": "SUSPENDED"}
+		]}`,
+	}
+	var tokens []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "AWSOrganizationsV20161128.ListAccounts" {
+			t.Errorf("target = %q", target)
+		}
+		var req struct{ NextToken string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, req.NextToken)
+		_, _ = w.Write([]byte(pages[req.NextToken]))
+	})
+
+	accounts, err := c.ListAccounts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"", "t-2"}) {
+		t.Errorf("tokens = %q, want both pages", tokens)
+	}
+	want := []Account{
+		{ID: "111111111111", Name: "management", Email: "root@example.com", Status: "ACTIVE"},
+		{ID: "222222222222", Name: "prod", Email: "prod@example.com", Status: "SUSPENDED"},
+		{ID: "333333333333", Name: "sandbox", Email: "sandbox@example.com", Status: "ACTIVE"},
+	}
+	if !reflect.DeepEqual(accounts, want) {
+		t.Errorf("ListAccounts() = %+v\nwant %+v (sorted by name)", accounts, want)
+	}
+}
+
+func TestForAccountAssumesMemberRole(t *testing.T) {
+	tests := []struct {
+		region, role, want string
+	}{
+		{"us-east-1", "", "arn:aws:iam::222222222222:role/OrganizationAccountAccessRole"},
+		{"eu-west-1", "AuditRole", "arn:aws:iam::222222222222:role/AuditRole"},
+		{"us-gov-west-1", "", "arn:aws-us-gov:iam::222222222222:role/OrganizationAccountAccessRole"},
+	}
+	for _, tt := range tests {
+		c := &CostClient{Region: tt.region, Credentials: StaticProvider{AccessKey: "AKID", SecretKey: "secret"}}
+		member := c.ForAccount("222222222222", tt.role)
+		p, ok := member.Credentials.(*AssumeRoleProvider)
+		if !ok {
+			t.Fatalf("credentials = %T, want an AssumeRoleProvider", member.Credentials)
+		}
+		if p.RoleARN != tt.want || p.SessionName != "azguard-222222222222" {
+			t.Errorf("ForAccount(%s, %q) role = %s session %s, want %s", tt.region, tt.role, p.RoleARN, p.SessionName, tt.want)
+		}
+	}
+}
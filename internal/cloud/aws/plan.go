@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Account plan types and statuses reported by the Free Tier API.
+const (
+	PlanTypeFree = "FREE"
+	PlanTypePaid = "PAID"
+
+	PlanStatusNotStarted = "NOT_STARTED"
+	PlanStatusActive     = "ACTIVE"
+	PlanStatusExpired    = "EXPIRED"
+)
+
+// AccountPlanState is the account's plan: the credit-based free plan that new
+// accounts start on, or the paid plan after an upgrade. Accounts created
+// before the free plan existed report PAID and use the classic free tier.
+type AccountPlanState struct {
+	AccountID        string    `json:"account_id"`
+	PlanType         string    `json:"plan_type"`
+	Status           string    `json:"status"`
+	RemainingCredits float64   `json:"remaining_credits"`
+	Currency         string    `json:"currency"`
+	ExpirationDate   time.Time `json:"expiration_date,omitempty"`
+}
+
+// IsFreePlan reports whether the account is still on the credit-based free plan.
+func (s *AccountPlanState) IsFreePlan() bool {
+	return s.PlanType == PlanTypeFree
+}
+
+// DaysRemaining returns the whole days until the plan expires, or -1 when
+// there is no expiration date.
+func (s *AccountPlanState) DaysRemaining(now time.Time) int {
+	if s.ExpirationDate.IsZero() {
+		return -1
+	}
+	days := math.Ceil(s.ExpirationDate.Sub(now).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return int(days)
+}
+
+// GetAccountPlanState queries the Free Tier API for the account plan.
+func (c *CostClient) GetAccountPlanState(ctx context.Context) (*AccountPlanState, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("AWS credentials not configured. Run 'aws configure' or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	body, err := c.callAPI(ctx, "freetier", "AWSFreeTierService.GetAccountPlanState", `{}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account plan state: %w", err)
+	}
+
+	var resp struct {
+		AccountID                   string `json:"accountId"`
+		AccountPlanType             string `json:"accountPlanType"`
+		AccountPlanStatus           string `json:"accountPlanStatus"`
+		AccountPlanRemainingCredits *struct {
+			Amount float64 `json:"amount"`
+			Unit   string  `json:"unit"`
+		} `json:"accountPlanRemainingCredits"`
+		AccountPlanExpirationDate json.RawMessage `json:"accountPlanExpirationDate"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse account plan response: %w", err)
+	}
+
+	state := &AccountPlanState{
+		AccountID: resp.AccountID,
+		PlanType:  resp.AccountPlanType,
+		Status:    resp.AccountPlanStatus,
+		Currency:  "USD",
+	}
+	if cr := resp.AccountPlanRemainingCredits; cr != nil {
+		state.RemainingCredits = cr.Amount
+		if cr.Unit != "" {
+			state.Currency = cr.Unit
+		}
+	}
+	if state.ExpirationDate, err = parseTimestamp(resp.AccountPlanExpirationDate); err != nil {
+		return nil, fmt.Errorf("failed to parse plan expiration date: %w", err)
+	}
+
+	return state, nil
+}
+
+// parseTimestamp accepts the JSON protocol's epoch seconds as well as an
+// ISO 8601 string. A missing or null value is the zero time.
+func parseTimestamp(raw json.RawMessage) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, nil
+	}
+
+	var epoch float64
+	if err := json.Unmarshal(raw, &epoch); err == nil {
+		sec, frac := math.Modf(epoch)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,240 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profile holds the settings for one named profile, merged from the shared
+// config file (~/.aws/config) and the shared credentials file (~/.aws/credentials).
+type Profile struct {
+	Name         string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// Settings used to derive credentials instead of static keys.
+	RoleARN              string
+	SourceProfile        string
+	CredentialSource     string
+	ExternalID           string
+	MFASerial            string
+	RoleSessionName      string
+	DurationSeconds      string
+	WebIdentityTokenFile string
+	CredentialProcess    string
+	SSOSession           string
+	SSOStartURL          string
+	SSORegion            string
+	SSOAccountID         string
+	SSORoleName          string
+
+	// Endpoint settings, see NewEndpointResolver.
+	EndpointURL          string
+	UseFIPSEndpoint      string
+	UseDualStackEndpoint string
+}
+
+// SharedConfig is the parsed content of the shared config and credentials files.
+type SharedConfig struct {
+	Profiles    map[string]*Profile
+	SSOSessions map[string]map[string]string
+}
+
+// DefaultProfileName returns the profile selected by AWS_PROFILE, or "default".
+func DefaultProfileName() string {
+	if p := os.Getenv("AWS_PROFILE"); p != "" {
+		return p
+	}
+	if p := os.Getenv("AWS_DEFAULT_PROFILE"); p != "" {
+		return p
+	}
+	return "default"
+}
+
+// ConfigFilePath returns the shared config file location, honoring AWS_CONFIG_FILE.
+func ConfigFilePath() string {
+	if p := os.Getenv("AWS_CONFIG_FILE"); p != "" {
+		return p
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", "config")
+}
+
+// CredentialsFilePath returns the shared credentials file location, honoring AWS_SHARED_CREDENTIALS_FILE.
+func CredentialsFilePath() string {
+	if p := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); p != "" {
+		return p
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", "credentials")
+}
+
+// LoadSharedConfig reads both shared files. Missing files are not an error.
+// Values from the credentials file take precedence over the config file.
+func LoadSharedConfig() (*SharedConfig, error) {
+	sc := &SharedConfig{
+		Profiles:    make(map[string]*Profile),
+		SSOSessions: make(map[string]map[string]string),
+	}
+
+	configSections, err := parseINIFile(ConfigFilePath())
+	if err != nil {
+		return nil, err
+	}
+	// With both [default] and [profile default], the latter wins, as in the AWS CLI.
+	for _, section := range []string{"default", "profile default"} {
+		if values, ok := configSections[section]; ok {
+			sc.merge("default", values)
+		}
+	}
+	for section, values := range configSections {
+		switch {
+		case section == "default" || section == "profile default":
+		case strings.HasPrefix(section, "profile "):
+			sc.merge(strings.TrimSpace(strings.TrimPrefix(section, "profile ")), values)
+		case strings.HasPrefix(section, "sso-session "):
+			sc.SSOSessions[strings.TrimSpace(strings.TrimPrefix(section, "sso-session "))] = values
+		}
+	}
+
+	credSections, err := parseINIFile(CredentialsFilePath())
+	if err != nil {
+		return nil, err
+	}
+	for section, values := range credSections {
+		sc.merge(section, values)
+	}
+
+	return sc, nil
+}
+
+// Profile returns the named profile, or an error if neither file defines it.
+func (sc *SharedConfig) Profile(name string) (*Profile, error) {
+	p, ok := sc.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("AWS profile %q not found in %s or %s", name, ConfigFilePath(), CredentialsFilePath())
+	}
+	return p, nil
+}
+
+func (sc *SharedConfig) merge(name string, values map[string]string) {
+	p, ok := sc.Profiles[name]
+	if !ok {
+		p = &Profile{Name: name}
+		sc.Profiles[name] = p
+	}
+
+	fields := map[string]*string{
+		"region":                  &p.Region,
+		"aws_access_key_id":       &p.AccessKey,
+		"aws_secret_access_key":   &p.SecretKey,
+		"aws_session_token":       &p.SessionToken,
+		"role_arn":                &p.RoleARN,
+		"source_profile":          &p.SourceProfile,
+		"credential_source":       &p.CredentialSource,
+		"external_id":             &p.ExternalID,
+		"mfa_serial":              &p.MFASerial,
+		"role_session_name":       &p.RoleSessionName,
+		"duration_seconds":        &p.DurationSeconds,
+		"web_identity_token_file": &p.WebIdentityTokenFile,
+		"credential_process":      &p.CredentialProcess,
+		"sso_session":             &p.SSOSession,
+		"sso_start_url":           &p.SSOStartURL,
+		"sso_region":              &p.SSORegion,
+		"sso_account_id":          &p.SSOAccountID,
+		"sso_role_name":           &p.SSORoleName,
+		"endpoint_url":            &p.EndpointURL,
+		"use_fips_endpoint":       &p.UseFIPSEndpoint,
+		"use_dualstack_endpoint":  &p.UseDualStackEndpoint,
+	}
+	for key, value := range values {
+		if field, ok := fields[key]; ok {
+			*field = value
+		}
+	}
+}
+
+// parseINIFile parses the AWS flavor of INI: [section] headers, key = value
+// pairs, # and ; comments, and indented continuation lines for nested values
+// such as s3 settings, which are kept but not interpreted. Whitespace inside
+// section names is collapsed, and a section that appears twice is merged, the
+// later values winning. Malformed lines are reported with their line number.
+func parseINIFile(path string) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sections, nil
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var (
+		current string
+		lastKey string
+	)
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: malformed section header", path, lineNo)
+			}
+			current = strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			if current == "" {
+				return nil, fmt.Errorf("%s:%d: empty section name", path, lineNo)
+			}
+			if _, ok := sections[current]; !ok {
+				sections[current] = make(map[string]string)
+			}
+			lastKey = ""
+			continue
+		}
+
+		if current == "" {
+			return nil, fmt.Errorf("%s:%d: setting outside of a [section]", path, lineNo)
+		}
+
+		if raw[0] == ' ' || raw[0] == '\t' {
+			if lastKey == "" {
+				return nil, fmt.Errorf("%s:%d: indented line does not continue a setting", path, lineNo)
+			}
+			sections[current][lastKey] += "\n" + line
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNo)
+		}
+		lastKey = strings.ToLower(strings.TrimSpace(key))
+		sections[current][lastKey] = stripInlineComment(strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return sections, nil
+}
+
+// stripInlineComment drops a trailing " #" or " ;" comment.
+func stripInlineComment(value string) string {
+	for _, marker := range []string{" #", " ;", "\t#", "\t;"} {
+		if i := strings.Index(value, marker); i >= 0 {
+			value = value[:i]
+		}
+	}
+	return strings.TrimSpace(value)
+}
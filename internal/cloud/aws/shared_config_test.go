@@ -0,0 +1,141 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSharedFiles points the shared config and credentials files at
+// temporary copies of config and credentials.
+func writeSharedFiles(t *testing.T, config, credentials string) {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	credentialsPath := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsPath, []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+}
+
+func TestLoadSharedConfig(t *testing.T) {
+	writeSharedFiles(t, `
+# comment
+[default]
+region = us-west-2
+
+[profile  dev]
+region = eu-west-1 # inline comment
+role_arn = arn:aws:iam::123456789012:role/dev
+source_profile = default
+s3 =
+  max_concurrent_requests = 20
+  addressing_style = path
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = us-east-1
+
+[not-a-profile]
+region = ap-south-1
+`, `
+[default]
+aws_access_key_id = AKIDEXAMPLE
+aws_secret_access_key = secret ; comment
+
+[dev]
+region = eu-central-1
+`)
+
+	sc, err := LoadSharedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := sc.Profile("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Region != "us-west-2" || def.AccessKey != "AKIDEXAMPLE" || def.SecretKey != "secret" {
+		t.Errorf("default = %+v", def)
+	}
+
+	dev, err := sc.Profile("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The credentials file wins over the config file.
+	if dev.Region != "eu-central-1" || dev.RoleARN != "arn:aws:iam::123456789012:role/dev" || dev.SourceProfile != "default" {
+		t.Errorf("dev = %+v", dev)
+	}
+
+	if got := sc.SSOSessions["corp"]["sso_start_url"]; got != "https://corp.awsapps.com/start" {
+		t.Errorf("sso-session corp start url = %q", got)
+	}
+	if _, err := sc.Profile("not-a-profile"); err == nil {
+		t.Error("sections without the profile prefix are not profiles in the config file")
+	}
+}
+
+func TestLoadSharedConfigProfileDefaultWins(t *testing.T) {
+	writeSharedFiles(t, `
+[profile default]
+region = eu-west-1
+
+[default]
+region = us-west-2
+output = json
+`, "")
+
+	// Map order used to decide the winner; repeat to catch that.
+	for i := 0; i < 20; i++ {
+		sc, err := LoadSharedConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, _ := sc.Profile("default"); p == nil || p.Region != "eu-west-1" {
+			t.Fatalf("default region = %+v, want eu-west-1 from [profile default]", p)
+		}
+	}
+}
+
+func TestParseINIFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unterminated header", "[default\nregion = us-east-1\n", ":1: malformed section header"},
+		{"empty header", "[ ]\n", ":1: empty section name"},
+		{"missing equals", "[default]\nregion us-east-1\n", ":2: expected key = value"},
+		{"missing key", "[default]\n= us-east-1\n", ":2: expected key = value"},
+		{"orphan continuation", "[default]\n  max_concurrent_requests = 20\n", ":2: indented line does not continue a setting"},
+		{"setting before section", "region = us-east-1\n[default]\n", ":1: setting outside of a [section]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := parseINIFile(path)
+			if err == nil || !strings.Contains(err.Error(), path+tt.want) {
+				t.Errorf("parseINIFile() error = %v, want %q", err, path+tt.want)
+			}
+		})
+	}
+}
+
+func TestParseINIFileMissing(t *testing.T) {
+	sections, err := parseINIFile(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(sections) != 0 {
+		t.Errorf("parseINIFile(missing) = %v, %v", sections, err)
+	}
+}
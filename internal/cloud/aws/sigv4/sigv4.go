@@ -0,0 +1,391 @@
+// Package sigv4 implements AWS Signature Version 4 request signing for any
+// method, path, query string and header set, including unsigned-payload and
+// presigned-URL modes.
+//
+// Canonicalization follows the AWS documentation and the published SigV4
+// test suite: paths are normalized and URI-encoded twice for every service
+// except S3, query parameters are sorted by encoded key then value, and
+// header values are trimmed with inner runs of whitespace collapsed.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Algorithm = "AWS4-HMAC-SHA256"
+
+	// UnsignedPayload replaces the payload hash when the body is not signed.
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	timeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+)
+
+// EmptyPayloadHash is the SHA-256 of an empty body.
+var EmptyPayloadHash = HashPayload(nil)
+
+// Headers that proxies and the HTTP stack may rewrite, so they are never signed.
+var ignoredHeaders = map[string]bool{
+	"authorization":     true,
+	"user-agent":        true,
+	"x-amzn-trace-id":   true,
+	"expect":            true,
+	"connection":        true,
+	"transfer-encoding": true,
+}
+
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// Signer signs requests for one service in one region.
+type Signer struct {
+	Credentials Credentials
+	Region      string
+	Service     string
+
+	// UnsignedPayload signs the literal UNSIGNED-PAYLOAD instead of the body hash.
+	UnsignedPayload bool
+
+	// DisablePathEscaping and DisablePathNormalization are set automatically
+	// for S3, which signs the path exactly as sent.
+	DisablePathEscaping      bool
+	DisablePathNormalization bool
+
+	// Now returns the signing time; it defaults to time.Now.
+	Now func() time.Time
+}
+
+func NewSigner(creds Credentials, region, service string) *Signer {
+	s := &Signer{Credentials: creds, Region: region, Service: service}
+	if service == "s3" {
+		s.DisablePathEscaping = true
+		s.DisablePathNormalization = true
+	}
+	return s
+}
+
+// Sign adds X-Amz-Date, X-Amz-Security-Token and Authorization headers to req.
+// payload must be the exact request body; it is ignored for unsigned payloads.
+// Every header already on the request is signed, except hop-by-hop headers.
+func (s *Signer) Sign(req *http.Request, payload []byte) error {
+	if s.Credentials.AccessKey == "" || s.Credentials.SecretKey == "" {
+		return fmt.Errorf("sigv4: missing credentials")
+	}
+
+	now := s.now()
+	req.Header.Set("X-Amz-Date", now.Format(timeFormat))
+	if s.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+
+	payloadHash := s.payloadHash(payload)
+	if s.UnsignedPayload || s.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	creq, signedHeaders := s.canonicalRequest(req, req.URL, payloadHash)
+
+	scope := s.scope(now)
+	signature := s.signature(now, StringToSign(now, scope, creq))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		Algorithm, s.Credentials.AccessKey, scope, signedHeaders, signature))
+	return nil
+}
+
+// Presign returns a URL that carries the signature in its query string and
+// stays valid for expires. Headers already on req are signed and must be
+// sent with the URL.
+func (s *Signer) Presign(req *http.Request, expires time.Duration) (string, error) {
+	if s.Credentials.AccessKey == "" || s.Credentials.SecretKey == "" {
+		return "", fmt.Errorf("sigv4: missing credentials")
+	}
+	if expires <= 0 || expires > 7*24*time.Hour {
+		return "", fmt.Errorf("sigv4: presign expiry must be between 1s and 7 days")
+	}
+
+	now := s.now()
+	scope := s.scope(now)
+	_, signedHeaders := s.canonicalHeaders(req)
+
+	u := *req.URL
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", Algorithm)
+	query.Set("X-Amz-Credential", s.Credentials.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(timeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if s.Credentials.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+	u.RawQuery = encodeQuery(query)
+
+	payloadHash := UnsignedPayload
+	if !s.UnsignedPayload && s.Service != "s3" {
+		payloadHash = EmptyPayloadHash
+	}
+
+	creq, _ := s.canonicalRequest(req, &u, payloadHash)
+	signature := s.signature(now, StringToSign(now, scope, creq))
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+func (s *Signer) now() time.Time {
+	if s.Now != nil {
+		return s.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (s *Signer) payloadHash(payload []byte) string {
+	if s.UnsignedPayload {
+		return UnsignedPayload
+	}
+	return HashPayload(payload)
+}
+
+func (s *Signer) scope(t time.Time) string {
+	return strings.Join([]string{t.Format(dateFormat), s.Region, s.Service, "aws4_request"}, "/")
+}
+
+func (s *Signer) signature(t time.Time, stringToSign string) string {
+	key := DeriveKey(s.Credentials.SecretKey, t.Format(dateFormat), s.Region, s.Service)
+	return hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+}
+
+// canonicalRequest returns the canonical request for req sent to u, and the
+// signed header list.
+func (s *Signer) canonicalRequest(req *http.Request, u *url.URL, payloadHash string) (string, string) {
+	canonicalHeaders, signedHeaders := s.canonicalHeaders(req)
+	creq := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(u),
+		CanonicalQuery(u.RawQuery),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	return creq, signedHeaders
+}
+
+func (s *Signer) canonicalURI(u *url.URL) string {
+	p := u.EscapedPath()
+	if u.Opaque != "" {
+		// Opaque URLs ("//host/path") carry the path verbatim after the host.
+		parts := strings.SplitN(strings.TrimPrefix(u.Opaque, "//"), "/", 2)
+		p = "/"
+		if len(parts) == 2 {
+			p += parts[1]
+		}
+	}
+	return CanonicalURI(p, !s.DisablePathNormalization, !s.DisablePathEscaping)
+}
+
+// canonicalHeaders returns the canonical header block and the signed header list.
+func (s *Signer) canonicalHeaders(req *http.Request) (string, string) {
+	values := make(map[string][]string)
+	for name, vals := range req.Header {
+		lower := strings.ToLower(name)
+		if ignoredHeaders[lower] || lower == "host" {
+			continue
+		}
+		values[lower] = append(values[lower], vals...)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values["host"] = []string{stripDefaultPort(host, req.URL.Scheme)}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		trimmed := make([]string, len(values[name]))
+		for i, v := range values[name] {
+			trimmed[i] = trimHeaderValue(v)
+		}
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(trimmed, ","))
+		b.WriteByte('\n')
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// CanonicalURI encodes an already-escaped request path. With normalize, dot
+// segments and duplicate slashes are removed; with escape, every segment is
+// URI-encoded again, which is the double encoding all services but S3 expect.
+func CanonicalURI(escapedPath string, normalize, escape bool) string {
+	p := escapedPath
+	if p == "" {
+		p = "/"
+	}
+
+	if normalize {
+		trailing := strings.HasSuffix(p, "/")
+		p = path.Clean("/" + p)
+		if trailing && p != "/" {
+			p += "/"
+		}
+	}
+
+	if !escape {
+		return p
+	}
+
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		segments[i] = URIEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// CanonicalQuery sorts and re-encodes a raw query string. Parameters without
+// a value are kept with an empty value.
+func CanonicalQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	type pair struct{ key, value string }
+	var pairs []pair
+	for _, part := range strings.Split(rawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		pairs = append(pairs, pair{URIEncode(unescapeQuery(k)), URIEncode(unescapeQuery(v))})
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+	return strings.Join(encoded, "&")
+}
+
+// URIEncode percent-encodes everything except the RFC 3986 unreserved characters.
+func URIEncode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0f])
+	}
+	return b.String()
+}
+
+// StringToSign builds the string to sign for a canonical request.
+func StringToSign(t time.Time, scope, canonicalRequest string) string {
+	return strings.Join([]string{
+		Algorithm,
+		t.UTC().Format(timeFormat),
+		scope,
+		HashPayload([]byte(canonicalRequest)),
+	}, "\n")
+}
+
+// DeriveKey computes the signing key for a date, region and service.
+func DeriveKey(secret, date, region, service string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	kRegion := hmacSHA256(kDate, []byte(region))
+	kService := hmacSHA256(kRegion, []byte(service))
+	return hmacSHA256(kService, []byte("aws4_request"))
+}
+
+// HashPayload returns the hex SHA-256 of a request body.
+func HashPayload(payload []byte) string {
+	h := sha256.Sum256(payload)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func isUnreserved(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+// unescapeQuery decodes percent escapes and treats '+' as a space, matching
+// how AWS parses form-style query strings.
+func unescapeQuery(s string) string {
+	if v, err := url.QueryUnescape(s); err == nil {
+		return v
+	}
+	return s
+}
+
+// encodeQuery encodes values with URIEncode so spaces become %20, not '+'.
+func encodeQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range values[k] {
+			parts = append(parts, URIEncode(k)+"="+URIEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// trimHeaderValue trims a value and collapses runs of whitespace, including
+// folded line breaks, into single spaces.
+func trimHeaderValue(v string) string {
+	return strings.Join(strings.Fields(v), " ")
+}
+
+// stripDefaultPort drops :443 from https hosts and :80 from http hosts. IPv6
+// literals keep their brackets, as the Host header carries them.
+func stripDefaultPort(host, scheme string) string {
+	_, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		return host[:len(host)-len(port)-1]
+	}
+	return host
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SSOProvider exchanges a cached IAM Identity Center access token, written
+// by 'aws sso login', for short-lived role credentials.
+type SSOProvider struct {
+	StartURL  string
+	Region    string
+	AccountID string
+	RoleName  string
+	// CacheKey is the sso-session name, or the start URL for legacy profiles.
+	CacheKey string
+	HTTP     *http.Client
+}
+
+func (p *SSOProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	token, err := p.cachedToken()
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	ep, err := envEndpoints().Resolve("sso", p.Region)
+	if err != nil {
+		return Credentials{}, err
+	}
+	endpoint := fmt.Sprintf("%s/federation/credentials?%s", ep.URL, url.Values{
+		"account_id": {p.AccountID},
+		"role_name":  {p.RoleName},
+	}.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("x-amz-sso_bearer_token", token)
+
+	httpClient := p.HTTP
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("SSO GetRoleCredentials failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		RoleCredentials struct {
+			AccessKeyID     string `json:"accessKeyId"`
+			SecretAccessKey string `json:"secretAccessKey"`
+			SessionToken    string `json:"sessionToken"`
+			Expiration      int64  `json:"expiration"`
+		} `json:"roleCredentials"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse SSO credentials: %w", err)
+	}
+
+	rc := result.RoleCredentials
+	return Credentials{
+		AccessKey:    rc.AccessKeyID,
+		SecretKey:    rc.SecretAccessKey,
+		SessionToken: rc.SessionToken,
+		Expires:      time.UnixMilli(rc.Expiration),
+	}, nil
+}
+
+// cachedToken reads ~/.aws/sso/cache/<sha1(cache key)>.json.
+func (p *SSOProvider) cachedToken() (string, error) {
+	home, _ := os.UserHomeDir()
+	sum := sha1.Sum([]byte(p.CacheKey))
+	path := filepath.Join(home, ".aws", "sso", "cache", hex.EncodeToString(sum[:])+".json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("no cached SSO token for %s; run 'aws sso login': %w", p.CacheKey, err)
+	}
+
+	var cached struct {
+		AccessToken string `json:"accessToken"`
+		ExpiresAt   string `json:"expiresAt"`
+	}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return "", fmt.Errorf("failed to parse SSO token cache %s: %w", path, err)
+	}
+
+	// Older CLI versions write "2006-01-02T15:04:05UTC" instead of RFC 3339.
+	expiresAt, err := time.Parse(time.RFC3339, cached.ExpiresAt)
+	if err != nil {
+		expiresAt, err = time.Parse("2006-01-02T15:04:05UTC", cached.ExpiresAt)
+	}
+	if err != nil || cached.AccessToken == "" || time.Now().After(expiresAt) {
+		return "", fmt.Errorf("SSO session for %s has expired; run 'aws sso login'", p.CacheKey)
+	}
+	return cached.AccessToken, nil
+}
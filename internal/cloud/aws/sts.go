@@ -0,0 +1,329 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const stsAPIVersion = "2011-06-15"
+
+// AssumeRoleProvider calls STS AssumeRole with credentials from Source and
+// keeps the role credentials until they are about to expire.
+// When MFASerial is set the user is prompted for a token code, and the
+// credentials are also cached in CacheDir so later runs reuse them instead
+// of prompting again.
+type AssumeRoleProvider struct {
+	Source      CredentialsProvider
+	RoleARN     string
+	ExternalID  string
+	SessionName string
+	MFASerial   string
+	Duration    time.Duration
+	Region      string
+	HTTP        *http.Client
+	// CacheDir holds MFA-backed credentials between runs; empty disables it.
+	CacheDir string
+
+	// TokenCode supplies the MFA code; it defaults to PromptMFAToken.
+	TokenCode func(serial string) (string, error)
+
+	mu    sync.Mutex
+	creds Credentials
+}
+
+// RoleCacheDir is where MFA-backed role credentials are cached.
+func RoleCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".azguard", "cache", "aws")
+}
+
+func (p *AssumeRoleProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.creds.NeedsRefresh() {
+		return p.creds, nil
+	}
+
+	if p.Source == nil {
+		return Credentials{}, fmt.Errorf("no source credentials for %s", p.RoleARN)
+	}
+	source, err := p.Source.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get source credentials for %s: %w", p.RoleARN, err)
+	}
+
+	cachePath := p.cachePath(source)
+	if cachePath != "" {
+		if creds, err := readCachedCredentials(cachePath); err == nil && !creds.NeedsRefresh() {
+			p.creds = creds
+			return creds, nil
+		}
+	}
+
+	creds, err := p.assumeRole(ctx, source)
+	if err != nil {
+		return Credentials{}, err
+	}
+	p.creds = creds
+	if cachePath != "" {
+		// A failed write only means the next run prompts again.
+		_ = writeCachedCredentials(cachePath, creds)
+	}
+	return creds, nil
+}
+
+func (p *AssumeRoleProvider) assumeRole(ctx context.Context, source Credentials) (Credentials, error) {
+
+	params := url.Values{}
+	params.Set("Action", "AssumeRole")
+	params.Set("Version", stsAPIVersion)
+	params.Set("RoleArn", p.RoleARN)
+	params.Set("RoleSessionName", sessionName(p.SessionName))
+	if p.Duration > 0 {
+		params.Set("DurationSeconds", strconv.Itoa(int(p.Duration.Seconds())))
+	}
+	if p.ExternalID != "" {
+		params.Set("ExternalId", p.ExternalID)
+	}
+	if p.MFASerial != "" {
+		tokenCode := p.TokenCode
+		if tokenCode == nil {
+			tokenCode = PromptMFAToken
+		}
+		code, err := tokenCode(p.MFASerial)
+		if err != nil {
+			return Credentials{}, err
+		}
+		params.Set("SerialNumber", p.MFASerial)
+		params.Set("TokenCode", code)
+	}
+
+	return stsCall(ctx, p.HTTP, p.Region, params, &source)
+}
+
+// cachePath names the cache file for the role, MFA device and source keys,
+// or returns "" when nothing should be cached.
+func (p *AssumeRoleProvider) cachePath(source Credentials) string {
+	if p.MFASerial == "" || p.CacheDir == "" {
+		return ""
+	}
+	sum := sha1.Sum([]byte(strings.Join([]string{p.RoleARN, p.MFASerial, p.ExternalID, source.AccessKey}, "\n")))
+	return filepath.Join(p.CacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+type cachedCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	SessionToken    string    `json:"SessionToken"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func readCachedCredentials(path string) (Credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var c cachedCredentials
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{
+		AccessKey:    c.AccessKeyID,
+		SecretKey:    c.SecretAccessKey,
+		SessionToken: c.SessionToken,
+		Expires:      c.Expiration,
+	}, nil
+}
+
+// writeCachedCredentials saves credentials readable by the user only. Only
+// temporary credentials are cached.
+func writeCachedCredentials(path string, creds Credentials) error {
+	if creds.Expires.IsZero() {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cachedCredentials{
+		AccessKeyID:     creds.AccessKey,
+		SecretAccessKey: creds.SecretKey,
+		SessionToken:    creds.SessionToken,
+		Expiration:      creds.Expires,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// WebIdentityProvider exchanges an OIDC token file, as written by CI systems
+// and EKS, for role credentials with AssumeRoleWithWebIdentity.
+type WebIdentityProvider struct {
+	RoleARN     string
+	TokenFile   string
+	SessionName string
+	Region      string
+	HTTP        *http.Client
+}
+
+func (p *WebIdentityProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	// Re-read on every refresh: CI runners rotate the token file.
+	token, err := os.ReadFile(p.TokenFile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("Action", "AssumeRoleWithWebIdentity")
+	params.Set("Version", stsAPIVersion)
+	params.Set("RoleArn", p.RoleARN)
+	params.Set("RoleSessionName", sessionName(p.SessionName))
+	params.Set("WebIdentityToken", strings.TrimSpace(string(token)))
+
+	return stsCall(ctx, p.HTTP, p.Region, params, nil)
+}
+
+type stsResponse struct {
+	Result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"Credentials"`
+	} `xml:",any"`
+}
+
+// stsCall posts a query-protocol STS request that returns credentials.
+// Credential providers have no client, so endpoint overrides come from the
+// environment only.
+func stsCall(ctx context.Context, httpClient *http.Client, region string, params url.Values, creds *Credentials) (Credentials, error) {
+	ep, err := envEndpoints().Resolve("sts", region)
+	if err != nil {
+		return Credentials{}, err
+	}
+	body, err := stsDo(ctx, httpClient, ep, params, creds)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	var result stsResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse STS response: %w", err)
+	}
+
+	c := result.Result.Credentials
+	if c.AccessKeyID == "" {
+		return Credentials{}, fmt.Errorf("STS %s returned no credentials", params.Get("Action"))
+	}
+	return Credentials{
+		AccessKey:    c.AccessKeyID,
+		SecretKey:    c.SecretAccessKey,
+		SessionToken: c.SessionToken,
+		Expires:      c.Expiration,
+	}, nil
+}
+
+// stsDo sends an STS request and returns the raw XML body. It is signed when
+// creds is non-nil; AssumeRoleWithWebIdentity is the one action that must be
+// sent unsigned.
+func stsDo(ctx context.Context, httpClient *http.Client, ep Endpoint, params url.Values, creds *Credentials) ([]byte, error) {
+	payload := []byte(params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ep.URL+"/", strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if creds != nil {
+		if err := signV4(req, payload, *creds, ep.SigningRegion, ep.SigningName); err != nil {
+			return nil, err
+		}
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("STS %s failed (status %d): %s", params.Get("Action"), resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// GetCallerIdentity returns the account ID the client's credentials belong to.
+// The result is cached for the life of the client.
+func (c *CostClient) GetCallerIdentity(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	cached := c.accountID
+	c.mu.Unlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("Action", "GetCallerIdentity")
+	params.Set("Version", stsAPIVersion)
+
+	ep, err := c.endpoint("sts", c.homeRegion())
+	if err != nil {
+		return "", err
+	}
+	body, err := stsDo(ctx, c.HTTP, ep, params, &creds)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Account string `xml:"GetCallerIdentityResult>Account"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse STS response: %w", err)
+	}
+	if result.Account == "" {
+		return "", fmt.Errorf("STS GetCallerIdentity returned no account")
+	}
+
+	c.mu.Lock()
+	c.accountID = result.Account
+	c.mu.Unlock()
+	return result.Account, nil
+}
+
+func sessionName(name string) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("azguard-%d", time.Now().Unix())
+}
@@ -0,0 +1,157 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	AdvisorAPI   = "2023-01-01"
+	ResourcesAPI = "2021-04-01"
+)
+
+// Recommendation is an Azure Advisor recommendation in the Cost category.
+type Recommendation struct {
+	ID             string
+	Impact         string
+	ResourceID     string
+	ResourceGroup  string
+	ResourceType   string
+	ResourceName   string
+	Problem        string
+	Solution       string
+	MonthlySavings float64
+	AnnualSavings  float64
+	Currency       string
+}
+
+// Resource is an entry from the subscription's ARM resource inventory.
+type Resource struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	Location      string            `json:"location"`
+	ResourceGroup string            `json:"-"`
+	Tags          map[string]string `json:"tags"`
+}
+
+type advisorListResponse struct {
+	Value []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			Category         string `json:"category"`
+			Impact           string `json:"impact"`
+			ImpactedField    string `json:"impactedField"`
+			ImpactedValue    string `json:"impactedValue"`
+			ShortDescription struct {
+				Problem  string `json:"problem"`
+				Solution string `json:"solution"`
+			} `json:"shortDescription"`
+			ResourceMetadata struct {
+				ResourceID string `json:"resourceId"`
+			} `json:"resourceMetadata"`
+			ExtendedProperties map[string]string `json:"extendedProperties"`
+		} `json:"properties"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+type resourceListResponse struct {
+	Value    []Resource `json:"value"`
+	NextLink string     `json:"nextLink"`
+}
+
+// ListCostRecommendations returns Azure Advisor's Cost category recommendations for the subscription.
+func (c *CostClient) ListCostRecommendations(ctx context.Context) ([]Recommendation, error) {
+	if err := ValidateSubscriptionID(c.SubscriptionID); err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Advisor/recommendations?api-version=%s&$filter=%s",
+		AzureManagementURL, c.SubscriptionID, AdvisorAPI, url.QueryEscape("Category eq 'Cost'"))
+
+	var recs []Recommendation
+	for next != "" {
+		var page advisorListResponse
+		if err := c.doJSON(ctx, "GET", next, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list advisor recommendations: %w", err)
+		}
+
+		for _, item := range page.Value {
+			p := item.Properties
+			if !strings.EqualFold(p.Category, "Cost") {
+				continue
+			}
+
+			resourceID := p.ResourceMetadata.ResourceID
+			if resourceID == "" {
+				resourceID = strings.SplitN(item.ID, "/providers/Microsoft.Advisor/", 2)[0]
+			}
+
+			rec := Recommendation{
+				ID:            item.Name,
+				Impact:        p.Impact,
+				ResourceID:    resourceID,
+				ResourceGroup: ResourceGroupFromID(resourceID),
+				ResourceType:  p.ImpactedField,
+				ResourceName:  p.ImpactedValue,
+				Problem:       p.ShortDescription.Problem,
+				Solution:      p.ShortDescription.Solution,
+				Currency:      p.ExtendedProperties["savingsCurrency"],
+			}
+			rec.MonthlySavings, _ = strconv.ParseFloat(p.ExtendedProperties["savingsAmount"], 64)
+			rec.AnnualSavings, _ = strconv.ParseFloat(p.ExtendedProperties["annualSavingsAmount"], 64)
+			if rec.MonthlySavings == 0 && rec.AnnualSavings > 0 {
+				rec.MonthlySavings = rec.AnnualSavings / 12
+			}
+			if rec.Currency == "" {
+				rec.Currency = "USD"
+			}
+
+			recs = append(recs, rec)
+		}
+		next = page.NextLink
+	}
+
+	return recs, nil
+}
+
+// ListResources returns every resource in the subscription.
+func (c *CostClient) ListResources(ctx context.Context) ([]Resource, error) {
+	if err := ValidateSubscriptionID(c.SubscriptionID); err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	next := fmt.Sprintf("%s/subscriptions/%s/resources?api-version=%s",
+		AzureManagementURL, c.SubscriptionID, ResourcesAPI)
+
+	var resources []Resource
+	for next != "" {
+		var page resourceListResponse
+		if err := c.doJSON(ctx, "GET", next, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+		for _, r := range page.Value {
+			r.ResourceGroup = ResourceGroupFromID(r.ID)
+			resources = append(resources, r)
+		}
+		next = page.NextLink
+	}
+
+	return resources, nil
+}
+
+// ResourceGroupFromID extracts the resource group segment from an ARM resource ID.
+func ResourceGroupFromID(id string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -41,7 +41,7 @@ func GetCLIToken() (string, error) {
 }
 
 func GetSPToken(tenantID, clientID, clientSecret string) (string, error) {
-	url := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenantID)
+	url := fmt.Sprintf("%s/%s/oauth2/v2.0/token", AuthorityHost, tenantID)
 
 	data := fmt.Sprintf(
 		"grant_type=client_credentials&client_id=%s&client_secret=%s&scope=https://management.azure.com/.default",
@@ -0,0 +1,191 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const ConsumptionAPI = "2023-05-01"
+
+var budgetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,63}$`)
+
+// Budget is a native Microsoft.Consumption budget on a subscription or resource group.
+type Budget struct {
+	Name          string
+	ResourceGroup string
+	Amount        float64
+	TimeGrain     string
+	StartDate     string
+	EndDate       string
+	CurrentSpend  float64
+	Currency      string
+	ETag          string
+	Notifications map[string]BudgetNotification
+}
+
+// BudgetNotification is a single threshold notification on a budget.
+type BudgetNotification struct {
+	Enabled       bool     `json:"enabled"`
+	Operator      string   `json:"operator"`
+	Threshold     float64  `json:"threshold"`
+	ThresholdType string   `json:"thresholdType,omitempty"`
+	ContactEmails []string `json:"contactEmails,omitempty"`
+	ContactGroups []string `json:"contactGroups,omitempty"`
+	ContactRoles  []string `json:"contactRoles,omitempty"`
+}
+
+type budgetResource struct {
+	ID         string           `json:"id,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	ETag       string           `json:"eTag,omitempty"`
+	Properties budgetProperties `json:"properties"`
+}
+
+type budgetProperties struct {
+	Category      string                        `json:"category"`
+	Amount        float64                       `json:"amount"`
+	TimeGrain     string                        `json:"timeGrain"`
+	TimePeriod    budgetTimePeriod              `json:"timePeriod"`
+	CurrentSpend  *budgetSpend                  `json:"currentSpend,omitempty"`
+	Notifications map[string]BudgetNotification `json:"notifications,omitempty"`
+}
+
+type budgetTimePeriod struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate,omitempty"`
+}
+
+type budgetSpend struct {
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit"`
+}
+
+type budgetListResponse struct {
+	Value    []budgetResource `json:"value"`
+	NextLink string           `json:"nextLink"`
+}
+
+// BudgetScope returns the ARM scope for budgets, optionally narrowed to a resource group.
+func (c *CostClient) BudgetScope(resourceGroup string) string {
+	scope := "/subscriptions/" + c.SubscriptionID
+	if resourceGroup != "" {
+		scope += "/resourceGroups/" + resourceGroup
+	}
+	return scope
+}
+
+// ListBudgets returns all Consumption budgets defined at the given scope.
+func (c *CostClient) ListBudgets(ctx context.Context, resourceGroup string) ([]Budget, error) {
+	if err := ValidateSubscriptionID(c.SubscriptionID); err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	next := fmt.Sprintf("%s%s/providers/Microsoft.Consumption/budgets?api-version=%s",
+		AzureManagementURL, c.BudgetScope(resourceGroup), ConsumptionAPI)
+
+	var budgets []Budget
+	for next != "" {
+		var page budgetListResponse
+		if err := c.doJSON(ctx, "GET", next, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list budgets: %w", err)
+		}
+		for _, r := range page.Value {
+			budgets = append(budgets, budgetFromResource(r, resourceGroup))
+		}
+		next = page.NextLink
+	}
+
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Name < budgets[j].Name })
+	return budgets, nil
+}
+
+// PutBudget creates or updates a Consumption budget. An existing budget must
+// carry the ETag returned by ListBudgets so Azure can detect concurrent edits.
+func (c *CostClient) PutBudget(ctx context.Context, b Budget) (*Budget, error) {
+	if err := ValidateSubscriptionID(c.SubscriptionID); err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	if b.TimeGrain == "" {
+		b.TimeGrain = "Monthly"
+	}
+	if b.StartDate == "" {
+		now := time.Now().UTC()
+		b.StartDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+
+	req := budgetResource{
+		ETag: b.ETag,
+		Properties: budgetProperties{
+			Category:      "Cost",
+			Amount:        b.Amount,
+			TimeGrain:     b.TimeGrain,
+			TimePeriod:    budgetTimePeriod{StartDate: b.StartDate, EndDate: b.EndDate},
+			Notifications: b.Notifications,
+		},
+	}
+
+	endpoint := fmt.Sprintf("%s%s/providers/Microsoft.Consumption/budgets/%s?api-version=%s",
+		AzureManagementURL, c.BudgetScope(b.ResourceGroup), url.PathEscape(b.Name), ConsumptionAPI)
+
+	var resp budgetResource
+	if err := c.doJSON(ctx, "PUT", endpoint, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to save budget %s: %w", b.Name, err)
+	}
+
+	saved := budgetFromResource(resp, b.ResourceGroup)
+	return &saved, nil
+}
+
+// ValidateBudgetName checks a name against Azure's budget naming rules:
+// 1 to 63 letters, digits, underscores and hyphens.
+func ValidateBudgetName(name string) error {
+	if !budgetNamePattern.MatchString(name) {
+		return fmt.Errorf("'%s' is not a valid Azure budget name: use 1 to 63 letters, digits, underscores and hyphens", name)
+	}
+	return nil
+}
+
+// NewBudgetNotifications builds one "actual cost greater than N percent"
+// notification per threshold. Without emails or action groups the
+// subscription owners are notified instead.
+func NewBudgetNotifications(thresholds []float64, emails, actionGroups []string) map[string]BudgetNotification {
+	notifications := make(map[string]BudgetNotification, len(thresholds))
+	for _, t := range thresholds {
+		n := BudgetNotification{
+			Enabled:       true,
+			Operator:      "GreaterThan",
+			Threshold:     t,
+			ThresholdType: "Actual",
+			ContactEmails: emails,
+			ContactGroups: actionGroups,
+		}
+		if len(emails) == 0 && len(actionGroups) == 0 {
+			n.ContactRoles = []string{"Owner"}
+		}
+		notifications[fmt.Sprintf("Actual_GreaterThan_%.0f_Percent", t)] = n
+	}
+	return notifications
+}
+
+func budgetFromResource(r budgetResource, resourceGroup string) Budget {
+	b := Budget{
+		Name:          r.Name,
+		ResourceGroup: resourceGroup,
+		Amount:        r.Properties.Amount,
+		TimeGrain:     r.Properties.TimeGrain,
+		StartDate:     r.Properties.TimePeriod.StartDate,
+		EndDate:       r.Properties.TimePeriod.EndDate,
+		ETag:          r.ETag,
+		Notifications: r.Properties.Notifications,
+	}
+	if r.Properties.CurrentSpend != nil {
+		b.CurrentSpend = r.Properties.CurrentSpend.Amount
+		b.Currency = r.Properties.CurrentSpend.Unit
+	}
+	return b
+}
@@ -0,0 +1,29 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBudgetName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"budget-10", true},
+		{"team_budget", true},
+		{strings.Repeat("a", 63), true},
+		{"", false},
+		{strings.Repeat("a", 64), false},
+		{"my budget", false},
+		{"budget/5", false},
+		{"budget.5", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateBudgetName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateBudgetName(%q) error = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
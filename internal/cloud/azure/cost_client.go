@@ -7,13 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
-const (
-	AzureManagementURL = "https://management.azure.com"
-	CostManagementAPI  = "2023-03-01"
-)
+const CostManagementAPI = "2023-03-01"
 
 type CostClient struct {
 	SubscriptionID string
@@ -42,6 +41,9 @@ type CostQueryRequest struct {
 	Timeframe  string   `json:"timeframe"`
 	TimePeriod *TimePeriod `json:"timePeriod,omitempty"`
 	Dataset    Dataset  `json:"dataset"`
+	// IncludeActualCost only applies to Forecast queries, which otherwise
+	// return the actual cost of past days alongside the forecast.
+	IncludeActualCost *bool `json:"includeActualCost,omitempty"`
 }
 
 type TimePeriod struct {
@@ -66,7 +68,20 @@ type Grouping struct {
 }
 
 type CostQueryResponse struct {
-	Value []CostItem `json:"value"`
+	Value      []CostItem       `json:"value"`
+	Properties *QueryProperties `json:"properties,omitempty"`
+}
+
+// QueryProperties is the tabular shape the Cost Management query API uses:
+// a column list plus rows of values in column order.
+type QueryProperties struct {
+	Columns []QueryColumn   `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+type QueryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 type CostItem struct {
@@ -98,9 +113,13 @@ type CostQueryResult struct {
 type CostRecord struct {
 	ServiceName   string
 	ResourceGroup string
+	TagKey        string
+	TagValue      string
 	Cost          float64
 	Currency      string
 	Date          string
+	// CostStatus is "Actual" or "Forecast" on forecast query rows.
+	CostStatus string
 }
 
 func (c *CostClient) QueryCosts(ctx context.Context, req CostQueryRequest) (*CostQueryResult, error) {
@@ -148,6 +167,49 @@ func (c *CostClient) QueryCosts(ctx context.Context, req CostQueryRequest) (*Cos
 	return c.parseResponse(result), nil
 }
 
+// doJSON sends an authenticated ARM request and decodes the JSON response into out.
+func (c *CostClient) doJSON(ctx context.Context, method, url string, in, out interface{}) error {
+	token, err := c.getToken()
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+
+	var reqBody io.Reader
+	if in != nil {
+		body, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s failed with status %d: %s", method, url, resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
 func (c *CostClient) parseResponse(resp CostQueryResponse) *CostQueryResult {
 	var records []CostRecord
 	var totalCost float64
@@ -173,6 +235,17 @@ func (c *CostClient) parseResponse(resp CostQueryResponse) *CostQueryResult {
 		records = append(records, record)
 	}
 
+	if resp.Properties != nil {
+		for _, row := range resp.Properties.Rows {
+			record := parseRow(resp.Properties.Columns, row)
+			totalCost += record.Cost
+			if record.Currency != "" {
+				currency = record.Currency
+			}
+			records = append(records, record)
+		}
+	}
+
 	return &CostQueryResult{
 		Records:    records,
 		TotalCost: totalCost,
@@ -180,6 +253,48 @@ func (c *CostClient) parseResponse(resp CostQueryResponse) *CostQueryResult {
 	}
 }
 
+func parseRow(columns []QueryColumn, row []interface{}) CostRecord {
+	var record CostRecord
+	for i, col := range columns {
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		switch strings.ToLower(col.Name) {
+		case "cost", "pretaxcost", "costusd", "totalcost":
+			if v, ok := row[i].(float64); ok {
+				record.Cost = v
+			}
+		case "currency":
+			record.Currency = fmt.Sprint(row[i])
+		case "usagedate":
+			record.Date = formatUsageDate(row[i])
+		case "servicename":
+			record.ServiceName = fmt.Sprint(row[i])
+		case "resourcegroup", "resourcegroupname":
+			record.ResourceGroup = fmt.Sprint(row[i])
+		case "tagkey":
+			record.TagKey = fmt.Sprint(row[i])
+		case "tagvalue":
+			record.TagValue = fmt.Sprint(row[i])
+		case "coststatus":
+			record.CostStatus = fmt.Sprint(row[i])
+		}
+	}
+	return record
+}
+
+// formatUsageDate turns the numeric yyyymmdd usage date into yyyy-mm-dd.
+func formatUsageDate(v interface{}) string {
+	if n, ok := v.(float64); ok {
+		v = strconv.FormatInt(int64(n), 10)
+	}
+	s := fmt.Sprint(v)
+	if len(s) == 8 {
+		return s[:4] + "-" + s[4:6] + "-" + s[6:]
+	}
+	return s
+}
+
 func (c *CostClient) QueryCostsByService(ctx context.Context, startDate, endDate string) (*CostQueryResult, error) {
 	req := CostQueryRequest{
 		Type:      "ActualCost",
@@ -230,22 +345,59 @@ func (c *CostClient) QueryCostsByResourceGroup(ctx context.Context, startDate, e
 	return c.QueryCosts(ctx, req)
 }
 
-func (c *CostClient) GetForecast(ctx context.Context, granularity string) (*CostQueryResult, error) {
-	if err := ValidateSubscriptionID(c.SubscriptionID); err != nil {
-		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+// QueryCostsByTag groups daily costs by the values of a single tag key.
+// Costs on untagged resources come back with an empty tag value.
+func (c *CostClient) QueryCostsByTag(ctx context.Context, tagKey, startDate, endDate string) (*CostQueryResult, error) {
+	req := CostQueryRequest{
+		Type:      "ActualCost",
+		Timeframe: "Custom",
+		TimePeriod: &TimePeriod{
+			From: startDate,
+			To:   endDate,
+		},
+		Dataset: Dataset{
+			Granularity: "Daily",
+			Aggregation: map[string]Aggregation{
+				"costTotal": {
+					Name:     "Cost",
+					Function: "Sum",
+				},
+			},
+			Grouping: []Grouping{
+				{Type: "TagKey", Name: tagKey},
+			},
+		},
 	}
 
-	token, err := c.getToken()
+	result, err := c.QueryCosts(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token: %w", err)
+		return nil, err
 	}
+	for i := range result.Records {
+		r := &result.Records[i]
+		r.TagKey = tagKey
+		// The legacy value-list shape reports the group value as the item name.
+		if r.TagValue == "" {
+			r.TagValue, r.ServiceName = r.ServiceName, ""
+		}
+	}
+	return result, nil
+}
 
-	url := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.CostManagement/query?api-version=%s",
-		AzureManagementURL, c.SubscriptionID, CostManagementAPI)
-
+// GetForecast returns Azure's forecast for the days from startDate to
+// endDate. It is a query of type Forecast, so it comes back in the same
+// tabular shape as any other query. Actual costs are left out, both in the
+// request and by row status, so the result can be added to month-to-date
+// costs without counting them twice.
+func (c *CostClient) GetForecast(ctx context.Context, granularity, startDate, endDate string) (*CostQueryResult, error) {
+	includeActualCost := false
 	forecastReq := CostQueryRequest{
 		Type:      "Forecast",
-		Timeframe: "BillingMonthToDate",
+		Timeframe: "Custom",
+		TimePeriod: &TimePeriod{
+			From: startDate,
+			To:   endDate,
+		},
 		Dataset: Dataset{
 			Granularity: granularity,
 			Aggregation: map[string]Aggregation{
@@ -255,52 +407,18 @@ func (c *CostClient) GetForecast(ctx context.Context, granularity string) (*Cost
 				},
 			},
 		},
+		IncludeActualCost: &includeActualCost,
 	}
 
-	body, err := json.Marshal(forecastReq)
+	result, err := c.QueryCosts(ctx, forecastReq)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("forecast request failed: %w", err)
 	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq.Header.Set("Authorization", "Bearer "+token)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.HTTPClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("forecast request failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	var result CostQueryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	var total float64
-	var currency string
-	for _, item := range result.Value {
-		total += item.Properties.Cost
-		if item.Properties.Currency != "" {
-			currency = item.Properties.Currency
+	forecast := &CostQueryResult{Currency: result.Currency}
+	for _, r := range result.Records {
+		if r.CostStatus == "" || strings.EqualFold(r.CostStatus, "Forecast") {
+			forecast.TotalCost += r.Cost
 		}
 	}
-
-	if currency == "" {
-		currency = "USD"
-	}
-
-	return &CostQueryResult{
-		TotalCost: total,
-		Currency:  currency,
-	}, nil
+	return forecast, nil
 }
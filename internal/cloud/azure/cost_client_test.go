@@ -0,0 +1,114 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatUsageDate(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{float64(20240315), "2024-03-15"},
+		{"20240315", "2024-03-15"},
+		{"2024-03-15T00:00:00", "2024-03-15T00:00:00"},
+		{float64(202403), "202403"},
+	}
+	for _, tt := range tests {
+		if got := formatUsageDate(tt.in); got != tt.want {
+			t.Errorf("formatUsageDate(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRow(t *testing.T) {
+	columns := []QueryColumn{
+		{Name: "PreTaxCost", Type: "Number"},
+		{Name: "UsageDate", Type: "Number"},
+		{Name: "ServiceName", Type: "String"},
+		{Name: "ResourceGroupName", Type: "String"},
+		{Name: "TagKey", Type: "String"},
+		{Name: "TagValue", Type: "String"},
+		{Name: "Currency", Type: "String"},
+	}
+
+	got := parseRow(columns, []interface{}{1.25, float64(20240301), "Storage", "rg-dev", "env", "dev", "EUR"})
+	want := CostRecord{
+		ServiceName:   "Storage",
+		ResourceGroup: "rg-dev",
+		TagKey:        "env",
+		TagValue:      "dev",
+		Cost:          1.25,
+		Currency:      "EUR",
+		Date:          "2024-03-01",
+	}
+	if got != want {
+		t.Errorf("parseRow() = %+v, want %+v", got, want)
+	}
+
+	// Short rows and nulls leave the fields empty.
+	got = parseRow(columns, []interface{}{nil, float64(20240302)})
+	if got != (CostRecord{Date: "2024-03-02"}) {
+		t.Errorf("parseRow() with a short row = %+v", got)
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	var resp CostQueryResponse
+	body := `{
+		"properties": {
+			"columns": [{"name": "Cost"}, {"name": "UsageDate"}, {"name": "ServiceName"}, {"name": "Currency"}],
+			"rows": [[0.5, 20240301, "Virtual Machines", "USD"], [0.25, 20240302, "Storage", "USD"]]
+		}
+	}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &CostClient{}
+	result := c.parseResponse(resp)
+	if len(result.Records) != 2 || math.Abs(result.TotalCost-0.75) > 1e-9 || result.Currency != "USD" {
+		t.Errorf("parseResponse() = %+v", result)
+	}
+}
+
+func TestGetForecastReadsTabularRows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CostQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type != "Forecast" {
+			t.Errorf("request type = %q, err %v", req.Type, err)
+		}
+		if req.Timeframe != "Custom" || req.TimePeriod == nil || req.TimePeriod.From != "2024-03-30" || req.TimePeriod.To != "2024-03-31" {
+			t.Errorf("request period = %s %+v, want Custom 2024-03-30 to 2024-03-31", req.Timeframe, req.TimePeriod)
+		}
+		if req.IncludeActualCost == nil || *req.IncludeActualCost {
+			t.Errorf("includeActualCost = %v, want false", req.IncludeActualCost)
+		}
+		// Actual rows are left out even if the API returns them.
+		_, _ = w.Write([]byte(`{
+			"properties": {
+				"columns": [{"name": "Cost"}, {"name": "UsageDate"}, {"name": "CostStatus"}, {"name": "Currency"}],
+				"rows": [[99.0, 20240329, "Actual", "EUR"], [10.5, 20240330, "Forecast", "EUR"], [4.5, 20240331, "Forecast", "EUR"]]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	saved := AzureManagementURL
+	AzureManagementURL = server.URL
+	defer func() { AzureManagementURL = saved }()
+
+	c := NewCostClient("11111111-2222-3333-4444-555555555555", func() (string, error) { return "token", nil })
+	result, err := c.GetForecast(context.Background(), "Daily", "2024-03-30", "2024-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.TotalCost-15) > 1e-9 || result.Currency != "EUR" {
+		t.Errorf("GetForecast() = $%.2f %s, want $15.00 EUR", result.TotalCost, result.Currency)
+	}
+}
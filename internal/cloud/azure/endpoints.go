@@ -0,0 +1,22 @@
+package azure
+
+import (
+	"os"
+	"strings"
+)
+
+// AzureManagementURL is the Resource Manager endpoint and AuthorityHost the
+// Microsoft Entra ID login endpoint. AZURE_MANAGEMENT_ENDPOINT and
+// AZURE_AUTHORITY_HOST override them, e.g. to point azguard at a sovereign
+// cloud or at 'azguard dev fake-server'.
+var (
+	AzureManagementURL = endpointFromEnv("AZURE_MANAGEMENT_ENDPOINT", "https://management.azure.com")
+	AuthorityHost      = endpointFromEnv("AZURE_AUTHORITY_HOST", "https://login.microsoftonline.com")
+)
+
+func endpointFromEnv(name, fallback string) string {
+	if v := strings.TrimRight(os.Getenv(name), "/"); v != "" {
+		return v
+	}
+	return fallback
+}
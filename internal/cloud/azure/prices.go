@@ -0,0 +1,89 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RetailPricesURL is the public, unauthenticated Azure Retail Prices API.
+const RetailPricesURL = "https://prices.azure.com/api/retail/prices"
+
+// RetailPrice is a single meter price from the Retail Prices API.
+type RetailPrice struct {
+	CurrencyCode     string  `json:"currencyCode"`
+	TierMinimumUnits float64 `json:"tierMinimumUnits"`
+	RetailPrice      float64 `json:"retailPrice"`
+	UnitPrice        float64 `json:"unitPrice"`
+	ArmRegionName    string  `json:"armRegionName"`
+	Location         string  `json:"location"`
+	MeterName        string  `json:"meterName"`
+	ProductName      string  `json:"productName"`
+	SkuName          string  `json:"skuName"`
+	ArmSkuName       string  `json:"armSkuName"`
+	ServiceName      string  `json:"serviceName"`
+	UnitOfMeasure    string  `json:"unitOfMeasure"`
+	Type             string  `json:"type"`
+}
+
+// QuoteFilterValue quotes s as an OData string literal for a $filter,
+// doubling any single quotes in it.
+func QuoteFilterValue(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+type PricesClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewPricesClient() *PricesClient {
+	return &PricesClient{
+		BaseURL:    RetailPricesURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Query returns every price matching an OData filter, following NextPageLink.
+func (c *PricesClient) Query(ctx context.Context, filter string) ([]RetailPrice, error) {
+	next := c.BaseURL + "?$filter=" + url.QueryEscape(filter)
+
+	var prices []RetailPrice
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("retail prices request failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var page struct {
+			Items        []RetailPrice `json:"Items"`
+			NextPageLink string        `json:"NextPageLink"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse retail prices: %w", err)
+		}
+
+		prices = append(prices, page.Items...)
+		next = page.NextPageLink
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,20 @@
+package azure
+
+import "testing"
+
+func TestQuoteFilterValue(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Standard_B1s", "'Standard_B1s'"},
+		{"eastus", "'eastus'"},
+		{"B1s' or serviceName eq 'Storage", "'B1s'' or serviceName eq ''Storage'"},
+		{"''", "''''''"},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := QuoteFilterValue(tt.in); got != tt.want {
+			t.Errorf("QuoteFilterValue(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+)
+
+const SubscriptionsAPI = "2022-12-01"
+
+// Subscription is the part of an Azure subscription azguard reads. QuotaID
+// names the offer the subscription was created from, e.g.
+// "FreeTrial_2014-09-01" or "PayAsYouGo_2014-09-01".
+type Subscription struct {
+	ID          string `json:"subscriptionId"`
+	DisplayName string `json:"displayName"`
+	State       string `json:"state"`
+	QuotaID     string `json:"quotaId"`
+}
+
+// GetSubscription returns the client's subscription.
+func (c *CostClient) GetSubscription(ctx context.Context) (*Subscription, error) {
+	if err := ValidateSubscriptionID(c.SubscriptionID); err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/subscriptions/%s?api-version=%s", AzureManagementURL, c.SubscriptionID, SubscriptionsAPI)
+
+	var resp struct {
+		Subscription
+		Policies struct {
+			QuotaID string `json:"quotaId"`
+		} `json:"subscriptionPolicies"`
+	}
+	if err := c.doJSON(ctx, "GET", url, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	sub := resp.Subscription
+	sub.QuotaID = resp.Policies.QuotaID
+	return &sub, nil
+}
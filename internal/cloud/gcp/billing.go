@@ -0,0 +1,215 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CostRecord is one day's cost of a service, net of credits such as the
+// free tier and free trial credits.
+type CostRecord struct {
+	ProjectID   string  `json:"project_id"`
+	ServiceName string  `json:"service"`
+	Cost        float64 `json:"cost"`
+	Currency    string  `json:"currency"`
+	Date        string  `json:"date"`
+}
+
+// SKUUsage is the usage of one SKU over a period, in its pricing unit
+// (e.g. "hour", "gibibyte month").
+type SKUUsage struct {
+	ServiceName string  `json:"service"`
+	SKU         string  `json:"sku"`
+	Amount      float64 `json:"amount"`
+	Unit        string  `json:"unit"`
+	Cost        float64 `json:"cost"`
+}
+
+// netCost adds the (negative) credits of each row to its cost.
+const netCost = "SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0))"
+
+// GetDailyCosts returns daily costs per service between startDate and
+// endDate (exclusive) for the client's project.
+func (c *Client) GetDailyCosts(ctx context.Context, startDate, endDate string) ([]CostRecord, error) {
+	sql := `SELECT FORMAT_DATE('%Y-%m-%d', DATE(usage_start_time)), project.id, service.description, currency, ` + netCost + `
+FROM ` + "`{table}`" + `
+WHERE usage_start_time >= TIMESTAMP(@start) AND usage_start_time < TIMESTAMP(@end) AND project.id = @project
+GROUP BY 1, 2, 3, 4
+ORDER BY 1`
+
+	rows, err := c.queryBilling(ctx, sql, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GCP costs: %w", err)
+	}
+
+	records := make([]CostRecord, 0, len(rows))
+	for _, row := range rows {
+		cost, err := parseAmount(row[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost on %s for %s: %w", row[0], row[2], err)
+		}
+		records = append(records, CostRecord{
+			Date:        row[0],
+			ProjectID:   row[1],
+			ServiceName: row[2],
+			Currency:    row[3],
+			Cost:        cost,
+		})
+	}
+	return records, nil
+}
+
+// GetSKUUsage returns usage per SKU between startDate and endDate
+// (exclusive) for the client's project, to check against free tier limits.
+func (c *Client) GetSKUUsage(ctx context.Context, startDate, endDate string) ([]SKUUsage, error) {
+	sql := `SELECT service.description, sku.description, usage.pricing_unit, SUM(usage.amount_in_pricing_units), ` + netCost + `
+FROM ` + "`{table}`" + `
+WHERE usage_start_time >= TIMESTAMP(@start) AND usage_start_time < TIMESTAMP(@end) AND project.id = @project
+GROUP BY 1, 2, 3
+ORDER BY 1, 2`
+
+	rows, err := c.queryBilling(ctx, sql, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GCP usage: %w", err)
+	}
+
+	usages := make([]SKUUsage, 0, len(rows))
+	for _, row := range rows {
+		amount, err := parseAmount(row[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage for %s: %w", row[1], err)
+		}
+		cost, err := parseAmount(row[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost for %s: %w", row[1], err)
+		}
+		usages = append(usages, SKUUsage{
+			ServiceName: row[0],
+			SKU:         row[1],
+			Unit:        row[2],
+			Amount:      amount,
+			Cost:        cost,
+		})
+	}
+	return usages, nil
+}
+
+// parseAmount parses a numeric query result. NULL, which query returns as "",
+// is zero.
+func parseAmount(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return v, nil
+}
+
+var tableName = regexp.MustCompile(`^[A-Za-z0-9_:-]+\.[A-Za-z0-9_]+\.[A-Za-z0-9_]+$`)
+
+// queryBilling runs sql, with {table} standing for the export table, for the
+// client's project and period.
+func (c *Client) queryBilling(ctx context.Context, sql, startDate, endDate string) ([][]string, error) {
+	if c.BillingTable == "" {
+		return nil, fmt.Errorf("GCP billing export table not configured. Set gcp.billing_table to project.dataset.gcp_billing_export_v1_XXXXXX")
+	}
+	if !tableName.MatchString(c.BillingTable) {
+		return nil, fmt.Errorf("invalid billing table %q: expected project.dataset.table", c.BillingTable)
+	}
+	if c.ProjectID == "" {
+		return nil, fmt.Errorf("GCP project not configured. Set gcp.project_id or run 'gcloud config set project'")
+	}
+
+	// Jobs run in the project that holds the export, which is often not the
+	// monitored one.
+	jobProject, _, _ := strings.Cut(c.BillingTable, ".")
+	return c.query(ctx, jobProject, strings.ReplaceAll(sql, "{table}", c.BillingTable), map[string]string{
+		"start":   startDate,
+		"end":     endDate,
+		"project": c.ProjectID,
+	})
+}
+
+type queryResponse struct {
+	JobComplete  bool `json:"jobComplete"`
+	JobReference struct {
+		JobID    string `json:"jobId"`
+		Location string `json:"location"`
+	} `json:"jobReference"`
+	Rows []struct {
+		F []struct {
+			V interface{} `json:"v"`
+		} `json:"f"`
+	} `json:"rows"`
+	PageToken string `json:"pageToken"`
+}
+
+// query runs a standard SQL query with named string parameters and returns
+// every row as strings, NULL as "". It waits for long-running jobs and
+// follows result pages.
+func (c *Client) query(ctx context.Context, project, sql string, params map[string]string) ([][]string, error) {
+	var queryParams []map[string]interface{}
+	for name, value := range params {
+		queryParams = append(queryParams, map[string]interface{}{
+			"name":           name,
+			"parameterType":  map[string]string{"type": "STRING"},
+			"parameterValue": map[string]string{"value": value},
+		})
+	}
+
+	var resp queryResponse
+	err := c.call(ctx, "POST", BigQueryEndpoint+"/projects/"+url.PathEscape(project)+"/queries", map[string]interface{}{
+		"query":           sql,
+		"useLegacySql":    false,
+		"parameterMode":   "NAMED",
+		"queryParameters": queryParams,
+		"timeoutMs":       30000,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for {
+		if resp.JobComplete {
+			for _, r := range resp.Rows {
+				row := make([]string, len(r.F))
+				for i, f := range r.F {
+					if f.V != nil {
+						row[i] = fmt.Sprint(f.V)
+					}
+				}
+				rows = append(rows, row)
+			}
+			if resp.PageToken == "" {
+				return rows, nil
+			}
+		}
+
+		q := url.Values{"timeoutMs": {"30000"}}
+		if resp.JobReference.Location != "" {
+			q.Set("location", resp.JobReference.Location)
+		}
+		if resp.JobComplete {
+			q.Set("pageToken", resp.PageToken)
+		}
+		job := resp.JobReference
+		if job.JobID == "" {
+			return nil, fmt.Errorf("BigQuery returned an incomplete job without a job ID")
+		}
+
+		resp = queryResponse{}
+		if err := c.call(ctx, "GET", BigQueryEndpoint+"/projects/"+url.PathEscape(project)+"/queries/"+url.PathEscape(job.JobID)+"?"+q.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+		if resp.JobReference.JobID == "" {
+			resp.JobReference = job
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type staticToken struct{}
+
+func (staticToken) Token(ctx context.Context) (Token, error) {
+	return Token{AccessToken: "token", Expires: time.Now().Add(time.Hour)}, nil
+}
+
+// billingServer answers every BigQuery query with rows, given as the string
+// values of each row's fields; nil is NULL.
+func billingServer(t *testing.T, rows [][]interface{}) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/projects/billing-proj/queries") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		resp := map[string]interface{}{"jobComplete": true}
+		var out []map[string]interface{}
+		for _, row := range rows {
+			var fields []map[string]interface{}
+			for _, v := range row {
+				fields = append(fields, map[string]interface{}{"v": v})
+			}
+			out = append(out, map[string]interface{}{"f": fields})
+		}
+		resp["rows"] = out
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	saved := BigQueryEndpoint
+	BigQueryEndpoint = server.URL
+	t.Cleanup(func() { BigQueryEndpoint = saved })
+
+	return &Client{
+		ProjectID:    "my-proj",
+		BillingTable: "billing-proj.billing.gcp_billing_export_v1_0000",
+		Credentials:  staticToken{},
+		HTTP:         server.Client(),
+	}
+}
+
+func TestGetDailyCosts(t *testing.T) {
+	c := billingServer(t, [][]interface{}{
+		{"2024-05-01", "my-proj", "Compute Engine", "EUR", "1.25"},
+		{"2024-05-01", "my-proj", "Cloud Storage", "EUR", nil},
+	})
+	records, err := c.GetDailyCosts(context.Background(), "2024-05-01", "2024-05-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Cost != 1.25 || records[0].Currency != "EUR" || records[1].Cost != 0 {
+		t.Errorf("GetDailyCosts() = %+v, want $1.25 EUR and a NULL cost as 0", records)
+	}
+}
+
+func TestGetDailyCostsRejectsInvalidCost(t *testing.T) {
+	c := billingServer(t, [][]interface{}{
+		{"2024-05-01", "my-proj", "Compute Engine", "USD", "1.2.5"},
+	})
+	if _, err := c.GetDailyCosts(context.Background(), "2024-05-01", "2024-05-02"); err == nil || !strings.Contains(err.Error(), `"1.2.5"`) {
+		t.Errorf("GetDailyCosts() error = %v, want the invalid cost reported", err)
+	}
+}
+
+func TestGetSKUUsageRejectsInvalidAmount(t *testing.T) {
+	c := billingServer(t, [][]interface{}{
+		{"Compute Engine", "E2 Instance Core", "hour", "NaN-ish", "0"},
+	})
+	if _, err := c.GetSKUUsage(context.Background(), "2024-05-01", "2024-06-01"); err == nil || !strings.Contains(err.Error(), "E2 Instance Core") {
+		t.Errorf("GetSKUUsage() error = %v, want the invalid amount reported", err)
+	}
+}
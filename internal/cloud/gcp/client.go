@@ -0,0 +1,166 @@
+// Package gcp reads Google Cloud billing data from the Cloud Billing
+// BigQuery export and lists resources through the Google Cloud REST APIs.
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Google API endpoints. They are variables so tests can point the client at
+// a local server.
+var (
+	BigQueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+	ComputeEndpoint  = "https://compute.googleapis.com/compute/v1"
+	StorageEndpoint  = "https://storage.googleapis.com/storage/v1"
+)
+
+// Client talks to Google Cloud APIs for one project.
+type Client struct {
+	ProjectID string
+	// BillingTable is the Cloud Billing export table, as
+	// project.dataset.gcp_billing_export_v1_XXXXXX.
+	BillingTable string
+	Credentials  CredentialsProvider
+	HTTP         *http.Client
+
+	mu    sync.Mutex
+	token Token
+}
+
+// NewClient creates a client. Credentials come from credentialsFile, then
+// GOOGLE_APPLICATION_CREDENTIALS, application default credentials and the
+// gcloud CLI. Without a projectID the project comes from the credentials,
+// GOOGLE_CLOUD_PROJECT or the gcloud configuration.
+func NewClient(projectID, credentialsFile, billingTable string) (*Client, error) {
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+
+	creds, credsProject, err := resolveCredentials(credentialsFile, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if projectID == "" {
+		projectID = credsProject
+	}
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if projectID == "" {
+		projectID = gcloudProject()
+	}
+
+	return &Client{
+		ProjectID:    projectID,
+		BillingTable: billingTable,
+		Credentials:  creds,
+		HTTP:         httpClient,
+	}, nil
+}
+
+// IsConfigured returns true if the client has a way to obtain tokens.
+func (c *Client) IsConfigured() bool {
+	return c.Credentials != nil
+}
+
+// accessToken returns a cached token, fetching a new one shortly before expiry.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.Credentials == nil {
+		return "", fmt.Errorf("GCP credentials not configured. Run 'gcloud auth application-default login' or set GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token.AccessToken != "" && time.Until(c.token.Expires) > time.Minute {
+		return c.token.AccessToken, nil
+	}
+	token, err := c.Credentials.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	return token.AccessToken, nil
+}
+
+// call sends a JSON request and decodes the response into out. body may be nil.
+func (c *Client) call(ctx context.Context, method, url string, body, out interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s: %s", apiErr.Error.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("GCP API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse GCP response: %w", err)
+	}
+	return nil
+}
+
+func gcloudProject() string {
+	if _, err := exec.LookPath("gcloud"); err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "gcloud", "config", "get-value", "project").Output()
+	if err != nil {
+		return ""
+	}
+	project := strings.TrimSpace(string(output))
+	if project == "(unset)" {
+		return ""
+	}
+	return project
+}
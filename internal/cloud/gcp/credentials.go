@@ -0,0 +1,272 @@
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// CloudPlatformScope grants read access to billing data and inventory.
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	DefaultTokenURI    = "https://oauth2.googleapis.com/token"
+)
+
+// Token is an OAuth 2.0 access token.
+type Token struct {
+	AccessToken string
+	Expires     time.Time
+}
+
+// CredentialsProvider returns access tokens for Google APIs.
+type CredentialsProvider interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// ServiceAccountProvider signs a JWT with a service account key and trades
+// it for an access token.
+type ServiceAccountProvider struct {
+	Email        string
+	PrivateKeyID string
+	Key          *rsa.PrivateKey
+	TokenURI     string
+	HTTP         *http.Client
+}
+
+func (p *ServiceAccountProvider) Token(ctx context.Context) (Token, error) {
+	tokenURI := p.TokenURI
+	if tokenURI == "" {
+		tokenURI = DefaultTokenURI
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   p.Email,
+		"scope": CloudPlatformScope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(nil, p.Key, crypto.SHA256, sum[:])
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to sign service account JWT: %w", err)
+	}
+
+	return exchangeToken(ctx, p.HTTP, tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)},
+	})
+}
+
+// AuthorizedUserProvider refreshes the user credentials written by
+// 'gcloud auth application-default login'.
+type AuthorizedUserProvider struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	TokenURI     string
+	HTTP         *http.Client
+}
+
+func (p *AuthorizedUserProvider) Token(ctx context.Context) (Token, error) {
+	tokenURI := p.TokenURI
+	if tokenURI == "" {
+		tokenURI = DefaultTokenURI
+	}
+	return exchangeToken(ctx, p.HTTP, tokenURI, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"refresh_token": {p.RefreshToken},
+	})
+}
+
+// GcloudProvider asks the gcloud CLI for a token of the active account.
+type GcloudProvider struct{}
+
+func (GcloudProvider) Token(ctx context.Context) (Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "gcloud", "auth", "print-access-token").Output()
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to get gcloud token: %w (run 'gcloud auth login' first)", err)
+	}
+	// gcloud does not report the expiry; its tokens last an hour.
+	return Token{
+		AccessToken: strings.TrimSpace(string(output)),
+		Expires:     time.Now().Add(50 * time.Minute),
+	}, nil
+}
+
+// credentialsFile is the JSON key or application default credentials file.
+type credentialsFile struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	QuotaProject string `json:"quota_project_id"`
+}
+
+// LoadCredentialsFile reads a service account key or authorized user file
+// and returns a provider for it with the project the file names, if any.
+func LoadCredentialsFile(path string, httpClient *http.Client) (CredentialsProvider, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read GCP credentials: %w", err)
+	}
+	var f credentialsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, "", fmt.Errorf("failed to parse GCP credentials %s: %w", path, err)
+	}
+
+	switch f.Type {
+	case "service_account":
+		key, err := parsePrivateKey(f.PrivateKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("GCP credentials %s: %w", path, err)
+		}
+		return &ServiceAccountProvider{
+			Email:        f.ClientEmail,
+			PrivateKeyID: f.PrivateKeyID,
+			Key:          key,
+			TokenURI:     f.TokenURI,
+			HTTP:         httpClient,
+		}, f.ProjectID, nil
+	case "authorized_user":
+		return &AuthorizedUserProvider{
+			ClientID:     f.ClientID,
+			ClientSecret: f.ClientSecret,
+			RefreshToken: f.RefreshToken,
+			TokenURI:     f.TokenURI,
+			HTTP:         httpClient,
+		}, f.QuotaProject, nil
+	}
+	return nil, "", fmt.Errorf("GCP credentials %s: unsupported type %q", path, f.Type)
+}
+
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("private_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private_key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private_key: %w", err)
+	}
+	return key, nil
+}
+
+// ApplicationDefaultCredentialsPath is where 'gcloud auth
+// application-default login' writes credentials, honoring CLOUDSDK_CONFIG.
+func ApplicationDefaultCredentialsPath() string {
+	dir := os.Getenv("CLOUDSDK_CONFIG")
+	if dir == "" {
+		if runtime.GOOS == "windows" {
+			dir = filepath.Join(os.Getenv("APPDATA"), "gcloud")
+		} else {
+			home, _ := os.UserHomeDir()
+			dir = filepath.Join(home, ".config", "gcloud")
+		}
+	}
+	return filepath.Join(dir, "application_default_credentials.json")
+}
+
+// resolveCredentials picks credentials in order from the configured file,
+// GOOGLE_APPLICATION_CREDENTIALS, application default credentials and the
+// gcloud CLI. It returns the project the credentials name, if any, and a nil
+// provider when nothing is configured.
+func resolveCredentials(path string, httpClient *http.Client) (CredentialsProvider, string, error) {
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path != "" {
+		return LoadCredentialsFile(path, httpClient)
+	}
+
+	if adc := ApplicationDefaultCredentialsPath(); fileExists(adc) {
+		return LoadCredentialsFile(adc, httpClient)
+	}
+
+	if _, err := exec.LookPath("gcloud"); err == nil {
+		return GcloudProvider{}, "", nil
+	}
+	return nil, "", nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// exchangeToken posts an OAuth 2.0 token request.
+func exchangeToken(ctx context.Context, httpClient *http.Client, tokenURI string, form url.Values) (Token, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Token{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("GCP token request failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return Token{}, fmt.Errorf("failed to parse GCP token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return Token{}, fmt.Errorf("GCP token response has no access_token")
+	}
+	return Token{
+		AccessToken: result.AccessToken,
+		Expires:     time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,309 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Resource types reported by ListResources.
+const (
+	ResourceInstance = "VM instance"
+	ResourceDisk     = "disk"
+	ResourceAddress  = "static IP"
+	ResourceBucket   = "bucket"
+)
+
+// Resource is one Compute Engine or Cloud Storage resource. SKU is what the
+// free tier rules look at: the machine type, disk type, address type or
+// storage class. FreeTier and Note are filled in by the caller's rules.
+type Resource struct {
+	Region   string   `json:"region"`
+	Zone     string   `json:"zone,omitempty"`
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	SKU      string   `json:"sku"`
+	Status   string   `json:"status,omitempty"`
+	Detail   string   `json:"detail"`
+	SizeGB   int      `json:"size_gb,omitempty"`
+	Users    []string `json:"users,omitempty"`
+	Spot     bool     `json:"spot,omitempty"`
+	FreeTier bool     `json:"free_tier"`
+	Note     string   `json:"note,omitempty"`
+}
+
+// Inventory is the result of listing a project. Errors lists the calls that
+// failed; Warnings holds findings about the resources as a whole.
+type Inventory struct {
+	ProjectID string     `json:"project_id"`
+	Resources []Resource `json:"resources"`
+	Warnings  []string   `json:"warnings,omitempty"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// ListResources lists the project's VM instances, persistent disks, external
+// static IPs and Cloud Storage buckets in every region.
+func (c *Client) ListResources(ctx context.Context) (*Inventory, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("GCP credentials not configured. Run 'gcloud auth application-default login' or set GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if c.ProjectID == "" {
+		return nil, fmt.Errorf("GCP project not configured. Set gcp.project_id or run 'gcloud config set project'")
+	}
+
+	inv := &Inventory{ProjectID: c.ProjectID}
+	collect := func(what string, resources []Resource, err error) {
+		if err != nil {
+			inv.Errors = append(inv.Errors, fmt.Sprintf("%s: %v", what, err))
+			return
+		}
+		inv.Resources = append(inv.Resources, resources...)
+	}
+
+	r, err := c.listInstances(ctx)
+	collect("instances", r, err)
+	r, err = c.listDisks(ctx)
+	collect("disks", r, err)
+	r, err = c.listAddresses(ctx)
+	collect("addresses", r, err)
+	r, err = c.listBuckets(ctx)
+	collect("buckets", r, err)
+
+	sort.Slice(inv.Resources, func(i, j int) bool {
+		a, b := inv.Resources[i], inv.Resources[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	return inv, nil
+}
+
+func (c *Client) listInstances(ctx context.Context) ([]Resource, error) {
+	type instance struct {
+		Name        string `json:"name"`
+		Zone        string `json:"zone"`
+		MachineType string `json:"machineType"`
+		Status      string `json:"status"`
+		Scheduling  struct {
+			Preemptible       bool   `json:"preemptible"`
+			ProvisioningModel string `json:"provisioningModel"`
+		} `json:"scheduling"`
+	}
+
+	var resources []Resource
+	err := c.aggregated(ctx, "instances", func(items json.RawMessage) error {
+		var list []instance
+		if err := json.Unmarshal(items, &list); err != nil {
+			return err
+		}
+		for _, inst := range list {
+			zone := path.Base(inst.Zone)
+			machineType := path.Base(inst.MachineType)
+			resources = append(resources, Resource{
+				Region: zoneRegion(zone),
+				Zone:   zone,
+				Type:   ResourceInstance,
+				Name:   inst.Name,
+				SKU:    machineType,
+				Status: inst.Status,
+				Detail: fmt.Sprintf("%s, %s in %s", machineType, strings.ToLower(inst.Status), zone),
+				Spot:   inst.Scheduling.Preemptible || inst.Scheduling.ProvisioningModel == "SPOT",
+			})
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (c *Client) listDisks(ctx context.Context) ([]Resource, error) {
+	type disk struct {
+		Name   string   `json:"name"`
+		Zone   string   `json:"zone"`
+		Region string   `json:"region"`
+		Type   string   `json:"type"`
+		SizeGB string   `json:"sizeGb"`
+		Status string   `json:"status"`
+		Users  []string `json:"users"`
+	}
+
+	var resources []Resource
+	err := c.aggregated(ctx, "disks", func(items json.RawMessage) error {
+		var list []disk
+		if err := json.Unmarshal(items, &list); err != nil {
+			return err
+		}
+		for _, d := range list {
+			size, _ := strconv.Atoi(d.SizeGB)
+			diskType := path.Base(d.Type)
+			res := Resource{
+				Type:   ResourceDisk,
+				Name:   d.Name,
+				SKU:    diskType,
+				Status: d.Status,
+				SizeGB: size,
+				Users:  d.Users,
+			}
+
+			where := ""
+			if d.Zone != "" {
+				res.Zone = path.Base(d.Zone)
+				res.Region = zoneRegion(res.Zone)
+				where = res.Zone
+			} else {
+				res.Region = path.Base(d.Region)
+				where = res.Region + " (regional)"
+			}
+
+			attached := "unattached"
+			if len(d.Users) > 0 {
+				names := make([]string, len(d.Users))
+				for i, u := range d.Users {
+					names[i] = path.Base(u)
+				}
+				attached = "attached to " + strings.Join(names, ", ")
+			}
+			res.Detail = fmt.Sprintf("%s, %d GB in %s, %s", diskType, size, where, attached)
+			resources = append(resources, res)
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (c *Client) listAddresses(ctx context.Context) ([]Resource, error) {
+	type address struct {
+		Name        string   `json:"name"`
+		Address     string   `json:"address"`
+		AddressType string   `json:"addressType"`
+		Region      string   `json:"region"`
+		Status      string   `json:"status"`
+		Users       []string `json:"users"`
+	}
+
+	var resources []Resource
+	err := c.aggregated(ctx, "addresses", func(items json.RawMessage) error {
+		var list []address
+		if err := json.Unmarshal(items, &list); err != nil {
+			return err
+		}
+		for _, a := range list {
+			// Internal addresses are free; only external ones are billed.
+			if a.AddressType == "INTERNAL" {
+				continue
+			}
+			region := "global"
+			if a.Region != "" {
+				region = path.Base(a.Region)
+			}
+			detail := a.Address + ", reserved and unused"
+			if len(a.Users) > 0 {
+				detail = a.Address + ", in use by " + path.Base(a.Users[0])
+			}
+			resources = append(resources, Resource{
+				Region: region,
+				Type:   ResourceAddress,
+				Name:   a.Name,
+				SKU:    "EXTERNAL",
+				Status: a.Status,
+				Detail: detail,
+				Users:  a.Users,
+			})
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (c *Client) listBuckets(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	pageToken := ""
+	for {
+		q := url.Values{"project": {c.ProjectID}, "maxResults": {"1000"}}
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+
+		var resp struct {
+			Items []struct {
+				Name         string `json:"name"`
+				Location     string `json:"location"`
+				LocationType string `json:"locationType"`
+				StorageClass string `json:"storageClass"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.call(ctx, "GET", StorageEndpoint+"/b?"+q.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, b := range resp.Items {
+			location := strings.ToLower(b.Location)
+			resources = append(resources, Resource{
+				Region: location,
+				Type:   ResourceBucket,
+				Name:   b.Name,
+				SKU:    b.StorageClass,
+				Detail: fmt.Sprintf("%s, %s %s", b.StorageClass, b.LocationType, location),
+			})
+		}
+
+		if resp.NextPageToken == "" {
+			return resources, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// aggregated follows the pages of a Compute Engine aggregated list and
+// passes the resource list of every zone or region to fn. Scopes without
+// resources only carry a warning and are skipped.
+func (c *Client) aggregated(ctx context.Context, resource string, fn func(items json.RawMessage) error) error {
+	pageToken := ""
+	for {
+		q := url.Values{"maxResults": {"500"}}
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+
+		var resp struct {
+			Items         map[string]map[string]json.RawMessage `json:"items"`
+			NextPageToken string                                `json:"nextPageToken"`
+		}
+		endpoint := ComputeEndpoint + "/projects/" + url.PathEscape(c.ProjectID) + "/aggregated/" + resource + "?" + q.Encode()
+		if err := c.call(ctx, "GET", endpoint, nil, &resp); err != nil {
+			return err
+		}
+
+		for _, scope := range resp.Items {
+			items, ok := scope[resource]
+			if !ok {
+				continue
+			}
+			if err := fn(items); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", resource, err)
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			return nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// zoneRegion returns the region of a zone, e.g. us-central1 for us-central1-a.
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
@@ -0,0 +1,209 @@
+// Package cloud defines the interface azguard uses to read costs, free tier
+// usage, resources and forecasts from a cloud provider, and the registry the
+// commands look providers up in.
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotSupported is returned by providers for operations they cannot do.
+var ErrNotSupported = errors.New("not supported")
+
+// Provider is one cloud provider account: an Azure subscription, an AWS
+// account or a GCP project.
+type Provider interface {
+	// Name is the provider key, e.g. "azure", as stored with cost records.
+	Name() string
+	// IsConfigured reports whether credentials and an account are set up.
+	IsConfigured() bool
+	// Account returns the subscription, account or project ID.
+	Account(ctx context.Context) (string, error)
+	// DailyCosts returns daily costs per service. endDate is exclusive.
+	DailyCosts(ctx context.Context, startDate, endDate string) ([]CostRecord, error)
+	// FreeTierUsage returns this month's usage of each free tier allowance.
+	FreeTierUsage(ctx context.Context) ([]FreeTierUsage, error)
+	// Inventory lists resources and flags those outside the free tier.
+	Inventory(ctx context.Context) (*Inventory, error)
+	// Forecast estimates this month's total bill.
+	Forecast(ctx context.Context) (*Forecast, error)
+}
+
+// CostRecord is one day's cost of a service in an account.
+type CostRecord struct {
+	AccountID     string  `json:"account_id"`
+	ResourceGroup string  `json:"resource_group,omitempty"`
+	ServiceName   string  `json:"service"`
+	Cost          float64 `json:"cost"`
+	Currency      string  `json:"currency"`
+	Date          string  `json:"date"`
+}
+
+// FreeTierUsage is the month-to-date usage of one free tier allowance.
+// Threshold is the fraction of Limit at which usage counts as a warning.
+type FreeTierUsage struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Used        float64 `json:"used"`
+	Forecast    float64 `json:"forecast,omitempty"`
+	Limit       float64 `json:"limit"`
+	Unit        string  `json:"unit"`
+	PercentUsed float64 `json:"percent_used"`
+	Threshold   float64 `json:"threshold"`
+}
+
+// Resource is one resource found by an inventory, flagged as inside the free
+// tier or billable.
+type Resource struct {
+	Region   string `json:"region"`
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	SKU      string `json:"sku,omitempty"`
+	Detail   string `json:"detail"`
+	SizeGB   int    `json:"size_gb,omitempty"`
+	FreeTier bool   `json:"free_tier"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Inventory is the result of listing an account. Warnings are findings about
+// the resources as a whole; Errors lists calls that failed while the rest of
+// the listing still completed.
+type Inventory struct {
+	Provider  string     `json:"provider"`
+	Account   string     `json:"account,omitempty"`
+	Regions   []string   `json:"regions,omitempty"`
+	Resources []Resource `json:"resources"`
+	Warnings  []string   `json:"warnings,omitempty"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// Forecast is an estimate of the month-end bill, with a prediction interval
+// when the provider gives one.
+type Forecast struct {
+	MonthToDate float64 `json:"month_to_date"`
+	MonthEnd    float64 `json:"month_end"`
+	Lower       float64 `json:"lower,omitempty"`
+	Upper       float64 `json:"upper,omitempty"`
+	Currency    string  `json:"currency"`
+}
+
+// Credit is what is left of a free credit or free plan. Plan describes the
+// account plan for providers that report one, e.g. "Free (active)". Paid is
+// set once the account left the free plan, in which case there is no credit
+// and the classic free tier applies; Expired is set when the free plan ended
+// without an upgrade.
+type Credit struct {
+	Remaining float64   `json:"remaining"`
+	Currency  string    `json:"currency"`
+	Expires   time.Time `json:"expires,omitempty"`
+	Plan      string    `json:"plan,omitempty"`
+	Paid      bool      `json:"paid,omitempty"`
+	Expired   bool      `json:"expired,omitempty"`
+}
+
+// CreditReporter is implemented by providers whose free tier is a credit.
+// Credit returns nil when the account has no credit or plan to report.
+type CreditReporter interface {
+	Credit(ctx context.Context) (*Credit, error)
+}
+
+// Factory creates a provider. It is called once, the first time the
+// provider is looked up.
+type Factory func() (Provider, error)
+
+// Registry holds the known providers in registration order and creates each
+// on first use. Factories run outside the registry lock, so a slow one (the
+// AWS credential probe can take seconds) only holds up callers of that
+// provider.
+type Registry struct {
+	mu        sync.Mutex
+	names     []string
+	factories map[string]Factory
+	providers map[string]*lazyProvider
+}
+
+// lazyProvider is a provider created by its factory on first use. A failed
+// factory is retried on the next lookup.
+type lazyProvider struct {
+	mu       sync.Mutex
+	factory  Factory
+	provider Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+		providers: make(map[string]*lazyProvider),
+	}
+}
+
+// Register adds a provider under name, replacing any earlier registration.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.factories[name] = factory
+	delete(r.providers, name)
+}
+
+// Names returns the registered provider names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+// Get returns the named provider, creating it on first use.
+func (r *Registry) Get(name string) (Provider, error) {
+	r.mu.Lock()
+	factory, ok := r.factories[name]
+	if !ok {
+		defer r.mu.Unlock()
+		return nil, r.unknown(name)
+	}
+	lp := r.providers[name]
+	if lp == nil {
+		lp = &lazyProvider{factory: factory}
+		r.providers[name] = lp
+	}
+	r.mu.Unlock()
+
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if lp.provider != nil {
+		return lp.provider, nil
+	}
+	p, err := lp.factory()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	lp.provider = p
+	return p, nil
+}
+
+// Select resolves a --provider value: one provider name, or "all" (or empty)
+// for every registered provider.
+func (r *Registry) Select(provider string) ([]string, error) {
+	if provider == "" || provider == "all" {
+		return r.Names(), nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[provider]; !ok {
+		return nil, r.unknown(provider)
+	}
+	return []string{provider}, nil
+}
+
+func (r *Registry) unknown(name string) error {
+	return fmt.Errorf("unknown provider %q (expected %s or all)", name, strings.Join(r.names, ", "))
+}
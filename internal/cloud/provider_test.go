@@ -0,0 +1,85 @@
+package cloud
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubProvider implements only Name; the other methods are never called.
+type stubProvider struct {
+	Provider
+	name string
+}
+
+func (p stubProvider) Name() string { return p.name }
+
+func TestRegistrySlowFactoryDoesNotBlockOthers(t *testing.T) {
+	r := NewRegistry()
+	release := make(chan struct{})
+	var calls int32
+	r.Register("aws", func() (Provider, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return stubProvider{name: "aws"}, nil
+	})
+	r.Register("azure", func() (Provider, error) { return stubProvider{name: "azure"}, nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p, err := r.Get("aws"); err != nil || p.Name() != "aws" {
+				t.Errorf("Get(aws) = %v, %v", p, err)
+			}
+		}()
+	}
+
+	// While aws is still being created, other providers are available.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if p, err := r.Get("azure"); err != nil || p.Name() != "azure" {
+			t.Errorf("Get(azure) = %v, %v", p, err)
+		}
+		if names, err := r.Select("all"); err != nil || len(names) != 2 {
+			t.Errorf("Select(all) = %v, %v", names, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Get(azure) waited for the aws factory")
+	}
+
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("aws factory ran %d times, want once", n)
+	}
+}
+
+func TestRegistryRetriesFailedFactory(t *testing.T) {
+	r := NewRegistry()
+	fail := true
+	r.Register("gcp", func() (Provider, error) {
+		if fail {
+			return nil, errors.New("no project")
+		}
+		return stubProvider{name: "gcp"}, nil
+	})
+
+	if _, err := r.Get("gcp"); err == nil || err.Error() != "gcp: no project" {
+		t.Fatalf("Get(gcp) error = %v, want the factory error", err)
+	}
+	fail = false
+	if p, err := r.Get("gcp"); err != nil || p.Name() != "gcp" {
+		t.Errorf("Get(gcp) = %v, %v; want the factory retried", p, err)
+	}
+	if _, err := r.Get("oracle"); err == nil {
+		t.Error("Get(oracle) succeeded, want an unknown provider error")
+	}
+}
@@ -37,6 +37,7 @@ type AzureConfig struct {
 }
 
 type AWSConfig struct {
+	Profile      string `mapstructure:"profile"`
 	AccessKey    string `mapstructure:"access_key"`
 	SecretKey    string `mapstructure:"secret_key"`
 	SessionToken string `mapstructure:"session_token"`
@@ -44,7 +45,9 @@ type AWSConfig struct {
 }
 
 type GCPConfig struct {
-	ProjectID string `mapstructure:"project_id"`
+	ProjectID       string `mapstructure:"project_id"`
+	CredentialsFile string `mapstructure:"credentials_file"`
+	BillingTable    string `mapstructure:"billing_table"`
 }
 
 type StorageConfig struct {
@@ -0,0 +1,106 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azguard/azguard/internal/cloud"
+	awscloud "github.com/azguard/azguard/internal/cloud/aws"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// FetchAndStoreAWSCostsByAccount is FetchAndStoreCosts for every linked
+// account of an organization, storing each account's costs under its own ID.
+func (s *Service) FetchAndStoreAWSCostsByAccount(ctx context.Context, client *awscloud.CostClient, startDate, endDate string) (int, error) {
+	costs, err := client.GetDailyCostsByAccount(ctx, startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	var accountIDs []string
+	seen := make(map[string]bool)
+	for _, r := range costs {
+		if !seen[r.AccountID] {
+			seen[r.AccountID] = true
+			accountIDs = append(accountIDs, r.AccountID)
+		}
+	}
+
+	return s.replaceAWSCosts(costs, accountIDs, startDate, endDate)
+}
+
+// replaceAWSCosts swaps the stored records of each account for the period.
+func (s *Service) replaceAWSCosts(costs []awscloud.CostRecord, accountIDs []string, startDate, endDate string) (int, error) {
+	records := make([]cloud.CostRecord, len(costs))
+	for i, r := range costs {
+		records[i] = cloud.CostRecord{
+			AccountID:   r.AccountID,
+			ServiceName: r.ServiceName,
+			Cost:        r.Cost,
+			Currency:    r.Currency,
+			Date:        r.Date,
+		}
+	}
+	return s.replaceCosts("aws", records, accountIDs, startDate, endDate)
+}
+
+// SyncAWSAnomalies fetches the anomalies detected in the period and stores
+// them, updating ones already stored. It returns them largest impact first.
+func (s *Service) SyncAWSAnomalies(ctx context.Context, client *awscloud.CostClient, startDate, endDate string) ([]storage.Anomaly, error) {
+	accountID, err := client.GetCallerIdentity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up AWS account: %w", err)
+	}
+
+	found, err := client.GetAnomalies(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]storage.Anomaly, len(found))
+	for i, a := range found {
+		anomaly := storage.Anomaly{
+			ID:            a.ID,
+			Provider:      "aws",
+			AccountID:     accountID,
+			StartDate:     dateOnly(a.StartDate),
+			EndDate:       dateOnly(a.EndDate),
+			ServiceName:   a.Dimension,
+			Impact:        a.Impact,
+			ImpactPercent: a.ImpactPercent,
+			ActualSpend:   a.ActualSpend,
+			ExpectedSpend: a.ExpectedSpend,
+			Score:         a.Score,
+		}
+		for _, rc := range a.RootCauses {
+			anomaly.RootCauses = append(anomaly.RootCauses, storage.AnomalyCause{
+				Service:       rc.Service,
+				Region:        rc.Region,
+				UsageType:     rc.UsageType,
+				LinkedAccount: rc.LinkedAccount,
+			})
+		}
+		if len(a.RootCauses) > 0 {
+			top := a.RootCauses[0]
+			if top.Service != "" {
+				anomaly.ServiceName = top.Service
+			}
+			anomaly.Region = top.Region
+			anomaly.UsageType = top.UsageType
+		}
+		anomalies[i] = anomaly
+	}
+
+	if err := s.db.SaveAnomalies(anomalies); err != nil {
+		return nil, fmt.Errorf("failed to save anomalies: %w", err)
+	}
+	return anomalies, nil
+}
+
+// dateOnly trims a timestamp such as 2024-05-01T00:00:00Z to its date.
+func dateOnly(ts string) string {
+	if len(ts) > 10 {
+		return ts[:10]
+	}
+	return ts
+}
@@ -0,0 +1,143 @@
+package cost
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// RemoteBudget is a budget as it exists in a cloud provider's native budgeting service.
+// Notifications is nil when the provider's listing does not include them.
+type RemoteBudget struct {
+	Name          string
+	Amount        float64
+	Notifications *BudgetNotifications
+}
+
+// BudgetNotifications is when a budget notifies, in percent of its amount, and whom:
+// email addresses, action groups or topics, and roles.
+type BudgetNotifications struct {
+	Thresholds []float64 `json:"thresholds"`
+	Contacts   []string  `json:"contacts"`
+}
+
+type DriftKind string
+
+const (
+	DriftMissingRemote DriftKind = "missing_remote"
+	DriftMissingLocal  DriftKind = "missing_local"
+	DriftAmount        DriftKind = "amount_mismatch"
+	DriftNotifications DriftKind = "notification_mismatch"
+	DriftDisabled      DriftKind = "disabled_locally"
+)
+
+type BudgetDrift struct {
+	Name                string               `json:"name"`
+	Kind                DriftKind            `json:"kind"`
+	LocalAmount         float64              `json:"local_amount"`
+	RemoteAmount        float64              `json:"remote_amount"`
+	LocalNotifications  *BudgetNotifications `json:"local_notifications,omitempty"`
+	RemoteNotifications *BudgetNotifications `json:"remote_notifications,omitempty"`
+}
+
+// AmountsDiffer reports whether two budget amounts differ by a cent or more.
+func AmountsDiffer(a, b float64) bool {
+	return math.Abs(a-b) >= 0.005
+}
+
+// DiffBudgets compares local budget alerts with the provider's budgets by name.
+// Disabled local alerts are only reported when the provider still has an active budget for them.
+// When want is set, the notifications of remote budgets that list them are compared with it too.
+func DiffBudgets(local []storage.Alert, remote []RemoteBudget, want *BudgetNotifications) []BudgetDrift {
+	remoteByName := make(map[string]RemoteBudget, len(remote))
+	for _, r := range remote {
+		remoteByName[r.Name] = r
+	}
+
+	var drift []BudgetDrift
+	seen := make(map[string]bool, len(local))
+	for _, a := range local {
+		seen[a.Name] = true
+		r, ok := remoteByName[a.Name]
+		switch {
+		case !a.Enabled && ok:
+			drift = append(drift, BudgetDrift{Name: a.Name, Kind: DriftDisabled, LocalAmount: a.Threshold, RemoteAmount: r.Amount})
+			continue
+		case !a.Enabled:
+			continue
+		case !ok:
+			drift = append(drift, BudgetDrift{Name: a.Name, Kind: DriftMissingRemote, LocalAmount: a.Threshold})
+			continue
+		}
+
+		if AmountsDiffer(a.Threshold, r.Amount) {
+			drift = append(drift, BudgetDrift{Name: a.Name, Kind: DriftAmount, LocalAmount: a.Threshold, RemoteAmount: r.Amount})
+		}
+		if want != nil && r.Notifications != nil && !sameNotifications(*want, *r.Notifications) {
+			drift = append(drift, BudgetDrift{
+				Name:                a.Name,
+				Kind:                DriftNotifications,
+				LocalAmount:         a.Threshold,
+				RemoteAmount:        r.Amount,
+				LocalNotifications:  want,
+				RemoteNotifications: r.Notifications,
+			})
+		}
+	}
+
+	for _, r := range remote {
+		if !seen[r.Name] {
+			drift = append(drift, BudgetDrift{Name: r.Name, Kind: DriftMissingLocal, RemoteAmount: r.Amount})
+		}
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool { return drift[i].Name < drift[j].Name })
+	return drift
+}
+
+// sameNotifications compares thresholds to the hundredth of a percent and
+// contacts case-insensitively, ignoring order and duplicates.
+func sameNotifications(a, b BudgetNotifications) bool {
+	at, bt := sortedThresholds(a.Thresholds), sortedThresholds(b.Thresholds)
+	if len(at) != len(bt) {
+		return false
+	}
+	for i := range at {
+		if AmountsDiffer(at[i], bt[i]) {
+			return false
+		}
+	}
+
+	ac, bc := contactSet(a.Contacts), contactSet(b.Contacts)
+	if len(ac) != len(bc) {
+		return false
+	}
+	for c := range ac {
+		if !bc[c] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedThresholds(thresholds []float64) []float64 {
+	sorted := append([]float64(nil), thresholds...)
+	sort.Float64s(sorted)
+	var unique []float64
+	for _, t := range sorted {
+		if len(unique) == 0 || AmountsDiffer(unique[len(unique)-1], t) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+func contactSet(contacts []string) map[string]bool {
+	set := make(map[string]bool, len(contacts))
+	for _, c := range contacts {
+		set[strings.ToLower(strings.TrimSpace(c))] = true
+	}
+	return set
+}
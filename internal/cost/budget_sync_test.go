@@ -0,0 +1,117 @@
+package cost
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestDiffBudgets(t *testing.T) {
+	want := &BudgetNotifications{Thresholds: []float64{80, 100}, Contacts: []string{"me@example.com"}}
+
+	tests := []struct {
+		name   string
+		local  []storage.Alert
+		remote []RemoteBudget
+		want   *BudgetNotifications
+		kinds  []DriftKind
+	}{
+		{
+			name:   "in sync",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10}},
+		},
+		{
+			name:   "amount within a cent",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10.004}},
+		},
+		{
+			name:   "amount changed",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 12}},
+			kinds:  []DriftKind{DriftAmount},
+		},
+		{
+			name:   "missing remote",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: nil,
+			kinds:  []DriftKind{DriftMissingRemote},
+		},
+		{
+			name:   "missing local",
+			remote: []RemoteBudget{{Name: "team", Amount: 50}},
+			kinds:  []DriftKind{DriftMissingLocal},
+		},
+		{
+			name:   "disabled locally but remote",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10}},
+			kinds:  []DriftKind{DriftDisabled},
+		},
+		{
+			name:  "disabled locally and not remote",
+			local: []storage.Alert{{Name: "budget-10", Threshold: 10}},
+		},
+		{
+			name:   "same notifications in another order",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10, Notifications: &BudgetNotifications{Thresholds: []float64{100, 80}, Contacts: []string{"Me@Example.com"}}}},
+			want:   want,
+		},
+		{
+			name:   "threshold changed",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10, Notifications: &BudgetNotifications{Thresholds: []float64{50, 100}, Contacts: []string{"me@example.com"}}}},
+			want:   want,
+			kinds:  []DriftKind{DriftNotifications},
+		},
+		{
+			name:   "contact changed and amount changed",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 20, Notifications: &BudgetNotifications{Thresholds: []float64{80, 100}, Contacts: []string{"other@example.com"}}}},
+			want:   want,
+			kinds:  []DriftKind{DriftAmount, DriftNotifications},
+		},
+		{
+			name:   "notifications not listed",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10}},
+			want:   want,
+		},
+		{
+			name:   "notifications not compared",
+			local:  []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}},
+			remote: []RemoteBudget{{Name: "budget-10", Amount: 10, Notifications: &BudgetNotifications{Thresholds: []float64{50}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kinds []DriftKind
+			for _, d := range DiffBudgets(tt.local, tt.remote, tt.want) {
+				kinds = append(kinds, d.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("DiffBudgets() kinds = %v, want %v", kinds, tt.kinds)
+			}
+		})
+	}
+}
+
+func TestDiffBudgetsSortsByName(t *testing.T) {
+	local := []storage.Alert{
+		{Name: "zeta", Threshold: 1, Enabled: true},
+		{Name: "alpha", Threshold: 1, Enabled: true},
+	}
+	remote := []RemoteBudget{{Name: "mid", Amount: 1}}
+
+	var names []string
+	for _, d := range DiffBudgets(local, remote, nil) {
+		names = append(names, d.Name)
+	}
+	if want := []string{"alpha", "mid", "zeta"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,215 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// PriceCacheTTL is how long cached retail prices are used before refreshing.
+const PriceCacheTTL = 7 * 24 * time.Hour
+
+// Estimate is the projected monthly cost of a resource after the free allowance.
+type Estimate struct {
+	Resource      string  `json:"resource"`
+	SKU           string  `json:"sku"`
+	Region        string  `json:"region"`
+	Meter         string  `json:"meter"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit"`
+	UnitPrice     float64 `json:"unit_price"`
+	FreeAllowance float64 `json:"free_allowance"`
+	FreeService   string  `json:"free_service,omitempty"`
+	Billable      float64 `json:"billable"`
+	GrossCost     float64 `json:"gross_cost"`
+	MonthlyCost   float64 `json:"monthly_cost"`
+	Currency      string  `json:"currency"`
+	PriceSource   string  `json:"price_source"`
+}
+
+// Estimator prices resources with the Azure Retail Prices API, caching
+// responses in the local database so estimates also work offline.
+type Estimator struct {
+	db      *storage.DB
+	prices  *azure.PricesClient
+	Offline bool
+}
+
+func NewEstimator(db *storage.DB, prices *azure.PricesClient) *Estimator {
+	return &Estimator{db: db, prices: prices}
+}
+
+// EstimateVM prices a pay-as-you-go virtual machine for the given hours per month.
+func (e *Estimator) EstimateVM(ctx context.Context, sku, region, os string, hours float64) (*Estimate, error) {
+	filter := fmt.Sprintf("serviceName eq 'Virtual Machines' and armSkuName eq %s and armRegionName eq %s and priceType eq 'Consumption'",
+		azure.QuoteFilterValue(sku), azure.QuoteFilterValue(region))
+	price, source, err := e.findPrice(ctx, filter, func(p azure.RetailPrice) bool {
+		if isSpotOrLowPriority(p) || p.UnitOfMeasure != "1 Hour" {
+			return false
+		}
+		return strings.Contains(p.ProductName, "Windows") == strings.EqualFold(os, "windows")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.build("Virtual Machine", sku, region, hours, "hours", "virtual_machines", sku, price, source)
+}
+
+// EstimateDisk prices managed disks of a given performance tier, e.g. P6, E10 or S4.
+func (e *Estimator) EstimateDisk(ctx context.Context, sku, region, redundancy string, count float64) (*Estimate, error) {
+	skuName := fmt.Sprintf("%s %s", strings.ToUpper(sku), strings.ToUpper(redundancy))
+	filter := fmt.Sprintf("serviceName eq 'Storage' and skuName eq %s and armRegionName eq %s and priceType eq 'Consumption'",
+		azure.QuoteFilterValue(skuName), azure.QuoteFilterValue(region))
+	price, source, err := e.findPrice(ctx, filter, func(p azure.RetailPrice) bool {
+		return p.MeterName == skuName+" Disk" && p.UnitOfMeasure == "1/Month"
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.build("Managed Disk", strings.ToUpper(sku), region, count, "disks", "managed_disks", strings.ToUpper(sku), price, source)
+}
+
+// EstimateStorage prices block blob capacity for a tier (hot, cool, archive) and redundancy.
+func (e *Estimator) EstimateStorage(ctx context.Context, tier, redundancy, region string, gb float64) (*Estimate, error) {
+	skuName := fmt.Sprintf("%s %s", titleCase(tier), strings.ToUpper(redundancy))
+	filter := fmt.Sprintf("serviceName eq 'Storage' and skuName eq %s and armRegionName eq %s and priceType eq 'Consumption'",
+		azure.QuoteFilterValue(skuName), azure.QuoteFilterValue(region))
+	price, source, err := e.findPrice(ctx, filter, func(p azure.RetailPrice) bool {
+		return p.MeterName == skuName+" Data Stored" &&
+			strings.Contains(p.ProductName, "Blob Storage") &&
+			p.TierMinimumUnits == 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.build("Blob Storage", skuName, region, gb, "GB", "blob_storage", skuName, price, source)
+}
+
+// EstimateAppService prices an App Service plan instance for the given hours per month.
+func (e *Estimator) EstimateAppService(ctx context.Context, sku, region, os string, hours float64) (*Estimate, error) {
+	sku = strings.ToUpper(sku)
+	filter := fmt.Sprintf("serviceName eq 'Azure App Service' and skuName eq %s and armRegionName eq %s and priceType eq 'Consumption'",
+		azure.QuoteFilterValue(sku), azure.QuoteFilterValue(region))
+	price, source, err := e.findPrice(ctx, filter, func(p azure.RetailPrice) bool {
+		return p.UnitOfMeasure == "1 Hour" &&
+			strings.Contains(p.ProductName, "Linux") == strings.EqualFold(os, "linux")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.build("App Service Plan", sku, region, hours, "hours", "app_service", sku, price, source)
+}
+
+func (e *Estimator) build(resource, sku, region string, quantity float64, unit, freeService, freeSKU string, price azure.RetailPrice, source string) (*Estimate, error) {
+	est := &Estimate{
+		Resource:    resource,
+		SKU:         sku,
+		Region:      region,
+		Meter:       price.MeterName,
+		Quantity:    quantity,
+		Unit:        unit,
+		UnitPrice:   price.RetailPrice,
+		Currency:    price.CurrencyCode,
+		PriceSource: source,
+	}
+
+	freeTier, err := LoadFreeTierConfig()
+	if err != nil {
+		return nil, err
+	}
+	if limit, ok := freeTier.Services[freeService]; ok && limitCoversSKU(limit, freeSKU) {
+		est.FreeService = freeService
+		est.FreeAllowance = math.Min(quantity, limit.Limit)
+	}
+
+	est.Billable = quantity - est.FreeAllowance
+	est.GrossCost = round2(quantity * price.RetailPrice)
+	est.MonthlyCost = round2(est.Billable * price.RetailPrice)
+	return est, nil
+}
+
+// findPrice returns the first price matching the filter and predicate, preferring
+// a fresh cache entry, then the API, then a stale cache entry.
+func (e *Estimator) findPrice(ctx context.Context, filter string, match func(azure.RetailPrice) bool) (azure.RetailPrice, string, error) {
+	prices, source, err := e.lookup(ctx, filter)
+	if err != nil {
+		return azure.RetailPrice{}, "", err
+	}
+
+	for _, p := range prices {
+		if match(p) {
+			return p, source, nil
+		}
+	}
+	return azure.RetailPrice{}, "", fmt.Errorf("no retail price found for: %s", filter)
+}
+
+func (e *Estimator) lookup(ctx context.Context, filter string) ([]azure.RetailPrice, string, error) {
+	var cached []azure.RetailPrice
+	data, fetchedAt, err := e.db.GetCachedPrices(filter)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read price cache: %w", err)
+	}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &cached); err != nil {
+			cached = nil
+		}
+	}
+
+	if cached != nil && (e.Offline || time.Since(fetchedAt) < PriceCacheTTL) {
+		return cached, "cache (" + fetchedAt.Format("2006-01-02") + ")", nil
+	}
+	if e.Offline {
+		return nil, "", fmt.Errorf("no cached price for this query; run once without --offline")
+	}
+
+	prices, err := e.prices.Query(ctx, filter)
+	if err != nil {
+		if cached != nil {
+			return cached, "stale cache (" + fetchedAt.Format("2006-01-02") + ")", nil
+		}
+		return nil, "", fmt.Errorf("failed to query retail prices: %w", err)
+	}
+
+	if b, err := json.Marshal(prices); err == nil {
+		_ = e.db.SaveCachedPrices(filter, string(b))
+	}
+	return prices, "live", nil
+}
+
+func limitCoversSKU(limit ServiceLimit, sku string) bool {
+	if len(limit.SKUs) == 0 {
+		return true
+	}
+	for _, s := range limit.SKUs {
+		if strings.EqualFold(s, sku) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSpotOrLowPriority(p azure.RetailPrice) bool {
+	return strings.Contains(p.SkuName, "Spot") || strings.Contains(p.SkuName, "Low Priority")
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
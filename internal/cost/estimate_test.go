@@ -0,0 +1,49 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestEstimateVMEscapesFilterValues(t *testing.T) {
+	var filter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("$filter")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"Items": []azure.RetailPrice{{
+				CurrencyCode:  "USD",
+				RetailPrice:   0.01,
+				MeterName:     "B1s",
+				ProductName:   "Virtual Machines BS Series",
+				UnitOfMeasure: "1 Hour",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	prices := azure.NewPricesClient()
+	prices.BaseURL = server.URL
+	e := NewEstimator(db, prices)
+
+	if _, err := e.EstimateVM(context.Background(), "Standard_B1s' or serviceName eq 'Storage", "eastus", "linux", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "serviceName eq 'Virtual Machines' and armSkuName eq 'Standard_B1s'' or serviceName eq ''Storage' and armRegionName eq 'eastus' and priceType eq 'Consumption'"
+	if filter != want {
+		t.Errorf("filter = %s\nwant      %s", filter, want)
+	}
+}
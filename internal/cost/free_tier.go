@@ -1,8 +1,12 @@
 package cost
 
 import (
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,12 +16,23 @@ type FreeTierConfig struct {
 	Budgets map[string]BudgetPreset `yaml:"budgets"`
 }
 
+// ServiceLimit is one free tier allowance. For AWS, Services lists the
+// service names the Free Tier API reports and UsageTypes holds glob patterns
+// (e.g. "BoxUsage:t2.micro", "EBS:VolumeUsage*") matched against usage types
+// with or without their region prefix. For GCP they match the service and
+// SKU descriptions of the billing export, while SKUs and Regions say which
+// machine types, disk types or storage classes, and where, are covered.
 type ServiceLimit struct {
-	Description      string  `yaml:"description"`
-	Limit            float64 `yaml:"limit"`
-	Unit             string  `yaml:"unit"`
-	Duration         string  `yaml:"duration"`
-	WarningThreshold float64 `yaml:"warning_threshold"`
+	Name             string   `yaml:"name"`
+	Description      string   `yaml:"description"`
+	Limit            float64  `yaml:"limit"`
+	Unit             string   `yaml:"unit"`
+	Duration         string   `yaml:"duration"`
+	WarningThreshold float64  `yaml:"warning_threshold"`
+	SKUs             []string `yaml:"skus"`
+	Services         []string `yaml:"services"`
+	UsageTypes       []string `yaml:"usage_types"`
+	Regions          []string `yaml:"regions"`
 }
 
 type BudgetPreset struct {
@@ -25,26 +40,155 @@ type BudgetPreset struct {
 	Description string  `yaml:"description"`
 }
 
+// DefaultWarningThreshold applies to services without a warning_threshold.
+const DefaultWarningThreshold = 0.8
+
+// catalogFiles maps a provider to its limits file in configs/ or ~/.azguard/.
+var catalogFiles = map[string]string{
+	"azure": "free_tier_limits.yaml",
+	"aws":   "aws_free_tier_limits.yaml",
+	"gcp":   "gcp_free_tier_limits.yaml",
+}
+
+// LoadFreeTierConfig loads the Azure free tier limits.
 func LoadFreeTierConfig() (*FreeTierConfig, error) {
-	paths := []string{
-		"configs/free_tier_limits.yaml",
-		"./configs/free_tier_limits.yaml",
-		filepath.Join(func() string { h, _ := os.UserHomeDir(); return h }(), ".azguard", "free_tier_limits.yaml"),
+	return LoadFreeTierCatalog("azure")
+}
+
+// LoadFreeTierCatalog loads the free tier limits for a provider, falling back
+// to built-in defaults when no limits file is found.
+func LoadFreeTierCatalog(provider string) (*FreeTierConfig, error) {
+	file, ok := catalogFiles[provider]
+	if !ok {
+		return nil, fmt.Errorf("no free tier catalog for provider %q", provider)
 	}
 
-	var config *FreeTierConfig
+	paths := []string{
+		filepath.Join("configs", file),
+		filepath.Join(".", "configs", file),
+		filepath.Join(func() string { h, _ := os.UserHomeDir(); return h }(), ".azguard", file),
+	}
 
-	for _, path := range paths {
-		data, err := os.ReadFile(path)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
 		if err == nil {
-			config = &FreeTierConfig{}
+			config := &FreeTierConfig{}
 			if err := yaml.Unmarshal(data, config); err == nil {
 				return config, nil
 			}
 		}
 	}
 
-	// Return default config if no file found
+	switch provider {
+	case "aws":
+		return defaultAWSCatalog(), nil
+	case "gcp":
+		return defaultGCPCatalog(), nil
+	}
+	return defaultAzureCatalog(), nil
+}
+
+// Keys returns the service keys in sorted order.
+func (c *FreeTierConfig) Keys() []string {
+	keys := make([]string, 0, len(c.Services))
+	for k := range c.Services {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MatchUsage finds the allowance for a Free Tier API row. A usage type rule
+// wins, as long as the entry's services (if any) include the reported service;
+// otherwise the first entry listing the service is used.
+func (c *FreeTierConfig) MatchUsage(service, usageType string) (string, *ServiceLimit) {
+	if k, limit := c.MatchUsageType(service, usageType); limit != nil {
+		return k, limit
+	}
+
+	for _, k := range c.Keys() {
+		limit := c.Services[k]
+		if containsFold(limit.Services, service) {
+			return k, &limit
+		}
+	}
+	return "", nil
+}
+
+// MatchUsageType is MatchUsage without the service name fallback, for
+// providers such as GCP where one service has many unrelated SKUs.
+func (c *FreeTierConfig) MatchUsageType(service, usageType string) (string, *ServiceLimit) {
+	bare := stripRegionPrefix(usageType)
+	for _, k := range c.Keys() {
+		limit := c.Services[k]
+		if len(limit.Services) > 0 && !containsFold(limit.Services, service) {
+			continue
+		}
+		for _, pattern := range limit.UsageTypes {
+			if ok, _ := path.Match(pattern, usageType); ok {
+				return k, &limit
+			}
+			if ok, _ := path.Match(pattern, bare); ok {
+				return k, &limit
+			}
+		}
+	}
+	return "", nil
+}
+
+// MatchResource checks a resource of the given service and SKU (e.g. a
+// machine type or storage class) in region against the allowances that list
+// the SKU. It returns the key of a covering allowance, or why none covers it.
+func (c *FreeTierConfig) MatchResource(service, sku, region string) (string, *ServiceLimit, string) {
+	var regions []string
+	for _, k := range c.Keys() {
+		limit := c.Services[k]
+		if !containsFold(limit.Services, service) || !containsFold(limit.SKUs, sku) {
+			continue
+		}
+		if len(limit.Regions) == 0 || containsFold(limit.Regions, region) {
+			return k, &limit, ""
+		}
+		regions = limit.Regions
+	}
+	if regions != nil {
+		return "", nil, fmt.Sprintf("%s is only free in %s", sku, strings.Join(regions, ", "))
+	}
+	return "", nil, fmt.Sprintf("%s is not covered by the free tier", sku)
+}
+
+// Threshold returns the warning threshold as a fraction, defaulting to 0.8.
+func (l *ServiceLimit) Threshold() float64 {
+	if l == nil || l.WarningThreshold <= 0 {
+		return DefaultWarningThreshold
+	}
+	return l.WarningThreshold
+}
+
+// stripRegionPrefix drops the billing region code, e.g. "USE2-" or "EU-", from a usage type.
+func stripRegionPrefix(usageType string) string {
+	prefix, rest, ok := strings.Cut(usageType, "-")
+	if !ok || len(prefix) < 2 || len(prefix) > 5 {
+		return usageType
+	}
+	for _, r := range prefix {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return usageType
+		}
+	}
+	return rest
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultAzureCatalog() *FreeTierConfig {
 	return &FreeTierConfig{
 		Services: map[string]ServiceLimit{
 			"virtual_machines": {
@@ -53,6 +197,7 @@ func LoadFreeTierConfig() (*FreeTierConfig, error) {
 				Unit:             "hours",
 				Duration:         "12 months",
 				WarningThreshold: 0.8,
+				SKUs:             []string{"Standard_B1s", "Standard_B2pts_v2", "Standard_B2ats_v2"},
 			},
 			"blob_storage": {
 				Description:      "Hot Blob Storage",
@@ -60,6 +205,7 @@ func LoadFreeTierConfig() (*FreeTierConfig, error) {
 				Unit:             "GB",
 				Duration:         "always free",
 				WarningThreshold: 0.8,
+				SKUs:             []string{"Hot LRS"},
 			},
 			"functions": {
 				Description:      "Azure Functions",
@@ -69,13 +215,116 @@ func LoadFreeTierConfig() (*FreeTierConfig, error) {
 				WarningThreshold: 0.8,
 			},
 		},
-		Budgets: map[string]BudgetPreset{
-			"tiny":     {Amount: 1, Description: "Strict budget"},
-			"small":    {Amount: 5, Description: "Small budget"},
-			"medium":   {Amount: 10, Description: "Medium budget"},
-			"moderate": {Amount: 20, Description: "Higher budget"},
+		Budgets: defaultBudgetPresets(),
+	}
+}
+
+func defaultAWSCatalog() *FreeTierConfig {
+	service := func(name, description string, limit float64, unit, duration string, services, usageTypes []string) ServiceLimit {
+		return ServiceLimit{
+			Name:             name,
+			Description:      description,
+			Limit:            limit,
+			Unit:             unit,
+			Duration:         duration,
+			WarningThreshold: DefaultWarningThreshold,
+			Services:         services,
+			UsageTypes:       usageTypes,
+		}
+	}
+	return &FreeTierConfig{
+		Services: map[string]ServiceLimit{
+			"ec2": service("Amazon EC2", "Linux t2.micro or t3.micro instance", 750, "hours/month", "12 months",
+				[]string{"Amazon Elastic Compute Cloud"}, []string{"BoxUsage:freetier.micro", "BoxUsage:t2.micro", "BoxUsage:t3.micro"}),
+			"ebs": service("Amazon EBS", "General Purpose SSD storage", 30, "GB/month", "12 months",
+				nil, []string{"EBS:VolumeUsage", "EBS:VolumeUsage.gp2", "EBS:VolumeUsage.gp3"}),
+			"s3": service("Amazon S3", "Standard storage", 5, "GB", "12 months",
+				[]string{"Amazon Simple Storage Service"}, []string{"TimedStorage-ByteHrs", "Requests-Tier1", "Requests-Tier2"}),
+			"lambda": service("AWS Lambda", "AWS Lambda requests", 1000000, "requests/month", "always free",
+				[]string{"AWS Lambda"}, []string{"Request", "Lambda-GB-Second*"}),
+			"rds": service("Amazon RDS", "db.t2.micro or db.t3.micro Single-AZ", 750, "hours/month", "12 months",
+				[]string{"Amazon Relational Database Service"}, []string{"InstanceUsage:db.t2.micro", "InstanceUsage:db.t3.micro", "InstanceUsage:db.t4g.micro"}),
+			"dynamodb": service("Amazon DynamoDB", "DynamoDB storage and throughput", 25, "GB", "always free",
+				[]string{"Amazon DynamoDB"}, nil),
+			"cloudfront": service("Amazon CloudFront", "Data transfer out", 1, "TB/month", "always free",
+				[]string{"Amazon CloudFront"}, nil),
+			"sns": service("Amazon SNS", "SNS publishes", 1000000, "publishes/month", "always free",
+				[]string{"Amazon Simple Notification Service"}, nil),
+			"sqs": service("Amazon SQS", "SQS requests", 1000000, "requests/month", "always free",
+				[]string{"Amazon Simple Queue Service"}, nil),
+			"cloudwatch": service("Amazon CloudWatch", "CloudWatch metrics and alarms", 10, "custom metrics", "always free",
+				[]string{"AmazonCloudWatch"}, nil),
+			"api_gateway": service("Amazon API Gateway", "REST API calls", 1000000, "calls/month", "12 months",
+				[]string{"Amazon API Gateway"}, nil),
+			"cognito": service("Amazon Cognito", "Monthly active users", 50000, "MAUs", "always free",
+				[]string{"Amazon Cognito"}, nil),
+			"elb": service("Elastic Load Balancing", "Application Load Balancer", 750, "hours/month", "12 months",
+				nil, []string{"LoadBalancerUsage", "LCUUsage"}),
+		},
+		Budgets: defaultBudgetPresets(),
+	}
+}
+
+func defaultGCPCatalog() *FreeTierConfig {
+	service := func(name, description string, limit float64, unit string, services, skus []string) ServiceLimit {
+		return ServiceLimit{
+			Name:             name,
+			Description:      description,
+			Limit:            limit,
+			Unit:             unit,
+			Duration:         "always free",
+			WarningThreshold: DefaultWarningThreshold,
+			Services:         services,
+			UsageTypes:       skus,
+		}
+	}
+	// only restricts an allowance to resources of these SKUs in the
+	// always-free regions.
+	only := func(l ServiceLimit, skus ...string) ServiceLimit {
+		l.SKUs = skus
+		l.Regions = []string{"us-west1", "us-central1", "us-east1"}
+		return l
+	}
+	compute := []string{"Compute Engine"}
+	storage := []string{"Cloud Storage"}
+	return &FreeTierConfig{
+		Services: map[string]ServiceLimit{
+			"e2_micro_core": only(service("e2-micro instance (vCPU)", "One e2-micro in us-west1, us-central1 or us-east1: 720 hours at 0.25 vCPU", 180, "hour",
+				compute, []string{"E2 Instance Core running in Americas"}), "e2-micro"),
+			"e2_micro_ram": only(service("e2-micro instance (memory)", "One e2-micro in us-west1, us-central1 or us-east1: 720 hours at 1 GiB", 720, "gibibyte hour",
+				compute, []string{"E2 Instance Ram running in Americas"}), "e2-micro"),
+			"pd_standard": only(service("Standard persistent disk", "Standard persistent disk in the e2-micro regions", 30, "gibibyte month",
+				compute, []string{"Storage PD Capacity"}), "pd-standard"),
+			"pd_snapshot": service("Persistent disk snapshots", "Snapshot storage in the e2-micro regions", 5, "gibibyte month",
+				compute, []string{"Storage PD Snapshot*"}),
+			"egress": service("Network egress", "Egress from North America to all regions but China and Australia", 1, "gibibyte",
+				compute, []string{"Network Internet Egress from Americas to*"}),
+			"gcs_storage": only(service("Cloud Storage", "Standard storage in us-west1, us-central1 or us-east1", 5, "gibibyte month",
+				storage, []string{"Standard Storage US Regional", "Regional Standard storage*"}), "STANDARD"),
+			"gcs_class_a": service("Cloud Storage Class A operations", "Class A operations on Standard storage", 5000, "count",
+				storage, []string{"*Class A Operations*"}),
+			"gcs_class_b": service("Cloud Storage Class B operations", "Class B operations on Standard storage", 50000, "count",
+				storage, []string{"*Class B Operations*"}),
+			"bigquery_analysis": service("BigQuery queries", "Query data processed", 1, "tebibyte",
+				[]string{"BigQuery"}, []string{"Analysis*"}),
+			"bigquery_storage": service("BigQuery storage", "Active logical storage", 10, "gibibyte month",
+				[]string{"BigQuery"}, []string{"Active Logical Storage*", "Active Storage*"}),
+			"cloud_run_requests": service("Cloud Run requests", "Requests", 2000000, "count",
+				[]string{"Cloud Run"}, []string{"Requests*"}),
+			"cloud_functions": service("Cloud Functions invocations", "Invocations", 2000000, "count",
+				[]string{"Cloud Functions"}, []string{"Invocations*"}),
 		},
-	}, nil
+		Budgets: defaultBudgetPresets(),
+	}
+}
+
+func defaultBudgetPresets() map[string]BudgetPreset {
+	return map[string]BudgetPreset{
+		"tiny":     {Amount: 1, Description: "Strict budget"},
+		"small":    {Amount: 5, Description: "Small budget"},
+		"medium":   {Amount: 10, Description: "Medium budget"},
+		"moderate": {Amount: 20, Description: "Higher budget"},
+	}
 }
 
 type ResourceStatus string
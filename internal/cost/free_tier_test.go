@@ -0,0 +1,141 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isolateCatalogs runs the test where no limits file is found, so the
+// built-in catalogs are used, and returns the ~/.azguard directory.
+func isolateCatalogs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	t.Setenv("HOME", dir)
+
+	home := filepath.Join(dir, ".azguard")
+	if err := os.MkdirAll(home, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func TestLoadFreeTierCatalog(t *testing.T) {
+	home := isolateCatalogs(t)
+
+	for provider, key := range map[string]string{"azure": "virtual_machines", "aws": "ec2", "gcp": "e2_micro_core"} {
+		catalog, err := LoadFreeTierCatalog(provider)
+		if err != nil {
+			t.Fatalf("LoadFreeTierCatalog(%s) error = %v", provider, err)
+		}
+		if _, ok := catalog.Services[key]; !ok {
+			t.Errorf("built-in %s catalog has no %s", provider, key)
+		}
+	}
+	if _, err := LoadFreeTierCatalog("oracle"); err == nil {
+		t.Error("LoadFreeTierCatalog(oracle) succeeded, want an error")
+	}
+
+	// A limits file in ~/.azguard replaces the built-in catalog; an
+	// unparseable one is ignored.
+	file := filepath.Join(home, "aws_free_tier_limits.yaml")
+	if err := os.WriteFile(file, []byte("services:\n  ec2:\n    name: Amazon EC2\n    limit: 100\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := LoadFreeTierCatalog("aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Services) != 1 || catalog.Services["ec2"].Limit != 100 {
+		t.Errorf("catalog = %+v, want the ~/.azguard file", catalog.Services)
+	}
+	if err := os.WriteFile(file, []byte("services: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if catalog, err = LoadFreeTierCatalog("aws"); err != nil || catalog.Services["ec2"].Limit != 750 {
+		t.Errorf("catalog = %+v, %v; want the built-in one", catalog, err)
+	}
+}
+
+func TestBundledCatalogsParse(t *testing.T) {
+	for provider, file := range catalogFiles {
+		data, err := os.ReadFile(filepath.Join("..", "..", "configs", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := &FreeTierConfig{}
+		if err := yaml.Unmarshal(data, config); err != nil || len(config.Services) == 0 {
+			t.Errorf("configs/%s (%s) = %d services, %v", file, provider, len(config.Services), err)
+		}
+	}
+}
+
+func TestMatchUsage(t *testing.T) {
+	aws := defaultAWSCatalog()
+	gcp := defaultGCPCatalog()
+
+	tests := []struct {
+		name      string
+		catalog   *FreeTierConfig
+		service   string
+		usageType string
+		want      string
+	}{
+		{"usage type", aws, "Amazon Elastic Compute Cloud", "BoxUsage:t2.micro", "ec2"},
+		{"usage type with region prefix", aws, "Amazon Elastic Compute Cloud", "USE2-BoxUsage:t3.micro", "ec2"},
+		{"usage type glob", aws, "AWS Lambda", "EUC1-Lambda-GB-Second-ARM", "lambda"},
+		{"any service for a rule without services", aws, "EC2 - Other", "EBS:VolumeUsage.gp3", "ebs"},
+		{"usage type of another service", aws, "Amazon Simple Storage Service", "BoxUsage:t2.micro", "s3"},
+		{"service fallback", aws, "Amazon DynamoDB", "TimedStorage-ByteHrs", "dynamodb"},
+		{"service is case-insensitive", aws, "amazon simple notification service", "Requests", "sns"},
+		{"other usage type falls back to the service", aws, "Amazon Elastic Compute Cloud", "BoxUsage:m5.large", "ec2"},
+		{"unknown service", aws, "Amazon Redshift", "Node:dc2.large", ""},
+		{"gcp sku", gcp, "Compute Engine", "E2 Instance Ram running in Americas", "e2_micro_ram"},
+		{"gcp sku glob", gcp, "Compute Engine", "Network Internet Egress from Americas to EMEA", "egress"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, limit := tt.catalog.MatchUsage(tt.service, tt.usageType)
+			if key != tt.want || (limit == nil) != (tt.want == "") {
+				t.Errorf("MatchUsage(%q, %q) = %q, %v; want %q", tt.service, tt.usageType, key, limit, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchUsageTypeHasNoServiceFallback(t *testing.T) {
+	// Compute Engine has many SKUs; an uncovered one matches nothing rather
+	// than the first Compute Engine allowance.
+	key, limit := defaultGCPCatalog().MatchUsageType("Compute Engine", "N2 Instance Core running in Americas")
+	if key != "" || limit != nil {
+		t.Errorf("MatchUsageType() = %q, %+v; want no match", key, limit)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit *ServiceLimit
+		want  float64
+	}{
+		{"nil limit", nil, DefaultWarningThreshold},
+		{"unset", &ServiceLimit{}, DefaultWarningThreshold},
+		{"negative", &ServiceLimit{WarningThreshold: -1}, DefaultWarningThreshold},
+		{"set", &ServiceLimit{WarningThreshold: 0.9}, 0.9},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.Threshold(); got != tt.want {
+			t.Errorf("%s: Threshold() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package cost
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	gcpcloud "github.com/azguard/azguard/internal/cloud/gcp"
+)
+
+// CheckGCPFreeTier matches month-to-date SKU usage against the catalog. Only
+// allowances with usage are returned, in catalog key order. The forecast
+// extends the usage at the same daily rate to the end of the month.
+func CheckGCPFreeTier(catalog *FreeTierConfig, usages []gcpcloud.SKUUsage, now time.Time) []cloud.FreeTierUsage {
+	byKey := make(map[string]*cloud.FreeTierUsage)
+	for _, u := range usages {
+		key, limit := catalog.MatchUsageType(u.ServiceName, u.SKU)
+		if limit == nil || limit.Limit <= 0 {
+			continue
+		}
+		entry, ok := byKey[key]
+		if !ok {
+			name := limit.Name
+			if name == "" {
+				name = key
+			}
+			entry = &cloud.FreeTierUsage{
+				Key:         key,
+				Name:        name,
+				Description: limit.Description,
+				Limit:       limit.Limit,
+				Unit:        limit.Unit,
+				Threshold:   limit.Threshold(),
+			}
+			byKey[key] = entry
+		}
+		entry.Used += u.Amount
+	}
+
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	var result []cloud.FreeTierUsage
+	for _, key := range catalog.Keys() {
+		entry, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		entry.Forecast = entry.Used / float64(now.Day()) * float64(daysInMonth)
+		entry.PercentUsed = entry.Used / entry.Limit * 100
+		result = append(result, *entry)
+	}
+	return result
+}
+
+// CheckGCPResources flags each resource of an inventory as inside or outside
+// the always-free envelope, using the SKU and region rules of the catalog,
+// and adds warnings for allowances the resources exceed together.
+func CheckGCPResources(catalog *FreeTierConfig, inv *gcpcloud.Inventory) {
+	freeInstances := make(map[string]bool)
+	running := 0
+	for i := range inv.Resources {
+		r := &inv.Resources[i]
+		if r.Type != gcpcloud.ResourceInstance {
+			continue
+		}
+		_, _, reason := catalog.MatchResource("Compute Engine", r.SKU, r.Region)
+		if reason == "" && r.Spot {
+			reason = "spot and preemptible VMs are not covered by the free tier"
+		}
+		r.FreeTier = reason == ""
+		r.Note = reason
+		if r.Status != "RUNNING" {
+			r.Note = strings.TrimSuffix("stopped, so its vCPU and memory are not billed; "+reason, "; ")
+		}
+		if r.FreeTier {
+			freeInstances[r.Zone+"/"+r.Name] = true
+			if r.Status == "RUNNING" {
+				running++
+			}
+		}
+	}
+	if running > 1 {
+		inv.Warnings = append(inv.Warnings, fmt.Sprintf("%d e2-micro instances are running; the free tier covers the hours of one", running))
+	}
+
+	diskGB := make(map[string]int)
+	for i := range inv.Resources {
+		r := &inv.Resources[i]
+		switch r.Type {
+		case gcpcloud.ResourceDisk:
+			key, _, reason := catalog.MatchResource("Compute Engine", r.SKU, r.Region)
+			if reason == "" && r.Zone == "" {
+				reason = "regional persistent disks are not covered by the free tier"
+			}
+			r.FreeTier = reason == ""
+			r.Note = reason
+			if r.FreeTier {
+				diskGB[key] += r.SizeGB
+			}
+
+		case gcpcloud.ResourceAddress:
+			switch {
+			case len(r.Users) == 0:
+				r.Note = "reserved but unused; idle static IPs are billed by the hour"
+			case r.Region == "global":
+				r.Note = "global addresses are used by load balancers, which are not in the free tier"
+			case !freeInstances[instanceKey(r.Users[0])]:
+				r.Note = "attached to " + path.Base(r.Users[0]) + ", which is outside the free tier"
+			default:
+				r.FreeTier = true
+			}
+
+		case gcpcloud.ResourceBucket:
+			_, _, reason := catalog.MatchResource("Cloud Storage", r.SKU, r.Region)
+			r.FreeTier = reason == ""
+			r.Note = reason
+		}
+	}
+
+	for _, key := range catalog.Keys() {
+		limit := catalog.Services[key]
+		if gb := diskGB[key]; gb > 0 && float64(gb) > limit.Limit {
+			inv.Warnings = append(inv.Warnings, fmt.Sprintf("%d GB of %s, above the %.0f GB free tier", gb, strings.Join(limit.SKUs, "/"), limit.Limit))
+		}
+	}
+}
+
+// instanceKey turns an instance URL into the zone/name key of its resource.
+func instanceKey(selfLink string) string {
+	parts := strings.Split(selfLink, "/")
+	if len(parts) < 4 || parts[len(parts)-2] != "instances" {
+		return ""
+	}
+	return parts[len(parts)-3] + "/" + parts[len(parts)-1]
+}
@@ -0,0 +1,167 @@
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	gcpcloud "github.com/azguard/azguard/internal/cloud/gcp"
+)
+
+const instanceURL = "https://www.googleapis.com/compute/v1/projects/my-proj/zones/"
+
+func TestCheckGCPResourcesInstances(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance gcpcloud.Resource
+		free     bool
+		note     string
+	}{
+		{"e2-micro in us-west1", gcpcloud.Resource{Region: "us-west1", SKU: "e2-micro", Status: "RUNNING"}, true, ""},
+		{"e2-micro in us-central1", gcpcloud.Resource{Region: "us-central1", SKU: "e2-micro", Status: "RUNNING"}, true, ""},
+		{"e2-micro in us-east1", gcpcloud.Resource{Region: "us-east1", SKU: "e2-micro", Status: "RUNNING"}, true, ""},
+		{"e2-micro elsewhere", gcpcloud.Resource{Region: "europe-west1", SKU: "e2-micro", Status: "RUNNING"}, false, "only free in us-west1, us-central1, us-east1"},
+		{"larger machine type", gcpcloud.Resource{Region: "us-east1", SKU: "e2-small", Status: "RUNNING"}, false, "e2-small is not covered"},
+		{"spot e2-micro", gcpcloud.Resource{Region: "us-east1", SKU: "e2-micro", Status: "RUNNING", Spot: true}, false, "spot and preemptible"},
+		{"stopped e2-small", gcpcloud.Resource{Region: "us-east1", SKU: "e2-small", Status: "TERMINATED"}, false, "stopped, so its vCPU and memory are not billed; e2-small"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.instance
+			r.Type, r.Name, r.Zone = gcpcloud.ResourceInstance, "vm", r.Region+"-b"
+			inv := &gcpcloud.Inventory{Resources: []gcpcloud.Resource{r}}
+			CheckGCPResources(defaultGCPCatalog(), inv)
+
+			got := inv.Resources[0]
+			if got.FreeTier != tt.free || !strings.Contains(got.Note, tt.note) {
+				t.Errorf("free = %v, note %q; want %v, %q", got.FreeTier, got.Note, tt.free, tt.note)
+			}
+		})
+	}
+}
+
+func TestCheckGCPResourcesWarnsAboutSecondInstance(t *testing.T) {
+	inv := &gcpcloud.Inventory{Resources: []gcpcloud.Resource{
+		{Type: gcpcloud.ResourceInstance, Name: "a", Region: "us-west1", Zone: "us-west1-a", SKU: "e2-micro", Status: "RUNNING"},
+		{Type: gcpcloud.ResourceInstance, Name: "b", Region: "us-east1", Zone: "us-east1-b", SKU: "e2-micro", Status: "RUNNING"},
+		{Type: gcpcloud.ResourceInstance, Name: "c", Region: "us-east1", Zone: "us-east1-b", SKU: "e2-micro", Status: "TERMINATED"},
+	}}
+	CheckGCPResources(defaultGCPCatalog(), inv)
+	if len(inv.Warnings) != 1 || !strings.Contains(inv.Warnings[0], "2 e2-micro instances are running") {
+		t.Errorf("warnings = %q, want one about the two running instances", inv.Warnings)
+	}
+}
+
+func TestCheckGCPResourcesDisks(t *testing.T) {
+	inv := &gcpcloud.Inventory{Resources: []gcpcloud.Resource{
+		{Type: gcpcloud.ResourceDisk, Name: "boot", Region: "us-west1", Zone: "us-west1-a", SKU: "pd-standard", SizeGB: 20},
+		{Type: gcpcloud.ResourceDisk, Name: "data", Region: "us-west1", Zone: "us-west1-a", SKU: "pd-standard", SizeGB: 20},
+		{Type: gcpcloud.ResourceDisk, Name: "ssd", Region: "us-west1", Zone: "us-west1-a", SKU: "pd-ssd", SizeGB: 10},
+		{Type: gcpcloud.ResourceDisk, Name: "eu", Region: "europe-west1", Zone: "europe-west1-b", SKU: "pd-standard", SizeGB: 10},
+		{Type: gcpcloud.ResourceDisk, Name: "regional", Region: "us-east1", SKU: "pd-standard", SizeGB: 10},
+	}}
+	CheckGCPResources(defaultGCPCatalog(), inv)
+
+	want := map[string]bool{"boot": true, "data": true, "ssd": false, "eu": false, "regional": false}
+	for _, r := range inv.Resources {
+		if r.FreeTier != want[r.Name] {
+			t.Errorf("%s: free = %v (%s), want %v", r.Name, r.FreeTier, r.Note, want[r.Name])
+		}
+	}
+	if r := inv.Resources[4]; !strings.Contains(r.Note, "regional persistent disks") {
+		t.Errorf("regional disk note = %q", r.Note)
+	}
+	// The two covered disks add up to 40 GB, over the 30 GB allowance.
+	if len(inv.Warnings) != 1 || !strings.Contains(inv.Warnings[0], "40 GB of pd-standard") {
+		t.Errorf("warnings = %q, want one about 40 GB of pd-standard", inv.Warnings)
+	}
+}
+
+func TestCheckGCPResourcesAddresses(t *testing.T) {
+	inv := &gcpcloud.Inventory{Resources: []gcpcloud.Resource{
+		{Type: gcpcloud.ResourceInstance, Name: "free-vm", Region: "us-west1", Zone: "us-west1-a", SKU: "e2-micro", Status: "RUNNING"},
+		{Type: gcpcloud.ResourceInstance, Name: "big-vm", Region: "us-west1", Zone: "us-west1-a", SKU: "n2-standard-2", Status: "RUNNING"},
+		{Type: gcpcloud.ResourceAddress, Name: "on-free", Region: "us-west1", Users: []string{instanceURL + "us-west1-a/instances/free-vm"}},
+		{Type: gcpcloud.ResourceAddress, Name: "on-big", Region: "us-west1", Users: []string{instanceURL + "us-west1-a/instances/big-vm"}},
+		{Type: gcpcloud.ResourceAddress, Name: "idle", Region: "us-west1"},
+		{Type: gcpcloud.ResourceAddress, Name: "lb", Region: "global", Users: []string{"https://www.googleapis.com/compute/v1/projects/my-proj/global/forwardingRules/web"}},
+	}}
+	CheckGCPResources(defaultGCPCatalog(), inv)
+
+	tests := map[string]struct {
+		free bool
+		note string
+	}{
+		"on-free": {true, ""},
+		"on-big":  {false, "attached to big-vm"},
+		"idle":    {false, "reserved but unused"},
+		"lb":      {false, "load balancers"},
+	}
+	for _, r := range inv.Resources[2:] {
+		want := tests[r.Name]
+		if r.FreeTier != want.free || !strings.Contains(r.Note, want.note) {
+			t.Errorf("%s: free = %v, note %q; want %v, %q", r.Name, r.FreeTier, r.Note, want.free, want.note)
+		}
+	}
+}
+
+func TestCheckGCPResourcesBuckets(t *testing.T) {
+	tests := []struct {
+		region, class string
+		free          bool
+	}{
+		{"us-east1", "STANDARD", true},
+		{"us-central1", "standard", true},
+		{"us-east1", "NEARLINE", false},
+		{"us", "STANDARD", false},
+		{"europe-west1", "STANDARD", false},
+	}
+	for _, tt := range tests {
+		inv := &gcpcloud.Inventory{Resources: []gcpcloud.Resource{
+			{Type: gcpcloud.ResourceBucket, Name: "b", Region: tt.region, SKU: tt.class},
+		}}
+		CheckGCPResources(defaultGCPCatalog(), inv)
+		if r := inv.Resources[0]; r.FreeTier != tt.free {
+			t.Errorf("%s bucket in %s: free = %v (%s), want %v", tt.class, tt.region, r.FreeTier, r.Note, tt.free)
+		}
+	}
+}
+
+func TestCheckGCPFreeTier(t *testing.T) {
+	usages := []gcpcloud.SKUUsage{
+		{ServiceName: "Compute Engine", SKU: "E2 Instance Core running in Americas", Amount: 45, Unit: "hour"},
+		{ServiceName: "Compute Engine", SKU: "E2 Instance Ram running in Americas", Amount: 180, Unit: "gibibyte hour"},
+		{ServiceName: "Compute Engine", SKU: "Storage PD Snapshot in US", Amount: 1, Unit: "gibibyte month"},
+		{ServiceName: "Compute Engine", SKU: "Storage PD Snapshot in EU", Amount: 0.5, Unit: "gibibyte month"},
+		{ServiceName: "Compute Engine", SKU: "N2 Instance Core running in Americas", Amount: 100, Unit: "hour"},
+		{ServiceName: "Cloud Run", SKU: "CPU Allocation Time", Amount: 10, Unit: "second"},
+	}
+	// Ten days into a 30-day month.
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	got := CheckGCPFreeTier(defaultGCPCatalog(), usages, now)
+
+	want := []struct {
+		key      string
+		used     float64
+		forecast float64
+		percent  float64
+	}{
+		{"e2_micro_core", 45, 135, 25},
+		{"e2_micro_ram", 180, 540, 25},
+		{"pd_snapshot", 1.5, 4.5, 30},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CheckGCPFreeTier() = %+v, want %d allowances", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Key != w.key || math.Abs(g.Used-w.used) > 1e-9 || math.Abs(g.Forecast-w.forecast) > 1e-9 || math.Abs(g.PercentUsed-w.percent) > 1e-9 {
+			t.Errorf("allowance %d = %s used %v forecast %v (%v%%), want %s used %v forecast %v (%v%%)",
+				i, g.Key, g.Used, g.Forecast, g.PercentUsed, w.key, w.used, w.forecast, w.percent)
+		}
+		if g.Threshold != DefaultWarningThreshold {
+			t.Errorf("%s threshold = %v", g.Key, g.Threshold)
+		}
+	}
+}
@@ -6,27 +6,43 @@ import (
 	"github.com/azguard/azguard/internal/storage"
 )
 
+// CostSummary summarizes stored costs. Amounts in different currencies are
+// never added: TotalCost and Currency are only set when every cost is in one
+// currency, and Totals holds the total per currency.
 type CostSummary struct {
 	Period          string            `json:"period"`
+	Provider        string            `json:"provider,omitempty"`
 	TotalCost       float64           `json:"total_cost"`
 	Currency        string            `json:"currency"`
+	Totals          map[string]float64 `json:"totals"`
 	ByService       map[string]float64 `json:"by_service"`
 	ByResourceGroup map[string]float64 `json:"by_resource_group"`
 	Forecast        *Forecast         `json:"forecast,omitempty"`
 	MonthlyBreakdown []storage.MonthlyCost `json:"monthly_breakdown,omitempty"`
 	Trend           *TrendAnalysis    `json:"trend,omitempty"`
+	Errors          []string          `json:"errors,omitempty"`
 }
 
 type Forecast struct {
 	NextMonth   float64 `json:"next_month"`
+	Currency    string  `json:"currency,omitempty"`
 	Confidence  string  `json:"confidence"`
 }
 
+// MonthEndProjection extrapolates this month's stored spend at its daily run rate.
+type MonthEndProjection struct {
+	MonthToDate float64 `json:"month_to_date"`
+	DaysCovered int     `json:"days_covered"`
+	DaysInMonth int     `json:"days_in_month"`
+	Projected   float64 `json:"projected"`
+}
+
 type Report struct {
 	GeneratedAt string           `json:"generated_at"`
 	Period      string           `json:"period"`
 	TotalCost   float64          `json:"total_cost"`
 	Currency    string           `json:"currency"`
+	Totals      map[string]float64 `json:"totals"`
 	Forecast    float64          `json:"forecast"`
 	MonthlyData []MonthlyReport  `json:"monthly_data"`
 	TopServices []ServiceCost    `json:"top_services"`
@@ -43,10 +59,12 @@ type ServiceCost struct {
 	Cost    float64 `json:"cost"`
 }
 
+// CostFilter narrows stored cost queries. An empty Provider means all providers.
 type CostFilter struct {
 	StartDate   string
 	EndDate     string
 	ServiceName string
+	Provider    string
 	GroupBy     string
 }
 
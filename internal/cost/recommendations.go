@@ -0,0 +1,124 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// Cost scopes of Recommendation.RecordedCost.
+const (
+	CostScopeResource      = "resource"
+	CostScopeResourceGroup = "resource_group"
+)
+
+// RefreshRecommendations pulls Azure Advisor cost recommendations, joins them
+// to the resource inventory and to this month's stored spend, ranks them by
+// estimated savings and stores the result.
+func (s *Service) RefreshRecommendations(ctx context.Context, client *azure.CostClient) ([]storage.Recommendation, error) {
+	advisorRecs, err := client.ListCostRecommendations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The inventory only enriches the list, so a failure here is not fatal.
+	locations := make(map[string]string)
+	if resources, err := client.ListResources(ctx); err == nil {
+		for _, r := range resources {
+			locations[strings.ToLower(r.ID)] = r.Location
+		}
+	}
+
+	startDate, endDate := GetCurrentMonthDateRange()
+	filter := storage.CostFilter{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Provider:  "azure",
+		GroupBy:   "ResourceID",
+	}
+	spendByResource, err := s.db.GetAggregatedCosts(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored costs: %w", err)
+	}
+	filter.GroupBy = "ResourceGroup"
+	spendByGroup, err := s.db.GetAggregatedCosts(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored costs: %w", err)
+	}
+
+	recs := make([]storage.Recommendation, len(advisorRecs))
+	for i, r := range advisorRecs {
+		recs[i] = storage.Recommendation{
+			ID:             r.ID,
+			SubscriptionID: client.SubscriptionID,
+			ResourceID:     r.ResourceID,
+			ResourceGroup:  r.ResourceGroup,
+			ResourceType:   r.ResourceType,
+			ResourceName:   r.ResourceName,
+			Location:       locations[strings.ToLower(r.ResourceID)],
+			Impact:         r.Impact,
+			Problem:        r.Problem,
+			Solution:       r.Solution,
+			MonthlySavings: r.MonthlySavings,
+			Currency:       r.Currency,
+		}
+	}
+	JoinRecordedCosts(recs, spendByResource, spendByGroup)
+
+	RankRecommendations(recs)
+
+	if err := s.db.ReplaceRecommendations(client.SubscriptionID, recs); err != nil {
+		return nil, fmt.Errorf("failed to save recommendations: %w", err)
+	}
+
+	return recs, nil
+}
+
+// JoinRecordedCosts sets each recommendation's recorded spend. Only imported
+// billing line items carry a resource ID; recommendations for resources
+// without any fall back to their resource group's spend, and CostScope says
+// which one was used.
+func JoinRecordedCosts(recs []storage.Recommendation, spendByResource, spendByGroup map[string]float64) {
+	groups := make(map[string]float64, len(spendByGroup))
+	for rg, c := range spendByGroup {
+		groups[strings.ToLower(rg)] += c
+	}
+
+	for i := range recs {
+		r := &recs[i]
+		if c, ok := spendByResource[strings.ToLower(r.ResourceID)]; ok && r.ResourceID != "" {
+			r.RecordedCost, r.CostScope = c, CostScopeResource
+			continue
+		}
+		if c, ok := groups[strings.ToLower(r.ResourceGroup)]; ok && r.ResourceGroup != "" {
+			r.RecordedCost, r.CostScope = c, CostScopeResourceGroup
+			continue
+		}
+		r.RecordedCost, r.CostScope = 0, ""
+	}
+}
+
+// GetStoredRecommendations returns the last recommendations saved by RefreshRecommendations.
+func (s *Service) GetStoredRecommendations(subscriptionID string) ([]storage.Recommendation, error) {
+	return s.db.GetRecommendations(subscriptionID)
+}
+
+// RankRecommendations orders recommendations by estimated monthly savings,
+// breaking ties with the spend already recorded against the resource.
+// Resource group spend covers more than the resource, so it only breaks ties
+// between recommendations that both have it.
+func RankRecommendations(recs []storage.Recommendation) {
+	sort.SliceStable(recs, func(i, j int) bool {
+		if recs[i].MonthlySavings != recs[j].MonthlySavings {
+			return recs[i].MonthlySavings > recs[j].MonthlySavings
+		}
+		if recs[i].CostScope != recs[j].CostScope {
+			return recs[i].CostScope == CostScopeResource
+		}
+		return recs[i].RecordedCost > recs[j].RecordedCost
+	})
+}
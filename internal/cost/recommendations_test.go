@@ -0,0 +1,58 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestJoinRecordedCosts(t *testing.T) {
+	vm := "/subscriptions/s/resourceGroups/rg-web/providers/Microsoft.Compute/virtualMachines/vm1"
+	disk := "/subscriptions/s/resourceGroups/rg-web/providers/Microsoft.Compute/disks/disk1"
+
+	recs := []storage.Recommendation{
+		{ID: "vm", ResourceID: vm, ResourceGroup: "rg-web"},
+		{ID: "disk", ResourceID: disk, ResourceGroup: "RG-WEB"},
+		{ID: "ip", ResourceID: "/subscriptions/s/resourceGroups/rg-other/providers/x/y/ip", ResourceGroup: "rg-other"},
+	}
+	spendByResource := map[string]float64{
+		"": 3,
+		"/subscriptions/s/resourcegroups/rg-web/providers/microsoft.compute/virtualmachines/vm1": 12.5,
+	}
+	spendByGroup := map[string]float64{"rg-web": 20, "": 4}
+
+	JoinRecordedCosts(recs, spendByResource, spendByGroup)
+
+	want := map[string]struct {
+		cost  float64
+		scope string
+	}{
+		"vm":   {12.5, CostScopeResource},
+		"disk": {20, CostScopeResourceGroup},
+		"ip":   {0, ""},
+	}
+	for _, r := range recs {
+		w := want[r.ID]
+		if r.RecordedCost != w.cost || r.CostScope != w.scope {
+			t.Errorf("%s: got $%.2f %q, want $%.2f %q", r.ID, r.RecordedCost, r.CostScope, w.cost, w.scope)
+		}
+	}
+}
+
+func TestRankRecommendations(t *testing.T) {
+	recs := []storage.Recommendation{
+		{ID: "group", MonthlySavings: 5, RecordedCost: 100, CostScope: CostScopeResourceGroup},
+		{ID: "small", MonthlySavings: 1},
+		{ID: "resource", MonthlySavings: 5, RecordedCost: 10, CostScope: CostScopeResource},
+		{ID: "big", MonthlySavings: 30},
+	}
+
+	RankRecommendations(recs)
+
+	want := []string{"big", "resource", "group", "small"}
+	for i, r := range recs {
+		if r.ID != want[i] {
+			t.Fatalf("rank %d = %s, want %s", i, r.ID, want[i])
+		}
+	}
+}
@@ -4,53 +4,168 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/cloud"
 	"github.com/azguard/azguard/internal/storage"
 )
 
 type Service struct {
 	db        *storage.DB
-	azureCost *azure.CostClient
+	providers *cloud.Registry
 }
 
-func NewService(db *storage.DB, azureCost *azure.CostClient) *Service {
+func NewService(db *storage.DB, providers *cloud.Registry) *Service {
 	return &Service{
 		db:        db,
-		azureCost: azureCost,
+		providers: providers,
 	}
 }
 
-func (s *Service) FetchAndStoreCosts(ctx context.Context, startDate, endDate string) error {
-	result, err := s.azureCost.QueryCostsByService(ctx, startDate, endDate)
+// Providers returns the registry the service looks providers up in.
+func (s *Service) Providers() *cloud.Registry {
+	return s.providers
+}
+
+// FetchAndStoreCosts pulls daily costs from a provider and replaces the
+// stored records of its account for the period, so repeated fetches do not
+// double count. endDate is exclusive. It returns the number of records saved.
+func (s *Service) FetchAndStoreCosts(ctx context.Context, provider, startDate, endDate string) (int, error) {
+	p, err := s.providers.Get(provider)
+	if err != nil {
+		return 0, err
+	}
+	account, err := p.Account(ctx)
+	if err != nil {
+		return 0, err
+	}
+	records, err := p.DailyCosts(ctx, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("failed to query costs: %w", err)
-	}
-
-	records := make([]storage.CostRecord, len(result.Records))
-	for i, r := range result.Records {
-		records[i] = storage.CostRecord{
-			SubscriptionID: s.azureCost.SubscriptionID,
-			ResourceGroup:  r.ResourceGroup,
-			ServiceName:    r.ServiceName,
-			Cost:           r.Cost,
-			Currency:       r.Currency,
-			Date:           r.Date,
+		return 0, err
+	}
+	return s.replaceCosts(provider, records, []string{account}, startDate, endDate)
+}
+
+// replaceCosts swaps the stored records of each account of a provider for
+// the period. endDate is exclusive. Days that have records imported from
+// billing files keep them: the import is the line-item source of truth, so
+// the API's records for those days are skipped rather than added on top.
+func (s *Service) replaceCosts(provider string, records []cloud.CostRecord, accountIDs []string, startDate, endDate string) (int, error) {
+	// The stored range is inclusive, so stop the day before endDate.
+	lastDay := endDate
+	if end, err := time.Parse("2006-01-02", endDate); err == nil {
+		lastDay = end.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	imported := make(map[string]map[string]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		filter := storage.CostFilter{
+			StartDate: startDate,
+			EndDate:   lastDay,
+			Provider:  provider,
+		}
+		// Azure subscriptions have their own column.
+		if provider == "azure" {
+			filter.SubscriptionID = accountID
+		} else {
+			filter.AccountID = accountID
+		}
+		days, err := s.db.ImportedCostDays(filter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to look up imported cost records: %w", err)
+		}
+		imported[accountID] = days
+		if err := s.db.DeleteCostRecords(filter); err != nil {
+			return 0, fmt.Errorf("failed to clear cost records: %w", err)
 		}
 	}
 
-	if err := s.db.SaveCostRecords(records); err != nil {
-		return fmt.Errorf("failed to save cost records: %w", err)
+	rows := make([]storage.CostRecord, 0, len(records))
+	for _, r := range records {
+		if imported[r.AccountID][r.Date] {
+			continue
+		}
+		row := storage.CostRecord{
+			Provider:      provider,
+			AccountID:     r.AccountID,
+			ResourceGroup: r.ResourceGroup,
+			ServiceName:   r.ServiceName,
+			Cost:          r.Cost,
+			Currency:      r.Currency,
+			Date:          r.Date,
+		}
+		if provider == "azure" {
+			row.SubscriptionID, row.AccountID = r.AccountID, ""
+		}
+		rows = append(rows, row)
+	}
+	if err := s.db.SaveCostRecords(rows); err != nil {
+		return 0, fmt.Errorf("failed to save cost records: %w", err)
+	}
+
+	return len(rows), nil
+}
+
+// ScanFreeTier reads this month's free tier usage from a provider and stores
+// it as today's snapshot of the provider's account.
+func (s *Service) ScanFreeTier(ctx context.Context, provider string) ([]cloud.FreeTierUsage, error) {
+	p, err := s.providers.Get(provider)
+	if err != nil {
+		return nil, err
+	}
+	account, err := p.Account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := p.FreeTierUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.StoreFreeTierUsage(provider, account, usages); err != nil {
+		return usages, err
 	}
+	return usages, nil
+}
 
+// StoreFreeTierUsage saves today's free tier usage snapshot for an account.
+func (s *Service) StoreFreeTierUsage(provider, accountID string, usages []cloud.FreeTierUsage) error {
+	rows := make([]storage.FreeTierUsage, len(usages))
+	for i, u := range usages {
+		rows[i] = storage.FreeTierUsage{
+			ServiceName: u.Name,
+			UsageType:   u.Key,
+			Actual:      u.Used,
+			Forecast:    u.Forecast,
+			Limit:       u.Limit,
+			Unit:        u.Unit,
+		}
+	}
+	today := time.Now().Format("2006-01-02")
+	if err := s.db.ReplaceFreeTierUsage(provider, accountID, today, rows); err != nil {
+		return fmt.Errorf("failed to save free tier usage: %w", err)
+	}
 	return nil
 }
 
+// UsageStatus classifies free tier usage against its limit and threshold.
+func UsageStatus(u cloud.FreeTierUsage) ResourceStatus {
+	switch {
+	case u.PercentUsed >= 100:
+		return StatusOverage
+	case u.Threshold > 0 && u.PercentUsed >= u.Threshold*100:
+		return StatusWarning
+	default:
+		return StatusFree
+	}
+}
+
 func (s *Service) GetCostSummary(filter CostFilter) (*CostSummary, error) {
 	byService, err := s.db.GetAggregatedCosts(storage.CostFilter{
 		StartDate: filter.StartDate,
 		EndDate:   filter.EndDate,
+		Provider:  filter.Provider,
 		GroupBy:   "ServiceName",
 	})
 	if err != nil {
@@ -60,64 +175,160 @@ func (s *Service) GetCostSummary(filter CostFilter) (*CostSummary, error) {
 	byResourceGroup, err := s.db.GetAggregatedCosts(storage.CostFilter{
 		StartDate: filter.StartDate,
 		EndDate:   filter.EndDate,
+		Provider:  filter.Provider,
 		GroupBy:   "ResourceGroup",
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	var totalCost float64
-	for _, c := range byService {
-		totalCost += c
+	totals, err := s.db.GetAggregatedCosts(storage.CostFilter{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Provider:  filter.Provider,
+		GroupBy:   "Currency",
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	summary := &CostSummary{
 		Period:           filter.StartDate + " to " + filter.EndDate,
-		TotalCost:        totalCost,
-		Currency:         "USD",
+		Provider:         filter.Provider,
+		Totals:           totals,
 		ByService:        byService,
 		ByResourceGroup: byResourceGroup,
 	}
+	summary.TotalCost, summary.Currency = singleTotal(totals)
 
 	return summary, nil
 }
 
-func (s *Service) GetForecast(ctx context.Context) (*Forecast, error) {
-	localForecast, err := s.GetLocalForecast()
+// singleTotal returns the total and its currency when totals holds a single
+// currency, USD when it is empty, and nothing for several currencies.
+func singleTotal(totals map[string]float64) (float64, string) {
+	switch len(totals) {
+	case 0:
+		return 0, "USD"
+	case 1:
+		for currency, total := range totals {
+			return total, currency
+		}
+	}
+	return 0, ""
+}
+
+// monthlyCurrency returns the one currency of monthly costs, or an error when
+// they mix currencies, so trends never add amounts in different currencies.
+// With a single currency each row is a separate month.
+func monthlyCurrency(monthlyCosts []storage.MonthlyCost) (string, error) {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, m := range monthlyCosts {
+		if !seen[m.Currency] {
+			seen[m.Currency] = true
+			currencies = append(currencies, m.Currency)
+		}
+	}
+	if len(currencies) > 1 {
+		sort.Strings(currencies)
+		return "", fmt.Errorf("stored costs are in more than one currency (%s); choose one with --provider", strings.Join(currencies, ", "))
+	}
+	if len(currencies) == 0 {
+		return "USD", nil
+	}
+	return currencies[0], nil
+}
+
+// GetForecast forecasts next month from stored monthly costs of a provider,
+// or all providers when provider is empty. With too little history it falls
+// back to the month-end forecasts of the providers' APIs.
+func (s *Service) GetForecast(ctx context.Context, provider string) (*Forecast, error) {
+	names, err := s.providers.Select(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	localForecast, err := s.GetLocalForecast(provider)
 	if err == nil && localForecast.Confidence != "low" {
 		return localForecast, nil
 	}
 
-	result, err := s.azureCost.GetForecast(ctx, "Monthly")
-	if err != nil {
+	total := 0.0
+	currency := ""
+	forecasted := 0
+	for _, name := range names {
+		p, err := s.providers.Get(name)
+		if err == nil && len(names) > 1 && !p.IsConfigured() {
+			continue
+		}
+		var f *cloud.Forecast
+		if err == nil {
+			f, err = p.Forecast(ctx)
+		}
+		if err != nil {
+			if localForecast != nil {
+				return localForecast, nil
+			}
+			return nil, fmt.Errorf("both local and API forecast failed: %w", err)
+		}
+		if forecasted > 0 && f.Currency != currency {
+			return nil, fmt.Errorf("provider forecasts are in different currencies (%s, %s); choose one with --provider", currency, f.Currency)
+		}
+		total += f.MonthEnd
+		currency = f.Currency
+		forecasted++
+	}
+	if forecasted == 0 {
 		if localForecast != nil {
 			return localForecast, nil
 		}
-		return nil, fmt.Errorf("both local and API forecast failed: %w", err)
+		return nil, fmt.Errorf("no configured provider to forecast")
 	}
 
 	return &Forecast{
-		NextMonth:  result.TotalCost,
+		NextMonth:  total,
+		Currency:   currency,
 		Confidence: "medium",
 	}, nil
 }
 
-func (s *Service) GetCurrentCosts(ctx context.Context) (*CostSummary, error) {
-	startDate, endDate := GetCurrentMonthDateRange()
-
-	if err := s.FetchAndStoreCosts(ctx, startDate, endDate); err != nil {
+// GetCurrentCosts fetches this month's costs from a provider, or from every
+// configured provider when provider is empty, and summarizes what is stored.
+// With several providers, one that fails is listed in the summary's Errors.
+func (s *Service) GetCurrentCosts(ctx context.Context, provider string) (*CostSummary, error) {
+	names, err := s.providers.Select(provider)
+	if err != nil {
 		return nil, err
 	}
 
+	startDate, endDate := GetCurrentMonthDateRange()
+	var fetchErrors []string
+	for _, name := range names {
+		if len(names) > 1 {
+			if p, err := s.providers.Get(name); err == nil && !p.IsConfigured() {
+				continue
+			}
+		}
+		if _, err := s.FetchAndStoreCosts(ctx, name, startDate, endDate); err != nil {
+			if len(names) == 1 {
+				return nil, err
+			}
+			fetchErrors = append(fetchErrors, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
 	summary, err := s.GetCostSummary(CostFilter{
 		StartDate: startDate,
 		EndDate:   endDate,
+		Provider:  provider,
 	})
 	if err != nil {
 		return nil, err
 	}
+	summary.Errors = fetchErrors
 
-	forecast, err := s.GetForecast(ctx)
+	forecast, err := s.GetForecast(ctx, provider)
 	if err == nil {
 		summary.Forecast = forecast
 	}
@@ -125,22 +336,30 @@ func (s *Service) GetCurrentCosts(ctx context.Context) (*CostSummary, error) {
 	return summary, nil
 }
 
-func (s *Service) GetCostHistory(days int) (*CostSummary, error) {
+// GetCostHistory summarizes stored costs for one provider, or all when provider is empty.
+func (s *Service) GetCostHistory(days int, provider string) (*CostSummary, error) {
 	startDate, endDate := GetLastNMonths(days)
 
 	summary, err := s.GetCostSummary(CostFilter{
 		StartDate: startDate,
 		EndDate:   endDate,
+		Provider:  provider,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	monthlyCosts, err := s.db.GetMonthlyCosts(12)
+	monthlyCosts, err := s.db.GetMonthlyCosts(12, provider)
 	if err == nil && len(monthlyCosts) > 0 {
 		summary.MonthlyBreakdown = monthlyCosts
 	}
 
+	if trend, err := s.GetTrendAnalysis(provider); err != nil {
+		summary.Errors = append(summary.Errors, fmt.Sprintf("no trend: %v", err))
+	} else if trend.Trend != "no_data" {
+		summary.Trend = trend
+	}
+
 	return summary, nil
 }
 
@@ -153,11 +372,14 @@ type TrendAnalysis struct {
 	Projection     float64           `json:"projection"`
 }
 
-func (s *Service) GetTrendAnalysis() (*TrendAnalysis, error) {
-	monthlyCosts, err := s.db.GetMonthlyCosts(6)
+func (s *Service) GetTrendAnalysis(provider string) (*TrendAnalysis, error) {
+	monthlyCosts, err := s.db.GetMonthlyCosts(6, provider)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly costs: %w", err)
 	}
+	if _, err := monthlyCurrency(monthlyCosts); err != nil {
+		return nil, err
+	}
 
 	if len(monthlyCosts) == 0 {
 		return &TrendAnalysis{
@@ -206,6 +428,11 @@ func (s *Service) GetTrendAnalysis() (*TrendAnalysis, error) {
 	}, nil
 }
 
+// calculateProjection fits a least-squares line through the monthly costs and
+// returns its value for the following month. GetMonthlyCosts returns the
+// newest month first, so the x axis is counted from the end of the slice;
+// counting from the front would fit the trend backwards and project a falling
+// cost for rising spend.
 func (s *Service) calculateProjection(monthlyCosts []storage.MonthlyCost) float64 {
 	if len(monthlyCosts) < 2 {
 		return 0
@@ -215,7 +442,7 @@ func (s *Service) calculateProjection(monthlyCosts []storage.MonthlyCost) float6
 	var sumX, sumY, sumXY, sumX2 float64
 
 	for i, mc := range monthlyCosts {
-		x := float64(i)
+		x := n - 1 - float64(i)
 		y := mc.TotalCost
 		sumX += x
 		sumY += y
@@ -230,8 +457,12 @@ func (s *Service) calculateProjection(monthlyCosts []storage.MonthlyCost) float6
 	return slope*nextMonthIndex + intercept
 }
 
-func (s *Service) GetLocalForecast() (*Forecast, error) {
-	monthlyCosts, err := s.db.GetMonthlyCosts(6)
+func (s *Service) GetLocalForecast(provider string) (*Forecast, error) {
+	monthlyCosts, err := s.db.GetMonthlyCosts(6, provider)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := monthlyCurrency(monthlyCosts)
 	if err != nil {
 		return nil, err
 	}
@@ -239,6 +470,7 @@ func (s *Service) GetLocalForecast() (*Forecast, error) {
 	if len(monthlyCosts) < 2 {
 		return &Forecast{
 			NextMonth:  0,
+			Currency:   currency,
 			Confidence: "low",
 		}, nil
 	}
@@ -258,22 +490,64 @@ func (s *Service) GetLocalForecast() (*Forecast, error) {
 
 	return &Forecast{
 		NextMonth:  math.Round(projection*100) / 100,
+		Currency:   currency,
 		Confidence: confidence,
 	}, nil
 }
 
-func (s *Service) GenerateReport() (*Report, error) {
-	monthlyCosts, err := s.db.GetMonthlyCosts(12)
+// ProjectMonthEnd projects this month's total from stored daily records for a
+// provider. It returns nil when nothing has been stored for the month yet.
+func (s *Service) ProjectMonthEnd(provider string) (*MonthEndProjection, error) {
+	startDate, endDate := GetCurrentMonthDateRange()
+	records, err := s.db.GetCostRecords(storage.CostFilter{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Provider:  provider,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var (
+		total  float64
+		latest string
+	)
+	for _, r := range records {
+		total += r.Cost
+		if r.Date > latest {
+			latest = r.Date
+		}
+	}
+
+	last, err := time.Parse("2006-01-02", latest[:min(len(latest), 10)])
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored date %q: %w", latest, err)
+	}
+	daysInMonth := time.Date(last.Year(), last.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	return &MonthEndProjection{
+		MonthToDate: math.Round(total*100) / 100,
+		DaysCovered: last.Day(),
+		DaysInMonth: daysInMonth,
+		Projected:   math.Round(total/float64(last.Day())*float64(daysInMonth)*100) / 100,
+	}, nil
+}
+
+func (s *Service) GenerateReport(provider string) (*Report, error) {
+	monthlyCosts, err := s.db.GetMonthlyCosts(12, provider)
 	if err != nil {
 		return nil, err
 	}
 
-	summary, err := s.GetCostSummary(CostFilter{})
+	summary, err := s.GetCostSummary(CostFilter{Provider: provider})
 	if err != nil {
 		return nil, err
 	}
 
-	forecast, _ := s.GetLocalForecast()
+	forecast, _ := s.GetLocalForecast(provider)
 
 	var monthlyData []MonthlyReport
 	for _, m := range monthlyCosts {
@@ -302,6 +576,7 @@ func (s *Service) GenerateReport() (*Report, error) {
 		Period:      period,
 		TotalCost:   summary.TotalCost,
 		Currency:    summary.Currency,
+		Totals:      summary.Totals,
 		Forecast:    0,
 		MonthlyData: monthlyData,
 		TopServices: topServices,
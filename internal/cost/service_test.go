@@ -0,0 +1,138 @@
+package cost
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestReplaceCostsKeepsImportedDays(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, _, err := db.SaveImportedCostRecords([]storage.CostRecord{
+		{Provider: "azure", SubscriptionID: "sub-1", ResourceID: "/vm1", ServiceName: "Virtual Machines", Cost: 4, Currency: "USD", Date: "2024-05-01", ImportKey: "azure:a"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewService(db, nil)
+	records := []cloud.CostRecord{
+		{AccountID: "sub-1", ServiceName: "Virtual Machines", Cost: 4, Currency: "USD", Date: "2024-05-01"},
+		{AccountID: "sub-1", ServiceName: "Virtual Machines", Cost: 3, Currency: "USD", Date: "2024-05-02"},
+	}
+	// Fetching twice must neither delete the import nor double count.
+	for i := 0; i < 2; i++ {
+		saved, err := s.replaceCosts("azure", records, []string{"sub-1"}, "2024-05-01", "2024-05-03")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved != 1 {
+			t.Errorf("fetch %d saved %d records, want only the day without imports", i+1, saved)
+		}
+	}
+
+	stored, err := db.GetCostRecords(storage.CostFilter{Provider: "azure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byDay := make(map[string]float64)
+	for _, r := range stored {
+		byDay[r.Date] += r.Cost
+	}
+	if len(stored) != 2 || byDay["2024-05-01"] != 4 || byDay["2024-05-02"] != 3 {
+		t.Errorf("stored %+v, want the imported $4 on 05-01 and the fetched $3 on 05-02", stored)
+	}
+}
+
+func TestCalculateProjectionFollowsTrend(t *testing.T) {
+	// Newest first, as GetMonthlyCosts returns them.
+	tests := []struct {
+		name   string
+		months []float64
+		want   float64
+	}{
+		{"rising", []float64{40, 30, 20, 10}, 50},
+		{"falling", []float64{10, 20, 30, 40}, 0},
+		{"flat", []float64{25, 25, 25}, 25},
+		{"one month", []float64{30}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			months := make([]storage.MonthlyCost, len(tt.months))
+			for i, c := range tt.months {
+				months[i] = storage.MonthlyCost{Month: fmt.Sprintf("2024-%02d", 5-i), TotalCost: c}
+			}
+			if got := (&Service{}).calculateProjection(months); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("calculateProjection(%v) = %v, want %v", tt.months, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMixedCurrenciesAreNotAdded(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Three months of AWS spend in USD and Azure spend in EUR.
+	now := time.Now().UTC()
+	var records []storage.CostRecord
+	for i := 0; i < 3; i++ {
+		day := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -i, 0).Format("2006-01-02")
+		records = append(records,
+			storage.CostRecord{Provider: "aws", AccountID: "111", ServiceName: "EC2", Cost: 10, Currency: "USD", Date: day},
+			storage.CostRecord{Provider: "azure", SubscriptionID: "sub-1", ServiceName: "Storage", Cost: 5, Currency: "EUR", Date: day},
+		)
+	}
+	if err := db.SaveCostRecords(records); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewService(db, nil)
+	summary, err := s.GetCostSummary(CostFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Currency != "" || summary.TotalCost != 0 || summary.Totals["USD"] != 30 || summary.Totals["EUR"] != 15 {
+		t.Errorf("summary = %v %s, totals %v; want no single total and 30 USD, 15 EUR", summary.TotalCost, summary.Currency, summary.Totals)
+	}
+	if _, err := s.GetTrendAnalysis(""); err == nil {
+		t.Error("GetTrendAnalysis() across currencies succeeded, want an error")
+	}
+	if _, err := s.GetLocalForecast(""); err == nil {
+		t.Error("GetLocalForecast() across currencies succeeded, want an error")
+	}
+
+	summary, err = s.GetCostSummary(CostFilter{Provider: "azure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Currency != "EUR" || summary.TotalCost != 15 {
+		t.Errorf("azure summary = %v %s, want 15 EUR", summary.TotalCost, summary.Currency)
+	}
+	trend, err := s.GetTrendAnalysis("azure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trend.CurrentMonth != 5 || trend.PreviousMonth != 5 || trend.AverageMonthly != 5 {
+		t.Errorf("azure trend = %+v, want 5 each month", trend)
+	}
+	forecast, err := s.GetLocalForecast("azure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forecast.Currency != "EUR" {
+		t.Errorf("forecast currency = %q, want EUR", forecast.Currency)
+	}
+}
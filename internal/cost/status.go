@@ -0,0 +1,308 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// Provider alerts are named after the provider and one of these, e.g.
+// aws-credit-20. Every other alert is a dollar budget on the combined spend.
+const (
+	ThresholdAlertSuffix = "-threshold-"
+	CreditAlertSuffix    = "-credit-"
+	PlanDaysAlertSuffix  = "-plan-days-"
+	AnomalyAlertSuffix   = "-anomaly-"
+)
+
+// ProviderStatus is one provider's row of the status dashboard.
+// FreeTierPercent is the highest usage of any of its allowances.
+type ProviderStatus struct {
+	Provider        string         `json:"provider"`
+	Account         string         `json:"account,omitempty"`
+	Spend           float64        `json:"spend"`
+	Currency        string         `json:"currency"`
+	FreeTierPercent float64        `json:"free_tier_percent"`
+	Allowances      int            `json:"allowances"`
+	Warnings        int            `json:"warnings"`
+	Overages        int            `json:"overages"`
+	Credit          *cloud.Credit  `json:"credit,omitempty"`
+	Alerts          []string       `json:"alerts,omitempty"`
+	Notes           []string       `json:"notes,omitempty"`
+	Status          ResourceStatus `json:"status"`
+	Error           string         `json:"error,omitempty"`
+}
+
+// StatusReport combines the status of each provider. Totals is the spend
+// per currency; amounts in different currencies are never added, so
+// TotalSpend and Currency are only set when every provider bills in one
+// currency. Alerts are the budget alerts reached by a total; Status is the
+// worst of all.
+type StatusReport struct {
+	Providers  []ProviderStatus   `json:"providers"`
+	TotalSpend float64            `json:"total_spend"`
+	Currency   string             `json:"currency"`
+	Totals     map[string]float64 `json:"totals"`
+	Alerts     []string           `json:"alerts,omitempty"`
+	Status     ResourceStatus     `json:"status"`
+}
+
+// statusRank orders statuses from best to worst. A provider that could not
+// be read ranks as unknown, above a warning and below an overage.
+var statusRank = map[ResourceStatus]int{
+	StatusFree:    0,
+	StatusWarning: 1,
+	StatusUnknown: 2,
+	StatusOverage: 3,
+}
+
+// WorseStatus returns the worse of two statuses.
+func WorseStatus(a, b ResourceStatus) ResourceStatus {
+	if statusRank[b] > statusRank[a] {
+		return b
+	}
+	return a
+}
+
+// GetStatus reads this month's spend, free tier usage and credit of a
+// provider, or of every configured provider in parallel when provider is
+// empty, and checks them against the enabled alerts.
+func (s *Service) GetStatus(ctx context.Context, provider string) (*StatusReport, error) {
+	names, err := s.providers.Select(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers are created in parallel: creating one can mean probing for
+	// credentials, which should not add up across providers.
+	unconfigured := make([]bool, len(names))
+	if len(names) > 1 {
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				if p, err := s.providers.Get(name); err == nil && !p.IsConfigured() {
+					unconfigured[i] = true
+				}
+			}(i, name)
+		}
+		wg.Wait()
+	}
+	var selected []string
+	for i, name := range names {
+		if !unconfigured[i] {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no provider is configured; see 'azguard config list'")
+	}
+
+	alerts, err := s.db.GetAlerts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+
+	report := &StatusReport{Providers: make([]ProviderStatus, len(selected))}
+	var wg sync.WaitGroup
+	for i, name := range selected {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			report.Providers[i] = s.providerStatus(ctx, name, alerts)
+		}(i, name)
+	}
+	wg.Wait()
+
+	var budgets []storage.Alert
+	for _, a := range alerts {
+		if a.Enabled && s.isBudgetAlert(a.Name) {
+			budgets = append(budgets, a)
+		}
+	}
+	aggregateStatus(report, budgets)
+	return report, nil
+}
+
+// aggregateStatus totals the providers' spend per currency, checks the USD
+// total against the budget alerts and sets the worst status. Providers that
+// could not be read, or spent nothing, add no currency of their own unless
+// nothing was spent at all.
+func aggregateStatus(report *StatusReport, budgets []storage.Alert) {
+	report.Totals = make(map[string]float64)
+	report.Status = StatusFree
+	idle := make(map[string]bool)
+	for _, ps := range report.Providers {
+		report.Status = WorseStatus(report.Status, ps.Status)
+		switch {
+		case ps.Error != "":
+		case ps.Spend != 0:
+			report.Totals[ps.Currency] += ps.Spend
+		default:
+			idle[ps.Currency] = true
+		}
+	}
+	if len(report.Totals) == 0 {
+		for currency := range idle {
+			report.Totals[currency] = 0
+		}
+	}
+	if len(report.Totals) == 0 {
+		report.Totals["USD"] = 0
+	}
+
+	currencies := make([]string, 0, len(report.Totals))
+	for currency := range report.Totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	report.TotalSpend, report.Currency = 0, ""
+	if len(currencies) == 1 {
+		report.Currency = currencies[0]
+		report.TotalSpend = report.Totals[report.Currency]
+	}
+
+	// Budgets are in US dollars, so only the USD total is checked.
+	usd, ok := report.Totals["USD"]
+	if !ok {
+		return
+	}
+	for _, a := range budgets {
+		if usd >= a.Threshold {
+			report.Alerts = append(report.Alerts, fmt.Sprintf("%s: $%.2f spent (alert at $%.2f)", a.Name, usd, a.Threshold))
+			report.Status = WorseStatus(report.Status, StatusWarning)
+		}
+	}
+}
+
+func (s *Service) isBudgetAlert(name string) bool {
+	return IsBudgetAlert(name, s.providers.Names())
+}
+
+// IsBudgetAlert reports whether an alert is a dollar budget rather than a
+// provider alert. Provider alerts are matched by their whole generated name,
+// a provider, a suffix and a number, so a budget named e.g. "aws-dev" is
+// still a budget.
+func IsBudgetAlert(name string, providers []string) bool {
+	for _, provider := range providers {
+		for _, suffix := range []string{ThresholdAlertSuffix, CreditAlertSuffix, PlanDaysAlertSuffix, AnomalyAlertSuffix} {
+			if rest, ok := strings.CutPrefix(name, provider+suffix); ok {
+				if _, err := strconv.ParseFloat(rest, 64); err == nil {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (s *Service) providerStatus(ctx context.Context, name string, alerts []storage.Alert) ProviderStatus {
+	ps := ProviderStatus{Provider: name, Currency: "USD", Status: StatusFree}
+	fail := func(err error) ProviderStatus {
+		ps.Error = err.Error()
+		ps.Status = StatusUnknown
+		return ps
+	}
+
+	p, err := s.providers.Get(name)
+	if err != nil {
+		return fail(err)
+	}
+	if !p.IsConfigured() {
+		return fail(fmt.Errorf("credentials not configured"))
+	}
+	if ps.Account, err = p.Account(ctx); err != nil {
+		return fail(err)
+	}
+
+	startDate, endDate := GetCurrentMonthDateRange()
+	records, err := p.DailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return fail(err)
+	}
+	for _, r := range records {
+		ps.Spend += r.Cost
+		if r.Currency != "" {
+			ps.Currency = r.Currency
+		}
+	}
+
+	usages, err := p.FreeTierUsage(ctx)
+	if err != nil {
+		// Without usage data, any spend is a sign of charges.
+		ps.Notes = append(ps.Notes, fmt.Sprintf("Could not fetch free tier data: %v", err))
+		if ps.Spend > 0 {
+			ps.Status = StatusWarning
+		}
+	}
+	ps.Allowances = len(usages)
+	for _, u := range usages {
+		ps.FreeTierPercent = math.Max(ps.FreeTierPercent, u.PercentUsed)
+		switch status := UsageStatus(u); status {
+		case StatusOverage:
+			ps.Overages++
+			ps.Status = WorseStatus(ps.Status, status)
+		case StatusWarning:
+			ps.Warnings++
+			ps.Status = WorseStatus(ps.Status, status)
+		}
+	}
+
+	if cr, ok := p.(cloud.CreditReporter); ok {
+		credit, err := cr.Credit(ctx)
+		if err != nil {
+			ps.Notes = append(ps.Notes, fmt.Sprintf("Could not fetch credit: %v", err))
+		}
+		ps.Credit = credit
+		if credit != nil && !credit.Paid && (credit.Expired || credit.Remaining <= 0) {
+			ps.Status = WorseStatus(ps.Status, StatusOverage)
+		}
+	}
+
+	ps.Alerts = providerAlerts(name, alerts, ps, time.Now())
+	if len(ps.Alerts) > 0 {
+		ps.Status = WorseStatus(ps.Status, StatusWarning)
+	}
+	return ps
+}
+
+// providerAlerts returns a message for each enabled alert of the provider
+// that its status has reached.
+func providerAlerts(provider string, alerts []storage.Alert, ps ProviderStatus, now time.Time) []string {
+	var messages []string
+	for _, a := range alerts {
+		if !a.Enabled {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(a.Name, provider+ThresholdAlertSuffix) && ps.Allowances > 0 && ps.FreeTierPercent >= a.Threshold:
+			messages = append(messages, fmt.Sprintf("%s: free tier %.0f%% used (alert at %.0f%%)", a.Name, ps.FreeTierPercent, a.Threshold))
+		case strings.HasPrefix(a.Name, provider+CreditAlertSuffix) && ps.Credit != nil && !ps.Credit.Paid && ps.Credit.Remaining <= a.Threshold:
+			messages = append(messages, fmt.Sprintf("%s: $%.2f credit left (alert at $%.2f)", a.Name, ps.Credit.Remaining, a.Threshold))
+		case strings.HasPrefix(a.Name, provider+PlanDaysAlertSuffix) && ps.Credit != nil && !ps.Credit.Paid && !ps.Credit.Expires.IsZero():
+			if days := DaysUntil(ps.Credit.Expires, now); float64(days) <= a.Threshold {
+				messages = append(messages, fmt.Sprintf("%s: free plan ends in %d days (alert at %.0f)", a.Name, days, a.Threshold))
+			}
+		}
+	}
+	return messages
+}
+
+// DaysUntil returns the whole days from now until t, or 0 once t has passed.
+func DaysUntil(t, now time.Time) int {
+	days := math.Ceil(t.Sub(now).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return int(days)
+}
@@ -0,0 +1,192 @@
+package cost
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestWorseStatus(t *testing.T) {
+	order := []ResourceStatus{StatusFree, StatusWarning, StatusUnknown, StatusOverage}
+	for i, better := range order {
+		for _, worse := range order[i:] {
+			if got := WorseStatus(better, worse); got != worse {
+				t.Errorf("WorseStatus(%s, %s) = %s", better, worse, got)
+			}
+			if got := WorseStatus(worse, better); got != worse {
+				t.Errorf("WorseStatus(%s, %s) = %s", worse, better, got)
+			}
+		}
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	budget := []storage.Alert{{Name: "budget-10", Threshold: 10, Enabled: true}}
+
+	tests := []struct {
+		name      string
+		providers []ProviderStatus
+		budgets   []storage.Alert
+		total     float64
+		currency  string
+		totals    map[string]float64
+		alerts    int
+		status    ResourceStatus
+	}{
+		{
+			name:     "no providers",
+			totals:   map[string]float64{"USD": 0},
+			currency: "USD",
+			status:   StatusFree,
+		},
+		{
+			name: "one currency",
+			providers: []ProviderStatus{
+				{Provider: "azure", Spend: 4, Currency: "USD", Status: StatusFree},
+				{Provider: "aws", Spend: 7, Currency: "USD", Status: StatusWarning},
+			},
+			budgets:  budget,
+			total:    11,
+			currency: "USD",
+			totals:   map[string]float64{"USD": 11},
+			alerts:   1,
+			status:   StatusWarning,
+		},
+		{
+			name: "mixed currencies are not added",
+			providers: []ProviderStatus{
+				{Provider: "azure", Spend: 8, Currency: "EUR", Status: StatusFree},
+				{Provider: "aws", Spend: 8, Currency: "USD", Status: StatusFree},
+			},
+			budgets: budget,
+			totals:  map[string]float64{"EUR": 8, "USD": 8},
+			status:  StatusFree,
+		},
+		{
+			name: "budgets only checked against the USD total",
+			providers: []ProviderStatus{
+				{Provider: "azure", Spend: 12, Currency: "EUR", Status: StatusFree},
+				{Provider: "aws", Spend: 3, Currency: "USD", Status: StatusFree},
+			},
+			budgets: budget,
+			totals:  map[string]float64{"EUR": 12, "USD": 3},
+			status:  StatusFree,
+		},
+		{
+			name: "USD over budget beside another currency",
+			providers: []ProviderStatus{
+				{Provider: "azure", Spend: 2, Currency: "EUR", Status: StatusFree},
+				{Provider: "aws", Spend: 11, Currency: "USD", Status: StatusFree},
+			},
+			budgets: budget,
+			totals:  map[string]float64{"EUR": 2, "USD": 11},
+			alerts:  1,
+			status:  StatusWarning,
+		},
+		{
+			name: "idle and failed providers add no currency",
+			providers: []ProviderStatus{
+				{Provider: "azure", Spend: 5, Currency: "EUR", Status: StatusFree},
+				{Provider: "gcp", Currency: "USD", Status: StatusFree},
+				{Provider: "aws", Currency: "USD", Status: StatusUnknown, Error: "credentials not configured"},
+			},
+			total:    5,
+			currency: "EUR",
+			totals:   map[string]float64{"EUR": 5},
+			status:   StatusUnknown,
+		},
+		{
+			name: "nothing spent keeps the providers' currency",
+			providers: []ProviderStatus{
+				{Provider: "azure", Currency: "EUR", Status: StatusFree},
+			},
+			currency: "EUR",
+			totals:   map[string]float64{"EUR": 0},
+			status:   StatusFree,
+		},
+		{
+			name: "overage outranks unknown",
+			providers: []ProviderStatus{
+				{Provider: "azure", Spend: 1, Currency: "USD", Status: StatusOverage},
+				{Provider: "aws", Currency: "USD", Status: StatusUnknown, Error: "timeout"},
+			},
+			budgets:  budget,
+			total:    1,
+			currency: "USD",
+			totals:   map[string]float64{"USD": 1},
+			status:   StatusOverage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &StatusReport{Providers: tt.providers}
+			aggregateStatus(report, tt.budgets)
+
+			if report.TotalSpend != tt.total || report.Currency != tt.currency {
+				t.Errorf("total = $%.2f %q, want $%.2f %q", report.TotalSpend, report.Currency, tt.total, tt.currency)
+			}
+			if !reflect.DeepEqual(report.Totals, tt.totals) {
+				t.Errorf("totals = %v, want %v", report.Totals, tt.totals)
+			}
+			if len(report.Alerts) != tt.alerts {
+				t.Errorf("alerts = %v, want %d", report.Alerts, tt.alerts)
+			}
+			if report.Status != tt.status {
+				t.Errorf("status = %s, want %s", report.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestProviderAlerts(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	alerts := []storage.Alert{
+		{Name: "aws-credit-20", Threshold: 20, Enabled: true},
+		{Name: "aws-plan-days-14", Threshold: 14, Enabled: true},
+	}
+
+	tests := []struct {
+		name   string
+		credit *cloud.Credit
+		want   int
+	}{
+		{"no plan", nil, 0},
+		{"plenty left", &cloud.Credit{Remaining: 150, Expires: now.AddDate(0, 3, 0)}, 0},
+		{"low credit", &cloud.Credit{Remaining: 12, Expires: now.AddDate(0, 3, 0)}, 1},
+		{"low credit ending soon", &cloud.Credit{Remaining: 12, Expires: now.AddDate(0, 0, 5)}, 2},
+		{"paid plan has no credit", &cloud.Credit{Plan: "Paid", Paid: true}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := providerAlerts("aws", alerts, ProviderStatus{Credit: tt.credit}, now)
+			if len(got) != tt.want {
+				t.Errorf("alerts = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBudgetAlert(t *testing.T) {
+	providers := []string{"azure", "aws", "gcp"}
+	tests := map[string]bool{
+		"budget-10":         true,
+		"aws-dev":           true,
+		"aws-credit-card":   true,
+		"aws-threshold-80":  false,
+		"aws-credit-20":     false,
+		"aws-credit-12.5":   false,
+		"aws-plan-days-14":  false,
+		"aws-anomaly-5":     false,
+		"gcp-threshold-90":  false,
+		"azure-threshold-1": false,
+	}
+	for name, want := range tests {
+		if got := IsBudgetAlert(name, providers); got != want {
+			t.Errorf("IsBudgetAlert(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
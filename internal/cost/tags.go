@@ -0,0 +1,104 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// UntaggedLabel is shown for spend on resources without the requested tag.
+const UntaggedLabel = "(untagged)"
+
+type TagCostSummary struct {
+	TagKey    string     `json:"tag_key"`
+	Period    string     `json:"period"`
+	TotalCost float64    `json:"total_cost"`
+	Currency  string     `json:"currency"`
+	Untagged  float64    `json:"untagged"`
+	ByValue   []TagValue `json:"by_value"`
+}
+
+type TagValue struct {
+	Value string  `json:"value"`
+	Cost  float64 `json:"cost"`
+}
+
+// FetchAndStoreCostsByTag queries Azure costs grouped by a tag key and
+// replaces the stored records for that tag and period.
+func (s *Service) FetchAndStoreCostsByTag(ctx context.Context, client *azure.CostClient, tagKey, startDate, endDate string) error {
+	result, err := client.QueryCostsByTag(ctx, tagKey, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("failed to query costs by tag: %w", err)
+	}
+
+	records := make([]storage.CostRecord, len(result.Records))
+	for i, r := range result.Records {
+		records[i] = storage.CostRecord{
+			Provider:       "azure",
+			SubscriptionID: client.SubscriptionID,
+			ResourceGroup:  r.ResourceGroup,
+			ServiceName:    r.ServiceName,
+			TagKey:         tagKey,
+			TagValue:       r.TagValue,
+			Cost:           r.Cost,
+			Currency:       r.Currency,
+			Date:           r.Date,
+		}
+	}
+
+	filter := storage.CostFilter{StartDate: startDate, EndDate: endDate, TagKey: tagKey, Provider: "azure"}
+	if err := s.db.DeleteCostRecords(filter); err != nil {
+		return fmt.Errorf("failed to clear cost records: %w", err)
+	}
+	if err := s.db.SaveCostRecords(records); err != nil {
+		return fmt.Errorf("failed to save cost records: %w", err)
+	}
+
+	return nil
+}
+
+// GetCostSummaryByTag aggregates stored costs for a tag key by tag value.
+func (s *Service) GetCostSummaryByTag(tagKey string, filter CostFilter) (*TagCostSummary, error) {
+	byValue, err := s.db.GetAggregatedCosts(storage.CostFilter{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		TagKey:    tagKey,
+		GroupBy:   "TagValue",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.db.GetAggregatedCosts(storage.CostFilter{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		TagKey:    tagKey,
+		GroupBy:   "Currency",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) > 1 {
+		return nil, fmt.Errorf("costs tagged with %q are in more than one currency", tagKey)
+	}
+
+	summary := &TagCostSummary{
+		TagKey: tagKey,
+		Period: filter.StartDate + " to " + filter.EndDate,
+	}
+	_, summary.Currency = singleTotal(totals)
+	for value, c := range byValue {
+		summary.TotalCost += c
+		if value == "" {
+			summary.Untagged += c
+			value = UntaggedLabel
+		}
+		summary.ByValue = append(summary.ByValue, TagValue{Value: value, Cost: c})
+	}
+
+	sort.Slice(summary.ByValue, func(i, j int) bool { return summary.ByValue[i].Cost > summary.ByValue[j].Cost })
+	return summary, nil
+}
@@ -0,0 +1,211 @@
+// Package demo generates a synthetic cost history for workshops and serves
+// it back through providers that never call a cloud.
+package demo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a demo story: the accounts it spends in, their services, free
+// tier usage and credit, the alerts set up for them and a planted overage.
+type Profile struct {
+	Name        string
+	Description string
+	Accounts    []Account
+	Alerts      []Alert
+}
+
+// Account is one provider's account in a profile. Costs of services with a
+// Tag are also stored grouped by TagKey, as 'azguard cost by-tag' does.
+type Account struct {
+	Provider   string
+	ID         string
+	TagKey     string
+	Services   []Service
+	Allowances []Allowance
+	Credit     *Credit
+	Overage    *Overage
+}
+
+// Service is a daily cost with noise, a weekend effect and monthly growth.
+type Service struct {
+	Name          string
+	ResourceGroup string
+	Tag           string
+	// Daily is the weekday cost in the current month.
+	Daily float64
+	// Weekend scales the cost on Saturdays and Sundays; 0 leaves it unchanged.
+	Weekend float64
+	// Noise is the relative standard deviation of each day's cost.
+	Noise float64
+	// Growth is the relative cost increase per month.
+	Growth float64
+}
+
+// Allowance is free tier usage reported by the provider. Monthly is the
+// usage a whole month reaches.
+type Allowance struct {
+	Service   string
+	UsageType string
+	Limit     float64
+	Monthly   float64
+	Unit      string
+}
+
+// Credit is a free credit granted when the history starts.
+type Credit struct {
+	Amount float64
+	Months int
+}
+
+// Overage is a cost that starts Days ago and runs until today.
+type Overage struct {
+	Service string
+	Tag     string
+	Daily   float64
+	Days    int
+	Story   string
+}
+
+// Alert is an alert the profile sets up, named like those azguard creates.
+type Alert struct {
+	Name      string
+	Threshold float64
+}
+
+var profiles = map[string]Profile{
+	"student-vm-overrun": {
+		Name:        "student-vm-overrun",
+		Description: "Azure for Students: a coursework VM resized for an assignment and left running",
+		Accounts: []Account{{
+			Provider: "azure",
+			ID:       "5f0c9e1a-3b7d-4c2e-9a61-2d8f4b7e1c03",
+			TagKey:   "course",
+			Services: []Service{
+				{Name: "Virtual Machines", ResourceGroup: "rg-coursework", Tag: "cs101", Daily: 0.21, Weekend: 0.6, Noise: 0.08},
+				{Name: "Storage", ResourceGroup: "rg-coursework", Tag: "cs101", Daily: 0.0035, Noise: 0.05, Growth: 0.04},
+				{Name: "Bandwidth", ResourceGroup: "rg-coursework", Daily: 0.012, Weekend: 0.4, Noise: 0.3},
+				{Name: "Azure Monitor", ResourceGroup: "rg-coursework", Daily: 0.006, Noise: 0.1},
+			},
+			Credit: &Credit{Amount: 100, Months: 12},
+			Overage: &Overage{
+				Service: "Virtual Machines",
+				Tag:     "cs229",
+				Daily:   4.61,
+				Days:    11,
+				Story:   "A B1s VM was resized to a D4s_v3 for a machine learning assignment and left running",
+			},
+		}},
+		Alerts: []Alert{
+			{Name: "budget-10", Threshold: 10},
+			{Name: "budget-25", Threshold: 25},
+			{Name: "azure-credit-25", Threshold: 25},
+		},
+	},
+	"aws-free-plan": {
+		Name:        "aws-free-plan",
+		Description: "AWS free plan: a NAT gateway left behind by a tutorial eats the credits",
+		Accounts: []Account{{
+			Provider: "aws",
+			ID:       "210987654321",
+			Services: []Service{
+				{Name: "Amazon Elastic Compute Cloud - Compute", Daily: 0.28, Noise: 0.03},
+				{Name: "Amazon Relational Database Service", Daily: 0.41, Noise: 0.02},
+				{Name: "Amazon Simple Storage Service", Daily: 0.009, Weekend: 0.5, Noise: 0.2, Growth: 0.08},
+				{Name: "Amazon CloudWatch", Daily: 0.02, Noise: 0.15},
+			},
+			Allowances: []Allowance{
+				{Service: "Amazon Elastic Compute Cloud", UsageType: "USE1-BoxUsage:freetier.micro", Limit: 750, Monthly: 712, Unit: "Hrs"},
+				{Service: "Amazon Relational Database Service", UsageType: "USE1-InstanceUsage:db.t3.micro", Limit: 750, Monthly: 744, Unit: "Hrs"},
+				{Service: "Amazon Simple Storage Service", UsageType: "USE1-TimedStorage-ByteHrs", Limit: 5, Monthly: 2.4, Unit: "GB-Mo"},
+			},
+			Credit: &Credit{Amount: 175, Months: 6},
+			Overage: &Overage{
+				Service: "EC2 - Other",
+				Daily:   1.08,
+				Days:    9,
+				Story:   "A NAT gateway from a VPC tutorial was never deleted",
+			},
+		}},
+		Alerts: []Alert{
+			{Name: "budget-20", Threshold: 20},
+			{Name: "aws-threshold-80", Threshold: 80},
+			{Name: "aws-credit-50", Threshold: 50},
+			{Name: "aws-plan-days-30", Threshold: 30},
+		},
+	},
+	"multi-cloud": {
+		Name:        "multi-cloud",
+		Description: "Azure, AWS and GCP inside their free tiers until a GCP egress spike",
+		Accounts: []Account{
+			{
+				Provider: "azure",
+				ID:       "9b2e4d71-6a0c-4f38-8e15-c7d3a9f2b640",
+				TagKey:   "env",
+				Services: []Service{
+					{Name: "Virtual Machines", ResourceGroup: "rg-web", Tag: "prod", Daily: 0.19, Noise: 0.04},
+					{Name: "Storage", ResourceGroup: "rg-web", Tag: "prod", Daily: 0.003, Noise: 0.05, Growth: 0.05},
+					{Name: "Bandwidth", ResourceGroup: "rg-web", Daily: 0.01, Weekend: 0.5, Noise: 0.25},
+				},
+			},
+			{
+				Provider: "aws",
+				ID:       "345678901234",
+				Services: []Service{
+					{Name: "AWS Lambda", Daily: 0.004, Weekend: 0.3, Noise: 0.3},
+					{Name: "Amazon Simple Storage Service", Daily: 0.006, Noise: 0.1, Growth: 0.03},
+					{Name: "Amazon DynamoDB", Daily: 0.002, Weekend: 0.5, Noise: 0.2},
+				},
+				Allowances: []Allowance{
+					{Service: "AWS Lambda", UsageType: "Global-Request", Limit: 1000000, Monthly: 412000, Unit: "Requests"},
+					{Service: "Amazon Simple Storage Service", UsageType: "USE1-TimedStorage-ByteHrs", Limit: 5, Monthly: 1.3, Unit: "GB-Mo"},
+					{Service: "Amazon DynamoDB", UsageType: "USE1-TimedStorage-ByteHrs", Limit: 25, Monthly: 0.8, Unit: "GB-Mo"},
+				},
+			},
+			{
+				Provider: "gcp",
+				ID:       "demo-project-4821",
+				Services: []Service{
+					{Name: "Compute Engine", Daily: 0.01, Noise: 0.05},
+					{Name: "Cloud Storage", Daily: 0.002, Noise: 0.1, Growth: 0.04},
+				},
+				Allowances: []Allowance{
+					{Service: "E2 Instance Core running in Americas", UsageType: "e2-micro", Limit: 744, Monthly: 720, Unit: "hour"},
+					{Service: "Storage PD Capacity", UsageType: "pd-standard", Limit: 30, Monthly: 10, Unit: "gibibyte month"},
+					{Service: "Network Internet Egress from Americas to Americas", UsageType: "egress", Limit: 1, Monthly: 4.2, Unit: "gibibyte"},
+				},
+				Overage: &Overage{
+					Service: "Networking",
+					Daily:   0.86,
+					Days:    4,
+					Story:   "A blog post went viral and its images are served straight from the VM",
+				},
+			},
+		},
+		Alerts: []Alert{
+			{Name: "budget-5", Threshold: 5},
+			{Name: "gcp-threshold-90", Threshold: 90},
+		},
+	},
+}
+
+// GetProfile returns a profile by name.
+func GetProfile(name string) (Profile, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown demo profile %q (%s)", name, strings.Join(ProfileNames(), ", "))
+	}
+	return p, nil
+}
+
+// ProfileNames returns the profile names, sorted.
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,192 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/providers"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// The demo database records each seeded account and its credit in the
+// config table under these keys.
+func accountKey(provider string) string       { return "demo." + provider + ".account" }
+func creditKey(provider string) string        { return "demo." + provider + ".credit" }
+func creditStartKey(provider string) string   { return "demo." + provider + ".credit_start" }
+func creditExpiresKey(provider string) string { return "demo." + provider + ".credit_expires" }
+
+// Provider serves an account seeded into the demo database, so every
+// command works without a cloud account.
+type Provider struct {
+	name string
+	db   *storage.DB
+}
+
+func NewProvider(name string, db *storage.DB) *Provider {
+	return &Provider{name: name, db: db}
+}
+
+func (p *Provider) Name() string { return p.name }
+
+func (p *Provider) IsConfigured() bool {
+	account, err := p.db.GetConfig(accountKey(p.name))
+	return err == nil && account != ""
+}
+
+func (p *Provider) Account(ctx context.Context) (string, error) {
+	account, err := p.db.GetConfig(accountKey(p.name))
+	if err != nil {
+		return "", err
+	}
+	if account == "" {
+		return "", fmt.Errorf("the demo has no %s account; run 'azguard demo seed' with another profile", p.name)
+	}
+	return account, nil
+}
+
+func (p *Provider) DailyCosts(ctx context.Context, startDate, endDate string) ([]cloud.CostRecord, error) {
+	// Stored date ranges include their last day.
+	lastDay := endDate
+	if end, err := time.Parse(dateLayout, endDate); err == nil {
+		lastDay = end.AddDate(0, 0, -1).Format(dateLayout)
+	}
+
+	rows, err := p.db.GetCostRecords(storage.CostFilter{Provider: p.name, StartDate: startDate, EndDate: lastDay})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read demo costs: %w", err)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Date < rows[j].Date })
+
+	records := make([]cloud.CostRecord, len(rows))
+	for i, r := range rows {
+		account := r.AccountID
+		if r.SubscriptionID != "" {
+			account = r.SubscriptionID
+		}
+		records[i] = cloud.CostRecord{
+			AccountID:     account,
+			ResourceGroup: r.ResourceGroup,
+			ServiceName:   r.ServiceName,
+			Cost:          r.Cost,
+			Currency:      r.Currency,
+			Date:          r.Date,
+		}
+	}
+	return records, nil
+}
+
+// FreeTierUsage works out Azure usage from this month's costs, as the Azure
+// provider does, and reads the seeded usage of other providers.
+func (p *Provider) FreeTierUsage(ctx context.Context) ([]cloud.FreeTierUsage, error) {
+	if p.name == "azure" {
+		startDate, endDate := cost.GetCurrentMonthDateRange()
+		records, err := p.DailyCosts(ctx, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		// Only a seeded credit is counted, as only Free Trial and
+		// Azure for Students subscriptions come with one.
+		credit, _, err := p.credit()
+		if err != nil {
+			return nil, err
+		}
+		return providers.AzureFreeTierUsage(records, credit), nil
+	}
+
+	account, err := p.Account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.db.GetFreeTierUsage(p.name, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read demo free tier usage: %w", err)
+	}
+
+	usages := make([]cloud.FreeTierUsage, len(rows))
+	for i, r := range rows {
+		usages[i] = cloud.FreeTierUsage{
+			Key:       r.UsageType,
+			Name:      r.ServiceName,
+			Used:      r.Actual,
+			Forecast:  r.Forecast,
+			Limit:     r.Limit,
+			Unit:      r.Unit,
+			Threshold: cost.DefaultWarningThreshold,
+		}
+		if r.Limit > 0 {
+			usages[i].PercentUsed = r.Actual / r.Limit * 100
+		}
+	}
+	return usages, nil
+}
+
+func (p *Provider) Inventory(ctx context.Context) (*cloud.Inventory, error) {
+	return nil, fmt.Errorf("listing resources is %w in the demo", cloud.ErrNotSupported)
+}
+
+// Forecast projects the average of the last seven days over the rest of the
+// month.
+func (p *Provider) Forecast(ctx context.Context) (*cloud.Forecast, error) {
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	records, err := p.DailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := now.Format(dateLayout)
+	weekAgo := now.AddDate(0, 0, -7).Format(dateLayout)
+	monthToDate, lastWeek := 0.0, 0.0
+	for _, r := range records {
+		monthToDate += r.Cost
+		if r.Date > weekAgo && r.Date <= today {
+			lastWeek += r.Cost
+		}
+	}
+
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	remaining := float64(daysInMonth - now.Day())
+	return &cloud.Forecast{
+		MonthToDate: monthToDate,
+		MonthEnd:    monthToDate + lastWeek/7*remaining,
+		Currency:    "USD",
+	}, nil
+}
+
+// Credit is the seeded credit less everything spent since it was granted.
+// Accounts seeded without a credit have none.
+func (p *Provider) Credit(ctx context.Context) (*cloud.Credit, error) {
+	amount, start, err := p.credit()
+	if err != nil || amount == 0 {
+		return nil, err
+	}
+
+	spent, err := p.db.GetTotalCost(storage.CostFilter{Provider: p.name, StartDate: start})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read demo costs: %w", err)
+	}
+	credit := &cloud.Credit{Remaining: amount - spent, Currency: "USD"}
+	if expires, err := p.db.GetConfig(creditExpiresKey(p.name)); err == nil && expires != "" {
+		credit.Expires, _ = time.ParseInLocation(dateLayout, expires, time.Local)
+	}
+	return credit, nil
+}
+
+// credit returns the seeded credit amount and the day it was granted.
+func (p *Provider) credit() (float64, string, error) {
+	value, err := p.db.GetConfig(creditKey(p.name))
+	if err != nil || value == "" {
+		return 0, "", err
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid demo credit %q: %w", value, err)
+	}
+	start, err := p.db.GetConfig(creditStartKey(p.name))
+	return amount, start, err
+}
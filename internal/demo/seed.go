@@ -0,0 +1,189 @@
+package demo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+const dateLayout = "2006-01-02"
+
+// Summary describes what Seed generated.
+type Summary struct {
+	Profile   string             `json:"profile"`
+	StartDate string             `json:"start_date"`
+	EndDate   string             `json:"end_date"`
+	Records   int                `json:"records"`
+	Totals    map[string]float64 `json:"totals"`
+	Overages  []string           `json:"overages"`
+	Alerts    []string           `json:"alerts"`
+}
+
+// Seed replaces the contents of db with months of synthetic history for a
+// profile, from the first day of the earliest month up to now's day. The
+// same seed always generates the same history.
+func Seed(db *storage.DB, profile Profile, months int, now time.Time, seed int64) (*Summary, error) {
+	if months < 1 {
+		return nil, fmt.Errorf("months must be at least 1")
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+
+	if err := db.Reset(); err != nil {
+		return nil, fmt.Errorf("failed to clear the demo database: %w", err)
+	}
+
+	summary := &Summary{
+		Profile:   profile.Name,
+		StartDate: start.Format(dateLayout),
+		EndDate:   today.Format(dateLayout),
+		Totals:    make(map[string]float64),
+	}
+	var records []storage.CostRecord
+	for _, a := range profile.Accounts {
+		rows := accountHistory(a, start, today, rng)
+		for _, r := range rows {
+			if r.TagKey == "" {
+				summary.Totals[a.Provider] += r.Cost
+			}
+		}
+		records = append(records, rows...)
+
+		if err := db.SetConfig(accountKey(a.Provider), a.ID); err != nil {
+			return nil, err
+		}
+		if c := a.Credit; c != nil {
+			settings := map[string]string{
+				creditKey(a.Provider):        strconv.FormatFloat(c.Amount, 'f', 2, 64),
+				creditStartKey(a.Provider):   start.Format(dateLayout),
+				creditExpiresKey(a.Provider): start.AddDate(0, c.Months, 0).Format(dateLayout),
+			}
+			for k, v := range settings {
+				if err := db.SetConfig(k, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(a.Allowances) > 0 {
+			if err := db.ReplaceFreeTierUsage(a.Provider, a.ID, today.Format(dateLayout), allowanceUsage(a, today, rng)); err != nil {
+				return nil, fmt.Errorf("failed to save free tier usage: %w", err)
+			}
+		}
+		if o := a.Overage; o != nil {
+			summary.Overages = append(summary.Overages, fmt.Sprintf("%s: %s (+$%.2f/day for %d days)", a.Provider, o.Story, o.Daily, o.Days))
+		}
+	}
+
+	if err := db.SaveCostRecords(records); err != nil {
+		return nil, fmt.Errorf("failed to save cost records: %w", err)
+	}
+	summary.Records = len(records)
+
+	for _, alert := range profile.Alerts {
+		if err := db.SaveAlert(storage.Alert{Name: alert.Name, Threshold: alert.Threshold, Enabled: true}); err != nil {
+			return nil, fmt.Errorf("failed to save alert %s: %w", alert.Name, err)
+		}
+		summary.Alerts = append(summary.Alerts, alert.Name)
+	}
+
+	for p, total := range summary.Totals {
+		summary.Totals[p] = math.Round(total*100) / 100
+	}
+	return summary, nil
+}
+
+// accountHistory generates a record per service and day, plus the daily
+// totals per tag value when the account has a tag key.
+func accountHistory(a Account, start, today time.Time, rng *rand.Rand) []storage.CostRecord {
+	var records []storage.CostRecord
+	record := func(service, resourceGroup, tagValue string, cost float64, date string) storage.CostRecord {
+		r := storage.CostRecord{
+			Provider:      a.Provider,
+			AccountID:     a.ID,
+			ResourceGroup: resourceGroup,
+			ServiceName:   service,
+			TagValue:      tagValue,
+			Cost:          cost,
+			Currency:      "USD",
+			Date:          date,
+		}
+		if a.Provider == "azure" {
+			r.SubscriptionID, r.AccountID = a.ID, ""
+		}
+		return r
+	}
+
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		monthsAgo := (today.Year()-day.Year())*12 + int(today.Month()-day.Month())
+		weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+
+		byTag := make(map[string]float64)
+		var tags []string
+		add := func(r storage.CostRecord, tag string) {
+			records = append(records, r)
+			if a.TagKey == "" {
+				return
+			}
+			if _, ok := byTag[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			byTag[tag] += r.Cost
+		}
+
+		for _, s := range a.Services {
+			cost := s.Daily / math.Pow(1+s.Growth, float64(monthsAgo))
+			if weekend && s.Weekend > 0 {
+				cost *= s.Weekend
+			}
+			cost = roundCost(cost * noise(rng, s.Noise))
+			add(record(s.Name, s.ResourceGroup, "", cost, date), s.Tag)
+		}
+		if o := a.Overage; o != nil && today.Sub(day) < time.Duration(o.Days)*24*time.Hour {
+			add(record(o.Service, "", "", roundCost(o.Daily*noise(rng, 0.03)), date), o.Tag)
+		}
+
+		for _, tag := range tags {
+			r := record("", "", tag, roundCost(byTag[tag]), date)
+			r.TagKey = a.TagKey
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// allowanceUsage is each allowance's usage so far this month, on track to
+// reach its monthly usage.
+func allowanceUsage(a Account, today time.Time, rng *rand.Rand) []storage.FreeTierUsage {
+	daysInMonth := time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	elapsed := float64(today.Day()) / float64(daysInMonth)
+
+	usage := make([]storage.FreeTierUsage, len(a.Allowances))
+	for i, al := range a.Allowances {
+		usage[i] = storage.FreeTierUsage{
+			ServiceName: al.Service,
+			UsageType:   al.UsageType,
+			Actual:      roundCost(al.Monthly * elapsed * noise(rng, 0.03)),
+			Forecast:    al.Monthly,
+			Limit:       al.Limit,
+			Unit:        al.Unit,
+		}
+	}
+	return usage
+}
+
+// noise returns a random factor around 1 with the given relative standard
+// deviation, never below 0.1.
+func noise(rng *rand.Rand, stddev float64) float64 {
+	return math.Max(0.1, 1+rng.NormFloat64()*stddev)
+}
+
+func roundCost(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
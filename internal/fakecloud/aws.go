@@ -0,0 +1,255 @@
+package fakecloud
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (s *Server) awsCallerIdentity(w http.ResponseWriter, r *http.Request, body []byte) error {
+	var resp struct {
+		XMLName xml.Name `xml:"GetCallerIdentityResponse"`
+		Account string   `xml:"GetCallerIdentityResult>Account"`
+		Arn     string   `xml:"GetCallerIdentityResult>Arn"`
+		UserID  string   `xml:"GetCallerIdentityResult>UserId"`
+	}
+	resp.Account = s.Scenario.AWS.AccountID
+	resp.Arn = "arn:aws:iam::" + resp.Account + ":user/azguard-fake"
+	resp.UserID = "AIDAFAKEAZGUARDUSER"
+	writeXML(w, http.StatusOK, resp)
+	return nil
+}
+
+type timePeriod struct {
+	Start string `json:"Start"`
+	End   string `json:"End"`
+}
+
+func (p timePeriod) parse() (time.Time, time.Time, error) {
+	start, err := parseDay(p.Start)
+	if err != nil {
+		return start, start, fmt.Errorf("invalid TimePeriod.Start: %w", err)
+	}
+	end, err := parseDay(p.End)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid TimePeriod.End: %w", err)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("TimePeriod.Start must be before TimePeriod.End")
+	}
+	return start, end, nil
+}
+
+type awsAmount struct {
+	Amount string `json:"Amount"`
+	Unit   string `json:"Unit"`
+}
+
+type awsGroup struct {
+	Keys    []string             `json:"Keys"`
+	Metrics map[string]awsAmount `json:"Metrics"`
+}
+
+// awsCostAndUsage answers daily or monthly UnblendedCost, in total or
+// grouped by SERVICE and LINKED_ACCOUNT.
+func (s *Server) awsCostAndUsage(w http.ResponseWriter, r *http.Request, body []byte) error {
+	var req struct {
+		TimePeriod  timePeriod `json:"TimePeriod"`
+		Granularity string     `json:"Granularity"`
+		GroupBy     []struct {
+			Type string `json:"Type"`
+			Key  string `json:"Key"`
+		} `json:"GroupBy"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	start, end, err := req.TimePeriod.parse()
+	if err != nil {
+		return err
+	}
+	for _, g := range req.GroupBy {
+		if g.Key != "SERVICE" && g.Key != "LINKED_ACCOUNT" {
+			return fmt.Errorf("grouping by %s is not emulated", g.Key)
+		}
+	}
+
+	// Costs are only known up to today.
+	known := s.today().AddDate(0, 0, 1)
+	var periods [][2]time.Time
+	for from := start; from.Before(end); {
+		to := from.AddDate(0, 0, 1)
+		if req.Granularity == "MONTHLY" {
+			to = time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		if to.After(end) {
+			to = end
+		}
+		periods = append(periods, [2]time.Time{from, to})
+		from = to
+	}
+
+	var results []interface{}
+	for _, p := range periods {
+		var (
+			keys   [][]string
+			totals = make(map[string]float64)
+			total  float64
+		)
+		for _, day := range days(p[0], p[1]) {
+			if !day.Before(known) {
+				break
+			}
+			for _, c := range s.Scenario.AWS.DailyCosts {
+				cost := c.costOn(day)
+				if cost == 0 {
+					continue
+				}
+				total += cost
+				groupKeys := make([]string, len(req.GroupBy))
+				for i, g := range req.GroupBy {
+					groupKeys[i] = c.Service
+					if g.Key == "LINKED_ACCOUNT" {
+						groupKeys[i] = s.linkedAccount(c)
+					}
+				}
+				k := fmt.Sprint(groupKeys)
+				if _, ok := totals[k]; !ok {
+					keys = append(keys, groupKeys)
+				}
+				totals[k] += cost
+			}
+		}
+
+		result := map[string]interface{}{
+			"TimePeriod": timePeriod{Start: p[0].Format(dateLayout), End: p[1].Format(dateLayout)},
+			"Estimated":  !p[1].Before(known),
+			"Total":      map[string]awsAmount{},
+			"Groups":     []awsGroup{},
+		}
+		if len(req.GroupBy) == 0 {
+			result["Total"] = map[string]awsAmount{"UnblendedCost": {Amount: formatAmount(total), Unit: s.Scenario.Currency}}
+		} else {
+			groups := make([]awsGroup, 0, len(keys))
+			for _, k := range keys {
+				groups = append(groups, awsGroup{
+					Keys:    k,
+					Metrics: map[string]awsAmount{"UnblendedCost": {Amount: formatAmount(totals[fmt.Sprint(k)]), Unit: s.Scenario.Currency}},
+				})
+			}
+			result["Groups"] = groups
+		}
+		results = append(results, result)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"GroupDefinitions": req.GroupBy,
+		"ResultsByTime":    results,
+	})
+	return nil
+}
+
+// awsCostForecast forecasts the scenario's costs for the requested days,
+// optionally filtered to services, with a ±10% prediction interval.
+func (s *Server) awsCostForecast(w http.ResponseWriter, r *http.Request, body []byte) error {
+	var req struct {
+		TimePeriod timePeriod `json:"TimePeriod"`
+		Filter     *struct {
+			Dimensions struct {
+				Key    string   `json:"Key"`
+				Values []string `json:"Values"`
+			} `json:"Dimensions"`
+		} `json:"Filter"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	start, end, err := req.TimePeriod.parse()
+	if err != nil {
+		return err
+	}
+	if start.Before(s.today()) {
+		return fmt.Errorf("TimePeriod.Start must not be before today")
+	}
+
+	var services map[string]bool
+	if f := req.Filter; f != nil && f.Dimensions.Key == "SERVICE" {
+		services = make(map[string]bool)
+		for _, v := range f.Dimensions.Values {
+			services[v] = true
+		}
+	}
+
+	mean := 0.0
+	for _, day := range days(start, end) {
+		for _, c := range s.Scenario.AWS.DailyCosts {
+			if services == nil || services[c.Service] {
+				mean += c.costOn(day)
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"Total": awsAmount{Amount: formatAmount(mean), Unit: s.Scenario.Currency},
+		"ForecastResultsByTime": []map[string]interface{}{{
+			"TimePeriod":                   req.TimePeriod,
+			"MeanValue":                    formatAmount(mean),
+			"PredictionIntervalLowerBound": formatAmount(mean * 0.9),
+			"PredictionIntervalUpperBound": formatAmount(mean * 1.1),
+		}},
+	})
+	return nil
+}
+
+func (s *Server) awsFreeTierUsage(w http.ResponseWriter, r *http.Request, body []byte) error {
+	usages := []map[string]interface{}{}
+	for _, u := range s.Scenario.AWS.FreeTier {
+		forecast := u.Forecast
+		if forecast == 0 {
+			forecast = u.Used
+		}
+		usages = append(usages, map[string]interface{}{
+			"service":               u.Service,
+			"usageType":             u.UsageType,
+			"description":           u.Description,
+			"actualUsageAmount":     u.Used,
+			"forecastedUsageAmount": forecast,
+			"limit":                 map[string]interface{}{"amount": u.Limit, "unit": u.Unit},
+			"region":                "global",
+			"freeTierType":          "Always Free",
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"freeTierUsages": usages})
+	return nil
+}
+
+func (s *Server) awsAccountPlanState(w http.ResponseWriter, r *http.Request, body []byte) error {
+	state := map[string]interface{}{
+		"accountId":         s.Scenario.AWS.AccountID,
+		"accountPlanType":   "PAID",
+		"accountPlanStatus": "ACTIVE",
+	}
+	if p := s.Scenario.AWS.Plan; p != nil {
+		status := p.Status
+		if status == "" {
+			status = "ACTIVE"
+		}
+		state["accountPlanType"] = "FREE"
+		state["accountPlanStatus"] = status
+		state["accountPlanRemainingCredits"] = map[string]interface{}{"amount": p.Credits, "unit": s.Scenario.Currency}
+		if expires, _ := parseDate(p.Expires); !expires.IsZero() {
+			state["accountPlanExpirationDate"] = expires.Format(time.RFC3339)
+		}
+	}
+	writeJSON(w, http.StatusOK, state)
+	return nil
+}
+
+func (s *Server) linkedAccount(c DailyCost) string {
+	if c.Account != "" {
+		return c.Account
+	}
+	return s.Scenario.AWS.AccountID
+}
@@ -0,0 +1,257 @@
+package fakecloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fakeToken is handed out by the token endpoints. Any bearer token is
+// accepted, but one must be sent.
+const fakeToken = "fake-azure-access-token"
+
+func (s *Server) azureToken(w http.ResponseWriter, r *http.Request, body []byte) error {
+	now := s.Now()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"token_type":     "Bearer",
+		"expires_in":     3599,
+		"ext_expires_in": 3599,
+		"expires_on":     strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+		"resource":       "https://management.azure.com",
+		"access_token":   fakeToken,
+	})
+	return nil
+}
+
+// azureSubscription describes the subscription in the path, created from
+// the scenario's offer.
+func (s *Server) azureSubscription(w http.ResponseWriter, r *http.Request, body []byte) error {
+	if !authorized(w, r) {
+		return nil
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/subscriptions/"), "/")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":             "/subscriptions/" + id,
+		"subscriptionId": id,
+		"displayName":    "azguard fake subscription",
+		"state":          "Enabled",
+		"subscriptionPolicies": map[string]string{
+			"quotaId":             s.Scenario.Azure.QuotaID,
+			"spendingLimit":       "On",
+			"locationPlacementId": "Public_2014-09-01",
+		},
+	})
+	return nil
+}
+
+// costQuery is the part of a Cost Management query the server reads.
+type costQuery struct {
+	Type       string `json:"type"`
+	Timeframe  string `json:"timeframe"`
+	TimePeriod *struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"timePeriod"`
+	Dataset struct {
+		Granularity string `json:"granularity"`
+		Grouping    []struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+		} `json:"grouping"`
+	} `json:"dataset"`
+}
+
+type queryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// azureQuery answers actual cost queries for a custom period or the month
+// to date, by day or in total, grouped by at most one dimension or tag.
+func (s *Server) azureQuery(w http.ResponseWriter, r *http.Request, body []byte) error {
+	if !authorized(w, r) {
+		return nil
+	}
+	q, err := parseCostQuery(body)
+	if err != nil {
+		return err
+	}
+
+	today := s.today()
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := today.AddDate(0, 0, 1)
+	if q.TimePeriod != nil {
+		if start, err = parseDay(q.TimePeriod.From); err != nil {
+			return fmt.Errorf("invalid timePeriod.from: %w", err)
+		}
+		if end, err = parseDay(q.TimePeriod.To); err != nil {
+			return fmt.Errorf("invalid timePeriod.to: %w", err)
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+	// Costs are only known up to today.
+	if end.After(today.AddDate(0, 0, 1)) {
+		end = today.AddDate(0, 0, 1)
+	}
+
+	daily := strings.EqualFold(q.Dataset.Granularity, "Daily")
+	columns := []queryColumn{{Name: "Cost", Type: "Number"}}
+	if daily {
+		columns = append(columns, queryColumn{Name: "UsageDate", Type: "Number"})
+	}
+	group, err := groupColumns(q)
+	if err != nil {
+		return err
+	}
+	columns = append(columns, group...)
+	columns = append(columns, queryColumn{Name: "Currency", Type: "String"})
+
+	type rowKey struct {
+		date  string
+		group string
+	}
+	var (
+		keys   []rowKey
+		totals = make(map[rowKey]float64)
+	)
+	for _, day := range days(start, end) {
+		for _, c := range s.Scenario.Azure.DailyCosts {
+			cost := c.costOn(day)
+			if cost == 0 {
+				continue
+			}
+			key := rowKey{group: groupValue(q, c)}
+			if daily {
+				key.date = day.Format("20060102")
+			}
+			if _, ok := totals[key]; !ok {
+				keys = append(keys, key)
+			}
+			totals[key] += cost
+		}
+	}
+
+	rows := make([][]interface{}, 0, len(keys))
+	for _, key := range keys {
+		row := []interface{}{totals[key]}
+		if daily {
+			date, _ := strconv.Atoi(key.date)
+			row = append(row, date)
+		}
+		if len(group) == 2 {
+			row = append(row, q.Dataset.Grouping[0].Name)
+		}
+		if len(group) > 0 {
+			row = append(row, key.group)
+		}
+		rows = append(rows, append(row, s.Scenario.Currency))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":   r.URL.Path,
+		"name": "fake-query",
+		"type": "Microsoft.CostManagement/query",
+		"properties": map[string]interface{}{
+			"nextLink": nil,
+			"columns":  columns,
+			"rows":     rows,
+		},
+	})
+	return nil
+}
+
+// azureForecast forecasts each day from tomorrow to the end of the month
+// from the scenario's costs, in the tabular shape of the query API.
+func (s *Server) azureForecast(w http.ResponseWriter, r *http.Request, body []byte) error {
+	if !authorized(w, r) {
+		return nil
+	}
+	if _, err := parseCostQuery(body); err != nil {
+		return err
+	}
+
+	today := s.today()
+	monthEnd := time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{}
+	for _, day := range days(today.AddDate(0, 0, 1), monthEnd) {
+		cost := 0.0
+		for _, c := range s.Scenario.Azure.DailyCosts {
+			cost += c.costOn(day)
+		}
+		date, _ := strconv.Atoi(day.Format("20060102"))
+		rows = append(rows, []interface{}{cost, date, "Forecast", s.Scenario.Currency})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":   r.URL.Path,
+		"name": "fake-forecast",
+		"type": "Microsoft.CostManagement/query",
+		"properties": map[string]interface{}{
+			"nextLink": nil,
+			"columns": []queryColumn{
+				{Name: "Cost", Type: "Number"},
+				{Name: "UsageDate", Type: "Number"},
+				{Name: "CostStatus", Type: "String"},
+				{Name: "Currency", Type: "String"},
+			},
+			"rows": rows,
+		},
+	})
+	return nil
+}
+
+func parseCostQuery(body []byte) (*costQuery, error) {
+	q := &costQuery{}
+	if err := json.Unmarshal(body, q); err != nil {
+		return nil, fmt.Errorf("invalid query body: %w", err)
+	}
+	if len(q.Dataset.Grouping) > 1 {
+		return nil, fmt.Errorf("only one grouping is emulated")
+	}
+	return q, nil
+}
+
+// groupColumns returns the columns a query's grouping adds to each row.
+func groupColumns(q *costQuery) ([]queryColumn, error) {
+	if len(q.Dataset.Grouping) == 0 {
+		return nil, nil
+	}
+	g := q.Dataset.Grouping[0]
+	switch {
+	case strings.EqualFold(g.Type, "TagKey"):
+		return []queryColumn{{Name: "TagKey", Type: "String"}, {Name: "TagValue", Type: "String"}}, nil
+	case strings.EqualFold(g.Name, "ServiceName"), strings.EqualFold(g.Name, "ResourceGroup"), strings.EqualFold(g.Name, "ResourceGroupName"):
+		return []queryColumn{{Name: g.Name, Type: "String"}}, nil
+	}
+	return nil, fmt.Errorf("grouping by %s %s is not emulated", g.Type, g.Name)
+}
+
+func groupValue(q *costQuery, c DailyCost) string {
+	if len(q.Dataset.Grouping) == 0 {
+		return ""
+	}
+	g := q.Dataset.Grouping[0]
+	switch {
+	case strings.EqualFold(g.Type, "TagKey"):
+		return c.Tags[g.Name]
+	case strings.EqualFold(g.Name, "ServiceName"):
+		return c.Service
+	}
+	return c.ResourceGroup
+}
+
+// authorized rejects Resource Manager calls without a bearer token.
+func authorized(w http.ResponseWriter, r *http.Request) bool {
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return true
+	}
+	writeFault(w, "azure.query", faultState{Fault: Fault{
+		Status:  http.StatusUnauthorized,
+		Code:    "AuthenticationFailed",
+		Message: "Authentication failed. The 'Authorization' header is missing.",
+	}})
+	return false
+}
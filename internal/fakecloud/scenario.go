@@ -0,0 +1,167 @@
+// Package fakecloud serves stand-ins for the Azure and AWS billing APIs
+// azguard calls, answering from a scenario file instead of a real account.
+package fakecloud
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const dateLayout = "2006-01-02"
+
+// Scenario describes the accounts the fake server pretends to be and the
+// failures it injects.
+type Scenario struct {
+	Currency string        `yaml:"currency"`
+	Azure    AzureScenario `yaml:"azure"`
+	AWS      AWSScenario   `yaml:"aws"`
+	// Errors fail matching calls with an error status.
+	Errors []Fault `yaml:"errors"`
+	// Throttle fails matching calls the way each API reports throttling.
+	Throttle []Fault `yaml:"throttle"`
+}
+
+// AzureScenario is the subscription behind the Cost Management API.
+type AzureScenario struct {
+	// QuotaID is the subscription's offer, e.g. FreeTrial_2014-09-01 (the
+	// default), AzureForStudents_2018-01-01 or PayAsYouGo_2014-09-01.
+	QuotaID    string      `yaml:"quota_id"`
+	DailyCosts []DailyCost `yaml:"daily_costs"`
+}
+
+// AWSScenario is the account behind Cost Explorer, the Free Tier API and STS.
+type AWSScenario struct {
+	AccountID  string          `yaml:"account_id"`
+	DailyCosts []DailyCost     `yaml:"daily_costs"`
+	FreeTier   []FreeTierUsage `yaml:"free_tier"`
+	// Plan is the free plan; without one the account is on the paid plan.
+	Plan *Plan `yaml:"plan"`
+}
+
+// DailyCost is a cost charged every day from From to To, both inclusive and
+// either open-ended when empty. Costs after today are only used for
+// forecasts.
+type DailyCost struct {
+	Service       string            `yaml:"service"`
+	ResourceGroup string            `yaml:"resource_group"`
+	Account       string            `yaml:"account"`
+	Tags          map[string]string `yaml:"tags"`
+	Cost          float64           `yaml:"cost"`
+	From          string            `yaml:"from"`
+	To            string            `yaml:"to"`
+}
+
+// FreeTierUsage is one row of the AWS Free Tier API.
+type FreeTierUsage struct {
+	Service     string  `yaml:"service"`
+	UsageType   string  `yaml:"usage_type"`
+	Description string  `yaml:"description"`
+	Used        float64 `yaml:"used"`
+	Forecast    float64 `yaml:"forecast"`
+	Limit       float64 `yaml:"limit"`
+	Unit        string  `yaml:"unit"`
+}
+
+// Plan is the AWS account's credit-based free plan.
+type Plan struct {
+	Credits float64 `yaml:"credits"`
+	Expires string  `yaml:"expires"`
+	Status  string  `yaml:"status"`
+}
+
+// Fault fails calls to an API: an operation such as aws.GetCostAndUsage or
+// azure.query, a whole cloud (azure, aws), or every call (*). Times limits
+// the fault to the first calls; 0 fails every call.
+type Fault struct {
+	API        string `yaml:"api"`
+	Status     int    `yaml:"status"`
+	Code       string `yaml:"code"`
+	Message    string `yaml:"message"`
+	Times      int    `yaml:"times"`
+	RetryAfter int    `yaml:"retry_after"`
+}
+
+// LoadScenario reads and checks a scenario file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	s := &Scenario{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Scenario) validate() error {
+	if s.Currency == "" {
+		s.Currency = "USD"
+	}
+	if s.Azure.QuotaID == "" {
+		s.Azure.QuotaID = "FreeTrial_2014-09-01"
+	}
+	if s.AWS.AccountID == "" {
+		s.AWS.AccountID = "123456789012"
+	}
+
+	for _, costs := range [][]DailyCost{s.Azure.DailyCosts, s.AWS.DailyCosts} {
+		for _, c := range costs {
+			if c.Service == "" {
+				return fmt.Errorf("daily cost without a service")
+			}
+			for _, d := range []string{c.From, c.To} {
+				if _, err := parseDate(d); err != nil {
+					return fmt.Errorf("daily cost of %s: %w", c.Service, err)
+				}
+			}
+		}
+	}
+	if p := s.AWS.Plan; p != nil {
+		if _, err := parseDate(p.Expires); err != nil {
+			return fmt.Errorf("aws plan: %w", err)
+		}
+	}
+
+	for _, faults := range [][]Fault{s.Errors, s.Throttle} {
+		for _, f := range faults {
+			if f.API == "" {
+				return fmt.Errorf("fault without an api")
+			}
+			if _, ok := operations[f.API]; !ok && f.API != "*" && f.API != "azure" && f.API != "aws" {
+				return fmt.Errorf("unknown api %q (%s)", f.API, strings.Join(OperationNames(), ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// parseDate parses a yyyy-mm-dd date; an empty string is the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// costOn returns the cost charged on day.
+func (c DailyCost) costOn(day time.Time) float64 {
+	from, _ := parseDate(c.From)
+	to, _ := parseDate(c.To)
+	if (!from.IsZero() && day.Before(from)) || (!to.IsZero() && day.After(to)) {
+		return 0
+	}
+	return c.Cost
+}
@@ -0,0 +1,273 @@
+package fakecloud
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type handler func(s *Server, w http.ResponseWriter, r *http.Request, body []byte) error
+
+// operations are the emulated APIs, named as faults refer to them.
+var operations = map[string]handler{
+	"azure.token":             (*Server).azureToken,
+	"azure.query":             (*Server).azureQuery,
+	"azure.forecast":          (*Server).azureForecast,
+	"azure.subscription":      (*Server).azureSubscription,
+	"aws.GetCallerIdentity":   (*Server).awsCallerIdentity,
+	"aws.GetCostAndUsage":     (*Server).awsCostAndUsage,
+	"aws.GetCostForecast":     (*Server).awsCostForecast,
+	"aws.GetFreeTierUsage":    (*Server).awsFreeTierUsage,
+	"aws.GetAccountPlanState": (*Server).awsAccountPlanState,
+}
+
+// OperationNames returns the names of the emulated APIs, sorted.
+func OperationNames() []string {
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Server answers Azure and AWS API calls from a scenario. Both clouds share
+// one address: Azure calls are told apart by path, AWS calls by their
+// X-Amz-Target header or Action parameter.
+type Server struct {
+	Scenario *Scenario
+	// Log receives a line per call when set.
+	Log io.Writer
+	// Now is the server's clock; costs after today are not reported as actual.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	faults []*faultState
+}
+
+type faultState struct {
+	Fault
+	throttle bool
+	calls    int
+}
+
+// NewServer creates a server for a scenario.
+func NewServer(s *Scenario) *Server {
+	srv := &Server{Scenario: s, Now: time.Now}
+	for _, f := range s.Throttle {
+		srv.faults = append(srv.faults, &faultState{Fault: f, throttle: true})
+	}
+	for _, f := range s.Errors {
+		srv.faults = append(srv.faults, &faultState{Fault: f})
+	}
+	return srv
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	op := operationName(r, body)
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		if s.Log != nil {
+			fmt.Fprintf(s.Log, "%s %s %s → %d\n", s.Now().Format("15:04:05"), r.Method, op, rec.status)
+		}
+	}()
+
+	h, ok := operations[op]
+	if !ok {
+		writeFault(rec, op, faultState{Fault: Fault{Status: http.StatusNotFound, Code: "UnknownOperation",
+			Message: fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path)}})
+		return
+	}
+	if f := s.fault(op); f != nil {
+		writeFault(rec, op, *f)
+		return
+	}
+	if err := h(s, rec, r, body); err != nil {
+		writeFault(rec, op, faultState{Fault: Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: err.Error()}})
+	}
+}
+
+var subscriptionPath = regexp.MustCompile(`^/subscriptions/[^/]+/?$`)
+
+// operationName identifies the API a request calls.
+func operationName(r *http.Request, body []byte) string {
+	path := strings.ToLower(r.URL.Path)
+	switch {
+	case strings.HasSuffix(path, "/oauth2/v2.0/token"), strings.HasSuffix(path, "/oauth2/token"):
+		return "azure.token"
+	case strings.HasSuffix(path, "/providers/microsoft.costmanagement/forecast"):
+		return "azure.forecast"
+	case strings.HasSuffix(path, "/providers/microsoft.costmanagement/query"):
+		var q struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(body, &q) == nil && strings.EqualFold(q.Type, "Forecast") {
+			return "azure.forecast"
+		}
+		return "azure.query"
+	case r.Method == http.MethodGet && subscriptionPath.MatchString(path):
+		return "azure.subscription"
+	}
+
+	if target := r.Header.Get("X-Amz-Target"); target != "" {
+		return "aws." + target[strings.LastIndex(target, ".")+1:]
+	}
+	if form, err := url.ParseQuery(string(body)); err == nil && form.Get("Action") != "" {
+		return "aws." + form.Get("Action")
+	}
+	if action := r.URL.Query().Get("Action"); action != "" {
+		return "aws." + action
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// fault returns the first fault still active for an operation and counts
+// the call against it.
+func (s *Server) fault(op string) *faultState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.faults {
+		if f.API != "*" && f.API != op && !strings.HasPrefix(op, f.API+".") {
+			continue
+		}
+		if f.Times > 0 && f.calls >= f.Times {
+			continue
+		}
+		f.calls++
+		return f
+	}
+	return nil
+}
+
+// writeFault writes an error the way the operation's API reports it.
+func writeFault(w http.ResponseWriter, op string, f faultState) {
+	status, code, message := f.Status, f.Code, f.Message
+	aws := strings.HasPrefix(op, "aws.")
+	if f.throttle {
+		if status == 0 {
+			status = http.StatusTooManyRequests
+			if aws {
+				// AWS reports throttling as a client error.
+				status = http.StatusBadRequest
+			}
+		}
+		if code == "" {
+			code = "TooManyRequests"
+			if aws {
+				code = "ThrottlingException"
+			}
+		}
+		if message == "" {
+			message = "Rate exceeded"
+		}
+	}
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	if code == "" {
+		code = "InternalServerError"
+		if aws {
+			code = "InternalServerException"
+		}
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(f.RetryAfter))
+	}
+
+	switch {
+	case op == "aws.GetCallerIdentity":
+		var e struct {
+			XMLName xml.Name `xml:"ErrorResponse"`
+			Type    string   `xml:"Error>Type"`
+			Code    string   `xml:"Error>Code"`
+			Message string   `xml:"Error>Message"`
+		}
+		e.Type, e.Code, e.Message = "Sender", code, message
+		writeXML(w, status, e)
+	case aws:
+		w.Header().Set("X-Amzn-ErrorType", code)
+		writeJSON(w, status, map[string]string{"__type": code, "message": message})
+	default:
+		writeJSON(w, status, map[string]interface{}{
+			"error": map[string]string{"code": code, "message": message},
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// today is the server's current day, at midnight UTC.
+func (s *Server) today() time.Time {
+	y, m, d := s.Now().UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// days returns each day from start up to but not including end.
+func days(start, end time.Time) []time.Time {
+	var out []time.Time
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		out = append(out, d)
+	}
+	return out
+}
+
+// parseDay parses the date part of an API date or timestamp.
+func parseDay(s string) (time.Time, error) {
+	if len(s) > len(dateLayout) {
+		s = s[:len(dateLayout)]
+	}
+	return time.Parse(dateLayout, s)
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,171 @@
+package fakecloud
+
+import (
+	"context"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud/aws"
+	"github.com/azguard/azguard/internal/cloud/azure"
+)
+
+const testSubscription = "11111111-2222-3333-4444-555555555555"
+
+// today is the fake server's clock in these tests.
+var today = time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+func testScenario() *Scenario {
+	return &Scenario{
+		Currency: "EUR",
+		Azure: AzureScenario{
+			QuotaID: "FreeTrial_2014-09-01",
+			DailyCosts: []DailyCost{
+				{Service: "Storage", Cost: 0.5},
+				{Service: "Virtual Machines", Cost: 2, From: "2024-05-05"},
+			},
+		},
+		AWS: AWSScenario{
+			AccountID: "123456789012",
+			DailyCosts: []DailyCost{
+				{Service: "Amazon Simple Storage Service", Cost: 0.25},
+			},
+			Plan: &Plan{Credits: 180, Expires: "2024-11-01", Status: "ACTIVE"},
+		},
+	}
+}
+
+// startServer serves the scenario and points the real Azure and AWS clients
+// at it through their endpoint overrides.
+func startServer(t *testing.T, s *Scenario) (*azure.CostClient, *aws.CostClient) {
+	t.Helper()
+	fake := NewServer(s)
+	fake.Now = func() time.Time { return today }
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	saved := azure.AzureManagementURL
+	azure.AzureManagementURL = server.URL
+	t.Cleanup(func() { azure.AzureManagementURL = saved })
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+	azureClient := azure.NewCostClient(testSubscription, func() (string, error) { return "token", nil })
+	awsClient := &aws.CostClient{
+		Region:      "us-east-1",
+		Credentials: aws.StaticProvider{AccessKey: "AKID", SecretKey: "secret"},
+		Endpoints:   aws.NewEndpointResolver(nil),
+		HTTP:        server.Client(),
+	}
+	return azureClient, awsClient
+}
+
+func TestAzureClientAgainstScenario(t *testing.T) {
+	azureClient, _ := startServer(t, testScenario())
+	ctx := context.Background()
+
+	// May 1-10: storage every day, VMs from the 5th.
+	result, err := azureClient.QueryCostsByService(ctx, "2024-05-01", "2024-05-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.TotalCost-17) > 1e-9 || result.Currency != "EUR" {
+		t.Errorf("QueryCostsByService() = %.2f %s, want 17.00 EUR up to today", result.TotalCost, result.Currency)
+	}
+
+	// May 11-31 is 21 days of both services.
+	forecast, err := azureClient.GetForecast(ctx, "Daily", "2024-05-11", "2024-05-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(forecast.TotalCost-52.5) > 1e-9 {
+		t.Errorf("GetForecast() = %.2f, want 52.50", forecast.TotalCost)
+	}
+
+	sub, err := azureClient.GetSubscription(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.QuotaID != "FreeTrial_2014-09-01" {
+		t.Errorf("quota ID = %q, want the scenario's offer", sub.QuotaID)
+	}
+}
+
+func TestAWSClientAgainstScenario(t *testing.T) {
+	_, awsClient := startServer(t, testScenario())
+	ctx := context.Background()
+
+	records, err := awsClient.GetDailyCosts(ctx, "2024-05-01", "2024-05-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0.0
+	for _, r := range records {
+		total += r.Cost
+	}
+	if len(records) != 10 || math.Abs(total-2.5) > 1e-9 {
+		t.Errorf("GetDailyCosts() = %d records, $%.2f; want 10 days, $2.50", len(records), total)
+	}
+
+	plan, err := awsClient.GetAccountPlanState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.IsFreePlan() || plan.RemainingCredits != 180 || plan.Status != aws.PlanStatusActive {
+		t.Errorf("GetAccountPlanState() = %+v, want an active free plan with $180", plan)
+	}
+}
+
+func TestScenarioErrors(t *testing.T) {
+	s := testScenario()
+	s.Errors = []Fault{
+		{API: "azure.query", Status: 500},
+		{API: "aws", Status: 403, Code: "AccessDeniedException", Message: "not authorized"},
+	}
+	azureClient, awsClient := startServer(t, s)
+	ctx := context.Background()
+
+	if _, err := azureClient.QueryCostsByService(ctx, "2024-05-01", "2024-05-31"); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("QueryCostsByService() error = %v, want status 500", err)
+	}
+	// Other Azure operations are unaffected.
+	if _, err := azureClient.GetSubscription(ctx); err != nil {
+		t.Errorf("GetSubscription() error = %v", err)
+	}
+	// A fault on a whole cloud fails each of its operations.
+	if _, err := awsClient.GetDailyCosts(ctx, "2024-05-01", "2024-05-11"); err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+		t.Errorf("GetDailyCosts() error = %v, want AccessDeniedException", err)
+	}
+	if _, err := awsClient.GetAccountPlanState(ctx); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("GetAccountPlanState() error = %v, want status 403", err)
+	}
+}
+
+func TestScenarioThrottling(t *testing.T) {
+	s := testScenario()
+	s.Throttle = []Fault{
+		{API: "azure.query", Times: 2},
+		{API: "aws.GetCostAndUsage", Times: 1},
+	}
+	azureClient, awsClient := startServer(t, s)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := azureClient.QueryCostsByService(ctx, "2024-05-01", "2024-05-31"); err == nil || !strings.Contains(err.Error(), "status 429") {
+			t.Errorf("call %d: QueryCostsByService() error = %v, want status 429", i+1, err)
+		}
+	}
+	if _, err := azureClient.QueryCostsByService(ctx, "2024-05-01", "2024-05-31"); err != nil {
+		t.Errorf("QueryCostsByService() after throttling = %v, want success", err)
+	}
+
+	// AWS reports throttling as a 400 with its own error code.
+	_, err := awsClient.GetDailyCosts(ctx, "2024-05-01", "2024-05-11")
+	if err == nil || !strings.Contains(err.Error(), "status 400") || !strings.Contains(err.Error(), "ThrottlingException") {
+		t.Errorf("GetDailyCosts() error = %v, want a 400 ThrottlingException", err)
+	}
+	if _, err := awsClient.GetDailyCosts(ctx, "2024-05-01", "2024-05-11"); err != nil {
+		t.Errorf("GetDailyCosts() after throttling = %v, want success", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package focus maps stored cost records to the FinOps Open Cost and Usage
+// Specification (FOCUS), so Azure, AWS and GCP spend share one schema.
+//
+// Only the columns azguard can fill from what it stores are written. Custom
+// columns use the x_ prefix the specification reserves for them.
+package focus
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// Columns is the FOCUS CSV header in output order.
+var Columns = []string{
+	"BillingAccountId",
+	"BillingCurrency",
+	"BillingPeriodStart",
+	"BillingPeriodEnd",
+	"ChargePeriodStart",
+	"ChargePeriodEnd",
+	"ChargeCategory",
+	"BilledCost",
+	"EffectiveCost",
+	"ProviderName",
+	"PublisherName",
+	"InvoiceIssuerName",
+	"ServiceName",
+	"ServiceCategory",
+	"SubAccountId",
+	"ResourceId",
+	"x_ResourceGroup",
+}
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// Row is one FOCUS charge row.
+type Row struct {
+	BillingAccountID   string
+	BillingCurrency    string
+	BillingPeriodStart time.Time
+	BillingPeriodEnd   time.Time
+	ChargePeriodStart  time.Time
+	ChargePeriodEnd    time.Time
+	ChargeCategory     string
+	BilledCost         float64
+	EffectiveCost      float64
+	ProviderName       string
+	PublisherName      string
+	InvoiceIssuerName  string
+	ServiceName        string
+	ServiceCategory    string
+	SubAccountID       string
+	ResourceID         string
+	ResourceGroup      string
+}
+
+var providerNames = map[string]string{
+	"azure": "Microsoft",
+	"aws":   "AWS",
+	"gcp":   "Google Cloud",
+}
+
+// ProviderName returns the FOCUS ProviderName for an azguard provider.
+func ProviderName(provider string) string {
+	if name, ok := providerNames[provider]; ok {
+		return name
+	}
+	return provider
+}
+
+// ProviderFromName maps a FOCUS ProviderName back to an azguard provider.
+func ProviderFromName(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "microsoft", "azure", "microsoft azure":
+		return "azure"
+	case "aws", "amazon web services":
+		return "aws"
+	case "google cloud", "gcp", "google", "google cloud platform":
+		return "gcp"
+	}
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// FromRecord converts a daily cost record. The charge period is the record's
+// day and the billing period its calendar month. Stored costs are what was
+// billed after discounts, so BilledCost and EffectiveCost are the same.
+func FromRecord(r storage.CostRecord) (Row, error) {
+	day, err := time.Parse("2006-01-02", r.Date)
+	if err != nil {
+		return Row{}, err
+	}
+	month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	subAccount := r.AccountID
+	if r.Provider == "azure" || subAccount == "" {
+		subAccount = r.SubscriptionID
+	}
+
+	category := "Usage"
+	if r.Cost < 0 {
+		category = "Credit"
+	}
+
+	name := ProviderName(r.Provider)
+	return Row{
+		BillingAccountID:   subAccount,
+		BillingCurrency:    r.Currency,
+		BillingPeriodStart: month,
+		BillingPeriodEnd:   month.AddDate(0, 1, 0),
+		ChargePeriodStart:  day,
+		ChargePeriodEnd:    day.AddDate(0, 0, 1),
+		ChargeCategory:     category,
+		BilledCost:         r.Cost,
+		EffectiveCost:      r.Cost,
+		ProviderName:       name,
+		PublisherName:      name,
+		InvoiceIssuerName:  name,
+		ServiceName:        r.ServiceName,
+		ServiceCategory:    ServiceCategory(r.ServiceName),
+		SubAccountID:       subAccount,
+		ResourceID:         r.ResourceID,
+		ResourceGroup:      r.ResourceGroup,
+	}, nil
+}
+
+// categoryKeywords assigns FOCUS service categories by service name; the
+// first match wins, so more specific keywords come first.
+var categoryKeywords = []struct {
+	keyword, category string
+}{
+	{"cosmos", "Databases"},
+	{"dynamodb", "Databases"},
+	{"sql", "Databases"},
+	{"rds", "Databases"},
+	{"relational database", "Databases"},
+	{"cloudwatch", "Management and Governance"},
+	{"monitor", "Management and Governance"},
+	{"cloudfront", "Networking"},
+	{"bandwidth", "Networking"},
+	{"load balanc", "Networking"},
+	{"network", "Networking"},
+	{"virtual private cloud", "Networking"},
+	{"dns", "Networking"},
+	{"storage", "Storage"},
+	{"s3", "Storage"},
+	{"ebs", "Storage"},
+	{"disk", "Storage"},
+	{"lambda", "Compute"},
+	{"functions", "Compute"},
+	{"virtual machines", "Compute"},
+	{"compute", "Compute"},
+	{"ec2", "Compute"},
+	{"app service", "Compute"},
+	{"container", "Compute"},
+	{"kubernetes", "Compute"},
+}
+
+// ServiceCategory guesses the FOCUS ServiceCategory for a service name.
+func ServiceCategory(service string) string {
+	s := strings.ToLower(service)
+	for _, k := range categoryKeywords {
+		if strings.Contains(s, k.keyword) {
+			return k.category
+		}
+	}
+	return "Other"
+}
+
+// Writer writes FOCUS rows as CSV.
+type Writer struct {
+	csv *csv.Writer
+}
+
+// NewWriter writes the header and returns a writer for the rows.
+func NewWriter(w io.Writer) (*Writer, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Columns); err != nil {
+		return nil, err
+	}
+	return &Writer{csv: cw}, nil
+}
+
+func (w *Writer) Write(r Row) error {
+	return w.csv.Write([]string{
+		r.BillingAccountID,
+		r.BillingCurrency,
+		r.BillingPeriodStart.Format(timeFormat),
+		r.BillingPeriodEnd.Format(timeFormat),
+		r.ChargePeriodStart.Format(timeFormat),
+		r.ChargePeriodEnd.Format(timeFormat),
+		r.ChargeCategory,
+		strconv.FormatFloat(r.BilledCost, 'f', -1, 64),
+		strconv.FormatFloat(r.EffectiveCost, 'f', -1, 64),
+		r.ProviderName,
+		r.PublisherName,
+		r.InvoiceIssuerName,
+		r.ServiceName,
+		r.ServiceCategory,
+		r.SubAccountID,
+		r.ResourceID,
+		r.ResourceGroup,
+	})
+}
+
+// Flush writes any buffered rows and reports a write error.
+func (w *Writer) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
@@ -0,0 +1,153 @@
+package focus
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func TestFromRecord(t *testing.T) {
+	row, err := FromRecord(storage.CostRecord{
+		Provider:       "azure",
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "rg-web",
+		ResourceID:     "/vm1",
+		ServiceName:    "Virtual Machines",
+		Cost:           2.5,
+		Currency:       "EUR",
+		Date:           "2024-02-29",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	want := Row{
+		BillingAccountID:   "sub-1",
+		BillingCurrency:    "EUR",
+		BillingPeriodStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		BillingPeriodEnd:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		ChargePeriodStart:  day,
+		ChargePeriodEnd:    day.AddDate(0, 0, 1),
+		ChargeCategory:     "Usage",
+		BilledCost:         2.5,
+		EffectiveCost:      2.5,
+		ProviderName:       "Microsoft",
+		PublisherName:      "Microsoft",
+		InvoiceIssuerName:  "Microsoft",
+		ServiceName:        "Virtual Machines",
+		ServiceCategory:    "Compute",
+		SubAccountID:       "sub-1",
+		ResourceID:         "/vm1",
+		ResourceGroup:      "rg-web",
+	}
+	if row != want {
+		t.Errorf("FromRecord() =\n%+v\nwant\n%+v", row, want)
+	}
+}
+
+func TestFromRecordAccountsAndCredits(t *testing.T) {
+	aws, err := FromRecord(storage.CostRecord{Provider: "aws", AccountID: "111122223333", SubscriptionID: "ignored", Cost: -1, Date: "2024-12-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.SubAccountID != "111122223333" || aws.ChargeCategory != "Credit" || aws.ProviderName != "AWS" {
+		t.Errorf("aws row = %+v", aws)
+	}
+	if !aws.BillingPeriodEnd.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("BillingPeriodEnd = %v, want the next January", aws.BillingPeriodEnd)
+	}
+
+	if _, err := FromRecord(storage.CostRecord{Provider: "gcp", Date: "05/01/2024"}); err == nil {
+		t.Error("FromRecord() accepted a malformed date")
+	}
+}
+
+func TestProviderNames(t *testing.T) {
+	for _, provider := range []string{"azure", "aws", "gcp"} {
+		if got := ProviderFromName(ProviderName(provider)); got != provider {
+			t.Errorf("ProviderFromName(ProviderName(%q)) = %q", provider, got)
+		}
+	}
+
+	aliases := map[string]string{
+		"Microsoft Azure":       "azure",
+		" amazon web services ": "aws",
+		"Google Cloud Platform": "gcp",
+		"Oracle":                "oracle",
+	}
+	for name, want := range aliases {
+		if got := ProviderFromName(name); got != want {
+			t.Errorf("ProviderFromName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestServiceCategory(t *testing.T) {
+	tests := map[string]string{
+		"Virtual Machines":                       "Compute",
+		"Amazon Elastic Compute Cloud - Compute": "Compute",
+		"AWS Lambda":                             "Compute",
+		"Azure Cosmos DB":                        "Databases",
+		"Amazon Relational Database Service":     "Databases",
+		"SQL Database":                           "Databases",
+		"Amazon Simple Storage Service":          "Storage",
+		"Cloud Storage":                          "Storage",
+		"Bandwidth":                              "Networking",
+		"Amazon CloudWatch":                      "Management and Governance",
+		"Azure Monitor":                          "Management and Governance",
+		"Key Vault":                              "Other",
+	}
+	for service, want := range tests {
+		if got := ServiceCategory(service); got != want {
+			t.Errorf("ServiceCategory(%q) = %q, want %q", service, got, want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	row, err := FromRecord(storage.CostRecord{Provider: "gcp", AccountID: "proj-1", ServiceName: "Compute Engine", Cost: 0.1, Currency: "USD", Date: "2024-05-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || len(lines[0]) != len(Columns) || len(lines[1]) != len(Columns) {
+		t.Fatalf("wrote %v", lines)
+	}
+	got := make(map[string]string)
+	for i, name := range lines[0] {
+		got[name] = lines[1][i]
+	}
+	want := map[string]string{
+		"ChargePeriodStart": "2024-05-01T00:00:00Z",
+		"ChargePeriodEnd":   "2024-05-02T00:00:00Z",
+		"BilledCost":        "0.1",
+		"ProviderName":      "Google Cloud",
+		"SubAccountId":      "proj-1",
+		"ServiceCategory":   "Compute",
+	}
+	for name, v := range want {
+		if got[name] != v {
+			t.Errorf("%s = %q, want %q", name, got[name], v)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+// Package httprec records the HTTP exchanges of the cloud clients to a
+// directory, with credentials and account IDs redacted, and replays them
+// later so a problem can be reproduced offline.
+package httprec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Environment variables that turn on recording or replay.
+const (
+	RecordEnv = "AZGUARD_RECORD"
+	ReplayEnv = "AZGUARD_REPLAY"
+)
+
+// Redacted replaces secrets in recordings.
+const Redacted = "REDACTED"
+
+// ReplaySubscriptionID stands in for the Azure subscription when replaying
+// without one configured; IDs are ignored when matching requests.
+const ReplaySubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// Exchange is one recorded request and its response, stored as a JSON file.
+type Exchange struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	RequestBody     string            `json:"request_body,omitempty"`
+	Status          int               `json:"status"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    string            `json:"response_body"`
+}
+
+// recordedRequestHeaders are the request headers worth keeping; the rest,
+// including every credential header, are dropped.
+var recordedRequestHeaders = []string{"Content-Type", "X-Amz-Target", "Accept"}
+
+var replaying *Replayer
+
+// Install wraps http.DefaultTransport, which every cloud client uses, in a
+// Recorder or Replayer when AZGUARD_RECORD or AZGUARD_REPLAY is set.
+func Install() error {
+	recordDir, replayDir := os.Getenv(RecordEnv), os.Getenv(ReplayEnv)
+	switch {
+	case recordDir != "" && replayDir != "":
+		return fmt.Errorf("%s and %s cannot be used together", RecordEnv, ReplayEnv)
+	case recordDir != "":
+		rec, err := NewRecorder(recordDir, http.DefaultTransport)
+		if err != nil {
+			return err
+		}
+		http.DefaultTransport = rec
+	case replayDir != "":
+		rep, err := NewReplayer(replayDir)
+		if err != nil {
+			return err
+		}
+		http.DefaultTransport = rep
+		replaying = rep
+	}
+	return nil
+}
+
+// Replaying returns the installed Replayer, or nil when not replaying.
+func Replaying() *Replayer {
+	return replaying
+}
+
+// Recorder passes requests on to a base transport and writes each exchange
+// to its directory as NNNN-METHOD-host.json.
+type Recorder struct {
+	dir  string
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	seq      int
+	redactor *redactor
+}
+
+func NewRecorder(dir string, base http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	// Continue numbering after earlier recordings in the directory, so
+	// several commands can be recorded into one directory.
+	existing, err := exchangeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	seq := 0
+	for _, name := range existing {
+		if n, err := strconv.Atoi(strings.SplitN(filepath.Base(name), "-", 2)[0]); err == nil && n > seq {
+			seq = n
+		}
+	}
+	return &Recorder{dir: dir, base: base, seq: seq, redactor: newRedactor()}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ex := Exchange{
+		Method:          req.Method,
+		URL:             r.redactor.redact(req.URL.String()),
+		RequestHeaders:  make(map[string]string),
+		RequestBody:     r.redactor.redact(string(reqBody)),
+		Status:          resp.StatusCode,
+		ResponseHeaders: make(map[string]string),
+		ResponseBody:    r.redactor.redact(string(respBody)),
+	}
+	for _, h := range recordedRequestHeaders {
+		if v := req.Header.Get(h); v != "" {
+			ex.RequestHeaders[h] = v
+		}
+	}
+	for h := range resp.Header {
+		if h == "Set-Cookie" {
+			continue
+		}
+		ex.ResponseHeaders[h] = r.redactor.redact(resp.Header.Get(h))
+	}
+
+	r.seq++
+	name := fmt.Sprintf("%04d-%s-%s.json", r.seq, req.Method, req.URL.Hostname())
+	b, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, name), b, 0600); err != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", name, err)
+	}
+	return resp, nil
+}
+
+// Replayer answers requests from recorded exchanges without calling the
+// network. A request is matched on its method, URL and body with account IDs
+// ignored; failing that, on the API operation alone, so recordings still
+// replay when dates in the request differ. Identical requests get the
+// recorded responses in order, and the last one again once they run out.
+type Replayer struct {
+	dir string
+
+	mu    sync.Mutex
+	exact map[string][]*Exchange
+	loose map[string][]*Exchange
+	hosts map[string]bool
+	aws   bool
+}
+
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := exchangeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", dir)
+	}
+
+	r := &Replayer{
+		dir:   dir,
+		exact: make(map[string][]*Exchange),
+		loose: make(map[string][]*Exchange),
+		hosts: make(map[string]bool),
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		ex := &Exchange{}
+		if err := json.Unmarshal(b, ex); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		u, err := url.Parse(ex.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		exact := exactKey(ex.Method, u, ex.RequestBody)
+		loose := looseKey(ex.Method, u, ex.RequestHeaders["X-Amz-Target"], ex.RequestBody)
+		r.exact[exact] = append(r.exact[exact], ex)
+		r.loose[loose] = append(r.loose[loose], ex)
+		r.hosts[u.Hostname()] = true
+		// AWS calls may go to an endpoint override, so they are known by
+		// their operation rather than the host.
+		if strings.HasSuffix(u.Hostname(), ".amazonaws.com") || awsOperation(u, ex.RequestHeaders["X-Amz-Target"], ex.RequestBody) != "" {
+			r.aws = true
+		}
+	}
+	return r, nil
+}
+
+// HasAWS reports whether any AWS API call was recorded.
+func (r *Replayer) HasAWS() bool {
+	return r.aws
+}
+
+// HasHost reports whether any exchange was recorded against a host ending
+// in suffix, e.g. ".amazonaws.com".
+func (r *Replayer) HasHost(suffix string) bool {
+	for host := range r.hosts {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	ex := next(r.exact, exactKey(req.Method, req.URL, string(body)))
+	if ex == nil {
+		ex = next(r.loose, looseKey(req.Method, req.URL, req.Header.Get("X-Amz-Target"), string(body)))
+	}
+	r.mu.Unlock()
+	if ex == nil {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, req.URL.Redacted(), r.dir)
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(ex.ResponseBody)),
+		ContentLength: int64(len(ex.ResponseBody)),
+		Request:       req,
+	}
+	for h, v := range ex.ResponseHeaders {
+		resp.Header.Set(h, v)
+	}
+	return resp, nil
+}
+
+// next pops the first exchange queued under key, keeping the last one.
+func next(queues map[string][]*Exchange, key string) *Exchange {
+	queue := queues[key]
+	if len(queue) == 0 {
+		return nil
+	}
+	if len(queue) > 1 {
+		queues[key] = queue[1:]
+	}
+	return queue[0]
+}
+
+func exactKey(method string, u *url.URL, body string) string {
+	return method + " " + normalizeIDs(u.Host+u.EscapedPath()+"?"+u.Query().Encode()) + " " + normalizeIDs(body)
+}
+
+// looseKey identifies the API operation: the path, plus the operation of
+// AWS APIs that share one path.
+func looseKey(method string, u *url.URL, target, body string) string {
+	return method + " " + normalizeIDs(u.Host+u.EscapedPath()) + " " + awsOperation(u, target, body)
+}
+
+// awsOperation returns the X-Amz-Target header of JSON APIs or the Action
+// parameter of query APIs such as STS, or "" for other requests.
+func awsOperation(u *url.URL, target, body string) string {
+	if target != "" {
+		return target
+	}
+	if form, err := url.ParseQuery(body); err == nil && form.Get("Action") != "" {
+		return form.Get("Action")
+	}
+	return u.Query().Get("Action")
+}
+
+func exchangeFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+var (
+	guidPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	digitsPattern = regexp.MustCompile(`\d+`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)("(?:access_?token|refresh_?token|id_?token|client_?secret|access_?key_?id|secret_?access_?key|session_?token|private_?key(?:_id)?|assertion)"\s*:\s*")[^"]*(")`),
+		regexp.MustCompile(`(<(?:AccessKeyId|SecretAccessKey|SessionToken)>)[^<]*(</)`),
+		regexp.MustCompile(`()\b(?:AKIA|ASIA)[A-Z0-9]{16}\b()`),
+		regexp.MustCompile(`(?i)(\b(?:client_secret|client_assertion|assertion|refresh_token|password|access_token)=)[^&\s"]*()`),
+	}
+)
+
+// redactor removes secrets and replaces subscription, tenant and account
+// IDs with numbered placeholders, the same ID always getting the same one.
+type redactor struct {
+	ids map[string]string
+}
+
+func newRedactor() *redactor {
+	return &redactor{ids: make(map[string]string)}
+}
+
+func (r *redactor) redact(s string) string {
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, "${1}"+Redacted+"${2}")
+	}
+	s = emailPattern.ReplaceAllString(s, "user@example.com")
+	s = guidPattern.ReplaceAllStringFunc(s, func(id string) string {
+		return r.placeholder(strings.ToLower(id), "00000000-0000-0000-0000-%012d")
+	})
+	return replaceAccounts(s, func(id string) string {
+		return r.placeholder(id, "%012d")
+	})
+}
+
+func (r *redactor) placeholder(id, format string) string {
+	if p, ok := r.ids[id]; ok {
+		return p
+	}
+	p := fmt.Sprintf(format, len(r.ids)+1)
+	r.ids[id] = p
+	return p
+}
+
+// normalizeIDs replaces every ID with the same zero value, so a live request
+// matches its recording whatever the real or placeholder IDs are.
+func normalizeIDs(s string) string {
+	s = guidPattern.ReplaceAllString(s, "00000000-0000-0000-0000-000000000000")
+	return replaceAccounts(s, func(string) string { return "000000000000" })
+}
+
+// replaceAccounts replaces AWS account IDs: runs of exactly 12 digits
+// where they appear as IDs, quoted, as an XML value, or between the colons
+// or slashes of an ARN or path. Amounts and timestamps are left alone.
+func replaceAccounts(s string, fn func(id string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range digitsPattern.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		if end-start != 12 || start == 0 || !strings.ContainsRune(`"':/>`, rune(s[start-1])) {
+			continue
+		}
+		if end < len(s) && !strings.ContainsRune(`"':/<`, rune(s[end])) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(fn(s[start:end]))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/azguard/azguard/internal/focus"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+// Column aliases cover the header spellings of the export versions in use:
+// Azure EA/MCA and pay-as-you-go exports, AWS CUR and CUR 2.0, and the GCP
+// BigQuery billing export and console cost table, and FOCUS.
+var (
+	azureDate          = []string{"Date", "UsageDate", "UsageDateTime"}
+	azureCost          = []string{"CostInBillingCurrency", "Cost", "PreTaxCost"}
+	azureCurrency      = []string{"BillingCurrency", "BillingCurrencyCode", "Currency"}
+	azureSubscription  = []string{"SubscriptionId", "SubscriptionGuid"}
+	azureResourceGroup = []string{"ResourceGroup", "ResourceGroupName"}
+	azureResource      = []string{"ResourceId", "InstanceId"}
+	azureService       = []string{"MeterCategory", "ServiceName", "ConsumedService"}
+	azureMeter         = []string{"MeterId", "MeterID"}
+	azureChargeType    = []string{"ChargeType"}
+
+	curLineItemID   = []string{"identity/LineItemId"}
+	curTimeInterval = []string{"identity/TimeInterval"}
+	curDate         = []string{"lineItem/UsageStartDate"}
+	curCost         = []string{"lineItem/UnblendedCost"}
+	curCurrency     = []string{"lineItem/CurrencyCode"}
+	curAccount      = []string{"lineItem/UsageAccountId"}
+	curResource     = []string{"lineItem/ResourceId"}
+	curService      = []string{"product/ProductName", "lineItem/ProductCode"}
+	curUsageType    = []string{"lineItem/UsageType"}
+	curOperation    = []string{"lineItem/Operation"}
+	curLineItemType = []string{"lineItem/LineItemType"}
+
+	gcpDate     = []string{"usage_start_time", "Usage start date"}
+	gcpCost     = []string{"cost", "Cost ($)"}
+	gcpCurrency = []string{"currency"}
+	gcpProject  = []string{"project.id", "Project ID"}
+	gcpResource = []string{"resource.global_name", "resource.name"}
+	gcpService  = []string{"service.description", "Service description"}
+	gcpSKU      = []string{"sku.id", "SKU ID"}
+	gcpCostType = []string{"cost_type", "Cost type"}
+
+	focusDate          = []string{"ChargePeriodStart"}
+	focusCost          = []string{"BilledCost"}
+	focusCurrency      = []string{"BillingCurrency"}
+	focusProvider      = []string{"ProviderName"}
+	focusSubAccount    = []string{"SubAccountId"}
+	focusResourceGroup = []string{"x_ResourceGroup"}
+	focusResource      = []string{"ResourceId"}
+	focusService       = []string{"ServiceName"}
+	focusSKU           = []string{"SkuId"}
+	focusCategory      = []string{"ChargeCategory"}
+)
+
+func init() {
+	formats["azure-export"] = format{
+		provider:   "azure",
+		required:   [][]string{azureDate, azureCost, azureService},
+		keyColumns: [][]string{azureDate, azureSubscription, azureResource, azureMeter, azureChargeType},
+		mapRow: func(c columns, row []string) (storage.CostRecord, error) {
+			return mapRecord(c, row, azureDate, azureCost, azureCurrency, azureService, azureResource, storage.CostRecord{
+				SubscriptionID: c.get(row, azureSubscription...),
+				ResourceGroup:  c.get(row, azureResourceGroup...),
+			})
+		},
+	}
+
+	formats["aws-cur"] = format{
+		provider:   "aws",
+		required:   [][]string{curDate, curCost, curService},
+		keyColumns: [][]string{curLineItemID, curTimeInterval, curDate, curAccount, curResource, curUsageType, curOperation, curLineItemType},
+		lineItemID: [][]string{curLineItemID, curTimeInterval},
+		mapRow: func(c columns, row []string) (storage.CostRecord, error) {
+			return mapRecord(c, row, curDate, curCost, curCurrency, curService, curResource, storage.CostRecord{
+				AccountID: c.get(row, curAccount...),
+			})
+		},
+	}
+
+	formats["gcp-export"] = format{
+		provider:   "gcp",
+		required:   [][]string{gcpDate, gcpCost, gcpService},
+		keyColumns: [][]string{gcpDate, gcpProject, gcpResource, gcpSKU, gcpCostType},
+		mapRow: func(c columns, row []string) (storage.CostRecord, error) {
+			return mapRecord(c, row, gcpDate, gcpCost, gcpCurrency, gcpService, gcpResource, storage.CostRecord{
+				AccountID: c.get(row, gcpProject...),
+			})
+		},
+	}
+
+	formats["focus-csv"] = format{
+		required:   [][]string{focusDate, focusCost, focusProvider, focusService},
+		keyColumns: [][]string{focusDate, focusProvider, focusSubAccount, focusResourceGroup, focusResource, focusService, focusSKU, focusCategory},
+		skipSynced: true,
+		mapRow: func(c columns, row []string) (storage.CostRecord, error) {
+			provider := focus.ProviderFromName(c.get(row, focusProvider...))
+			if provider == "" {
+				return storage.CostRecord{}, fmt.Errorf("missing ProviderName")
+			}
+			base := storage.CostRecord{
+				Provider:      provider,
+				ResourceGroup: c.get(row, focusResourceGroup...),
+			}
+			if provider == "azure" {
+				base.SubscriptionID = c.get(row, focusSubAccount...)
+			} else {
+				base.AccountID = c.get(row, focusSubAccount...)
+			}
+			return mapRecord(c, row, focusDate, focusCost, focusCurrency, focusService, focusResource, base)
+		},
+	}
+}
+
+// mapRecord fills the fields every format shares into base.
+func mapRecord(c columns, row []string, date, cost, currency, service, resource []string, base storage.CostRecord) (storage.CostRecord, error) {
+	var err error
+	if base.Date, err = parseDate(c.get(row, date...)); err != nil {
+		return base, err
+	}
+	if base.Cost, err = parseCost(c.get(row, cost...)); err != nil {
+		return base, err
+	}
+	base.Currency = c.get(row, currency...)
+	if base.Currency == "" {
+		base.Currency = "USD"
+	}
+	base.ServiceName = c.get(row, service...)
+	base.ResourceID = c.get(row, resource...)
+	return base, nil
+}
@@ -0,0 +1,325 @@
+// Package importer loads billing files downloaded from the cloud consoles
+// into the local cost database, for accounts that cannot grant API access.
+//
+// Files are read as a stream, plain or gzip-compressed, and written in
+// batches, so exports with millions of line items import without loading
+// the file into memory.
+package importer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/azguard/azguard/internal/storage"
+)
+
+const batchSize = 1000
+
+// format describes how one kind of billing file maps onto cost records.
+type format struct {
+	// provider is empty for formats that name the provider on each row.
+	provider string
+	// required lists alias groups; a file must have one column of each group.
+	required [][]string
+	// keyColumns identify a line item across exports, so a revised export
+	// replaces the costs it restates. They never include the cost: columns
+	// missing from the file count as empty, and rows sharing a key on the
+	// same day are told apart by their order in the file.
+	keyColumns [][]string
+	// lineItemID are the key columns that identify a line item on their own,
+	// like the CUR line item ID. Rows that have them are never numbered.
+	lineItemID [][]string
+	// skipSynced skips rows for a provider, account, service and day that
+	// already has records synced from the API. FOCUS files exported by
+	// azguard restate those records, so importing one into the database it
+	// came from would otherwise count every cost twice.
+	skipSynced bool
+	mapRow     func(c columns, row []string) (storage.CostRecord, error)
+}
+
+var formats = map[string]format{}
+
+// Formats returns the supported --format names.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Result summarizes an import.
+type Result struct {
+	Rows      int     `json:"rows"`
+	Added     int     `json:"added"`
+	Updated   int     `json:"updated"`
+	Skipped   int     `json:"skipped"`
+	TotalCost float64 `json:"total_cost"`
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+}
+
+// ImportFile imports a billing file in the named format.
+func ImportFile(db *storage.DB, formatName, path string) (*Result, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Import(db, formatName, f)
+}
+
+// Import reads a CSV billing file and stores its line items. Rows without a
+// date are skipped, as are zero-cost rows that do not clear a stored cost
+// and, for formats that restate synced costs, rows already synced.
+func Import(db *storage.DB, formatName string, r io.Reader) (*Result, error) {
+	spec, ok := formats[formatName]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q (use %s)", formatName, strings.Join(Formats(), ", "))
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	cols := newColumns(header)
+	for _, group := range spec.required {
+		if !cols.has(group...) {
+			return nil, fmt.Errorf("file is not in %s format: missing column %s", formatName, group[0])
+		}
+	}
+	// seen counts the rows of each key across the file, numbering repeated
+	// line items. Keys include the date, so repeats are counted per day
+	// however the export orders its rows.
+	seen := make(map[string]int)
+	// synced caches HasSyncedCost per provider, account, service and day.
+	synced := make(map[string]bool)
+
+	result := &Result{}
+	batch := make([]storage.CostRecord, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		added, updated, err := db.SaveImportedCostRecords(batch)
+		if err != nil {
+			return fmt.Errorf("failed to save records: %w", err)
+		}
+		result.Added += added
+		result.Updated += updated
+		result.Skipped += len(batch) - added - updated
+		batch = batch[:0]
+		return nil
+	}
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		result.Rows++
+
+		record, err := spec.mapRow(cols, row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.Date == "" {
+			result.Skipped++
+			continue
+		}
+		if record.Provider == "" {
+			record.Provider = spec.provider
+		}
+		if spec.skipSynced {
+			key := strings.Join([]string{record.Provider, record.SubscriptionID, record.AccountID, record.ServiceName, record.Date}, "\x00")
+			found, ok := synced[key]
+			if !ok {
+				if found, err = db.HasSyncedCost(record); err != nil {
+					return nil, fmt.Errorf("line %d: failed to look up synced costs: %w", line, err)
+				}
+				synced[key] = found
+			}
+			if found {
+				result.Skipped++
+				continue
+			}
+		}
+		record.ImportKey = importKey(record.Provider, spec.keyColumns, cols, row)
+		if !hasAll(cols, row, spec.lineItemID) {
+			seen[record.ImportKey]++
+			if n := seen[record.ImportKey]; n > 1 {
+				record.ImportKey += "#" + strconv.Itoa(n)
+			}
+		}
+
+		if record.Cost != 0 {
+			result.TotalCost += record.Cost
+			if result.StartDate == "" || record.Date < result.StartDate {
+				result.StartDate = record.Date
+			}
+			if record.Date > result.EndDate {
+				result.EndDate = record.Date
+			}
+		}
+
+		batch = append(batch, record)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Open opens a billing file, decompressing it when it is gzipped.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bufio.NewReader(f)
+	magic, _ := buf.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to open gzip file: %w", err)
+		}
+		return readCloser{gz, func() error { gz.Close(); return f.Close() }}, nil
+	}
+	return readCloser{buf, f.Close}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error { return r.close() }
+
+// hasAll reports whether the row has a value in each column group. It is
+// false when there are no groups.
+func hasAll(cols columns, row []string, groups [][]string) bool {
+	for _, group := range groups {
+		if cols.get(row, group...) == "" {
+			return false
+		}
+	}
+	return len(groups) > 0
+}
+
+// importKey hashes a row's key columns. The same line item in a revised
+// export gets the same key whatever its cost.
+func importKey(provider string, keyColumns [][]string, cols columns, row []string) string {
+	h := sha1.New()
+	for _, group := range keyColumns {
+		h.Write([]byte(cols.get(row, group...)))
+		h.Write([]byte{0x1f})
+	}
+	return provider + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// columns maps normalized header names to their index. Names are compared
+// lowercased with everything but letters and digits removed, so
+// "lineItem/UsageAccountId" (CUR) and "line_item_usage_account_id" (CUR 2.0)
+// are the same column.
+type columns map[string]int
+
+func newColumns(header []string) columns {
+	c := make(columns, len(header))
+	for i, name := range header {
+		key := normalize(name)
+		if _, dup := c[key]; !dup {
+			c[key] = i
+		}
+	}
+	return c
+}
+
+func normalize(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+func (c columns) has(names ...string) bool {
+	for _, n := range names {
+		if _, ok := c[normalize(n)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the first non-empty value among the aliased columns.
+func (c columns) get(row []string, names ...string) string {
+	for _, n := range names {
+		if i, ok := c[normalize(n)]; ok && i < len(row) {
+			if v := strings.TrimSpace(row[i]); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// parseCost reads an amount, ignoring currency symbols and thousands separators.
+func parseCost(s string) (float64, error) {
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cost %q", s)
+	}
+	return v, nil
+}
+
+// parseDate returns the YYYY-MM-DD day of an ISO date or timestamp, or of a
+// US-style MM/DD/YYYY date as written by older Azure exports.
+func parseDate(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if len(s) >= 10 && s[4] == '-' && s[7] == '-' {
+		if _, err := time.Parse("2006-01-02", s[:10]); err == nil {
+			return s[:10], nil
+		}
+	}
+	if t, err := time.Parse("1/2/2006", strings.Fields(s)[0]); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+	return "", fmt.Errorf("invalid date %q", s)
+}
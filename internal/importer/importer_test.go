@@ -0,0 +1,404 @@
+package importer
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azguard/azguard/internal/focus"
+	"github.com/azguard/azguard/internal/storage"
+)
+
+func newTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db, err := storage.New(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func storedTotal(t *testing.T, db *storage.DB) (float64, int) {
+	t.Helper()
+	records, err := db.GetCostRecords(storage.CostFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0.0
+	for _, r := range records {
+		total += r.Cost
+	}
+	return total, len(records)
+}
+
+func TestImportRevisedExportReplacesCosts(t *testing.T) {
+	tests := []struct {
+		format   string
+		original string
+		revised  string
+		// want is the revised file's total and line item count.
+		want  float64
+		items int
+	}{
+		{
+			format: "azure-export",
+			original: "Date,SubscriptionId,ResourceId,MeterId,ChargeType,MeterCategory,CostInBillingCurrency,BillingCurrency\n" +
+				"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+				"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+				"2024-05-01,sub-1,/disk1,meter-b,Usage,Storage,0.50,USD\n",
+			revised: "Date,SubscriptionId,ResourceId,MeterId,ChargeType,MeterCategory,CostInBillingCurrency,BillingCurrency\n" +
+				"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.25,USD\n" +
+				"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.25,USD\n" +
+				"2024-05-01,sub-1,/disk1,meter-b,Usage,Storage,0.75,USD\n",
+			want:  3.25,
+			items: 3,
+		},
+		{
+			format: "gcp-export",
+			original: "usage_start_time,project.id,resource.name,sku.id,cost_type,service.description,cost,currency\n" +
+				"2024-05-01T00:00:00Z,proj-1,vm-1,sku-a,regular,Compute Engine,2.00,USD\n" +
+				"2024-05-02T00:00:00Z,proj-1,vm-1,sku-a,regular,Compute Engine,2.00,USD\n",
+			revised: "usage_start_time,project.id,resource.name,sku.id,cost_type,service.description,cost,currency\n" +
+				"2024-05-01T00:00:00Z,proj-1,vm-1,sku-a,regular,Compute Engine,2.50,USD\n" +
+				"2024-05-02T00:00:00Z,proj-1,vm-1,sku-a,regular,Compute Engine,1.50,USD\n",
+			want:  4,
+			items: 2,
+		},
+		{
+			format: "focus-csv",
+			original: "ChargePeriodStart,ProviderName,SubAccountId,ResourceId,ServiceName,ChargeCategory,BilledCost,BillingCurrency\n" +
+				"2024-05-01T00:00:00Z,Microsoft,sub-1,/vm1,Virtual Machines,Usage,3.00,USD\n" +
+				"2024-05-01T00:00:00Z,AWS,111122223333,,Amazon EC2,Usage,3.00,USD\n",
+			revised: "ChargePeriodStart,ProviderName,SubAccountId,ResourceId,ServiceName,ChargeCategory,BilledCost,BillingCurrency\n" +
+				"2024-05-01T00:00:00Z,Microsoft,sub-1,/vm1,Virtual Machines,Usage,3.50,USD\n" +
+				"2024-05-01T00:00:00Z,AWS,111122223333,,Amazon EC2,Usage,1.00,USD\n",
+			want:  4.5,
+			items: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			db := newTestDB(t)
+
+			first, err := Import(db, tt.format, strings.NewReader(tt.original))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.Added != tt.items {
+				t.Errorf("first import added %d, want %d identical and distinct line items", first.Added, tt.items)
+			}
+
+			second, err := Import(db, tt.format, strings.NewReader(tt.revised))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if second.Added != 0 || second.Updated != tt.items {
+				t.Errorf("revised import added %d, updated %d, want 0 and %d", second.Added, second.Updated, tt.items)
+			}
+
+			total, n := storedTotal(t, db)
+			if math.Abs(total-tt.want) > 1e-9 || n != tt.items {
+				t.Errorf("stored $%.2f in %d records, want $%.2f in %d", total, n, tt.want, tt.items)
+			}
+		})
+	}
+}
+
+func TestImportRevisedExportClearsDroppedCharge(t *testing.T) {
+	db := newTestDB(t)
+	header := "Date,SubscriptionId,ResourceId,MeterId,ChargeType,MeterCategory,CostInBillingCurrency,BillingCurrency\n"
+	original := header +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+		"2024-05-01,sub-1,/disk1,meter-b,Usage,Storage,0.50,USD\n"
+	revised := header +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,0,USD\n" +
+		"2024-05-01,sub-1,/disk1,meter-b,Usage,Storage,0.50,USD\n" +
+		"2024-05-01,sub-1,/ip1,meter-c,Usage,Virtual Network,0,USD\n"
+
+	if _, err := Import(db, "azure-export", strings.NewReader(original)); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Import(db, "azure-export", strings.NewReader(revised))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 0 || result.Updated != 2 || result.Skipped != 1 {
+		t.Errorf("result = %+v, want 2 updated and the new free line item skipped", result)
+	}
+	if total, n := storedTotal(t, db); math.Abs(total-0.5) > 1e-9 || n != 2 {
+		t.Errorf("stored $%.2f in %d records, want $0.50 in 2", total, n)
+	}
+}
+
+func TestImportNumbersRepeatsPerDay(t *testing.T) {
+	db := newTestDB(t)
+	// The same meter on two days is two line items; repeats within a day are
+	// numbered, and numbering starts again on the next day.
+	file := "Date,SubscriptionId,ResourceId,MeterId,ChargeType,MeterCategory,CostInBillingCurrency,BillingCurrency\n" +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+		"2024-05-02,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+		"2024-05-02,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n"
+
+	for i := 0; i < 2; i++ {
+		if _, err := Import(db, "azure-export", strings.NewReader(file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total, n := storedTotal(t, db); math.Abs(total-4) > 1e-9 || n != 4 {
+		t.Errorf("stored $%.2f in %d records, want $4.00 in 4", total, n)
+	}
+}
+
+func TestImportNumbersRepeatsAcrossInterleavedDays(t *testing.T) {
+	db := newTestDB(t)
+	// Exports sorted by resource rather than date still number each day's
+	// repeats the same way, so a re-import replaces rather than adds.
+	header := "Date,SubscriptionId,ResourceId,MeterId,ChargeType,MeterCategory,CostInBillingCurrency,BillingCurrency\n"
+	file := header +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+		"2024-05-02,sub-1,/vm1,meter-a,Usage,Virtual Machines,2.00,USD\n" +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,3.00,USD\n" +
+		"2024-05-02,sub-1,/vm1,meter-a,Usage,Virtual Machines,4.00,USD\n"
+	sorted := header +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,1.00,USD\n" +
+		"2024-05-01,sub-1,/vm1,meter-a,Usage,Virtual Machines,3.00,USD\n" +
+		"2024-05-02,sub-1,/vm1,meter-a,Usage,Virtual Machines,2.00,USD\n" +
+		"2024-05-02,sub-1,/vm1,meter-a,Usage,Virtual Machines,4.00,USD\n"
+
+	if _, err := Import(db, "azure-export", strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if total, n := storedTotal(t, db); math.Abs(total-10) > 1e-9 || n != 4 {
+		t.Fatalf("stored $%.2f in %d records, want $10.00 in 4", total, n)
+	}
+	result, err := Import(db, "azure-export", strings.NewReader(sorted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 0 {
+		t.Errorf("result = %+v, want no new records", result)
+	}
+	if total, n := storedTotal(t, db); math.Abs(total-10) > 1e-9 || n != 4 {
+		t.Errorf("stored $%.2f in %d records, want $10.00 in 4", total, n)
+	}
+}
+
+func TestImportCURLineItemIDs(t *testing.T) {
+	db := newTestDB(t)
+	file := "identity/LineItemId,identity/TimeInterval,lineItem/UsageStartDate,lineItem/UsageAccountId,lineItem/ProductCode,lineItem/UnblendedCost\n" +
+		"li-1,2024-05-01T00:00:00Z/2024-05-02T00:00:00Z,2024-05-01T00:00:00Z,111122223333,AmazonEC2,0.40\n" +
+		"li-2,2024-05-01T00:00:00Z/2024-05-02T00:00:00Z,2024-05-01T00:00:00Z,111122223333,AmazonEC2,0.40\n"
+
+	for i := 0; i < 2; i++ {
+		if _, err := Import(db, "aws-cur", strings.NewReader(file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total, n := storedTotal(t, db); math.Abs(total-0.8) > 1e-9 || n != 2 {
+		t.Errorf("stored $%.2f in %d records, want $0.80 in 2", total, n)
+	}
+}
+
+func TestImportFOCUSExport(t *testing.T) {
+	// API records carry no resource ID, so two services of one day can only
+	// differ by name, and a repeated record must still count twice.
+	exported := []storage.CostRecord{
+		{Provider: "azure", SubscriptionID: "sub-1", ResourceGroup: "rg-web", ServiceName: "Storage", Cost: 1, Currency: "USD", Date: "2024-05-01"},
+		{Provider: "azure", SubscriptionID: "sub-1", ResourceGroup: "rg-web", ServiceName: "Storage", Cost: 1, Currency: "USD", Date: "2024-05-01"},
+		{Provider: "azure", SubscriptionID: "sub-1", ResourceGroup: "rg-web", ServiceName: "Bandwidth", Cost: 0.5, Currency: "USD", Date: "2024-05-01"},
+		{Provider: "aws", AccountID: "111122223333", ServiceName: "Amazon EC2", Cost: 2, Currency: "USD", Date: "2024-05-01"},
+	}
+
+	var buf bytes.Buffer
+	w, err := focus.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range exported {
+		row, err := focus.FromRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := newTestDB(t)
+	if _, err := Import(db, "focus-csv", bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	records, err := db.GetCostRecords(storage.CostFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(exported) {
+		t.Fatalf("imported %d records, want %d", len(records), len(exported))
+	}
+	byProvider := make(map[string]float64)
+	for _, r := range records {
+		byProvider[r.Provider] += r.Cost
+		if r.Provider == "azure" && (r.SubscriptionID != "sub-1" || r.ResourceGroup != "rg-web") {
+			t.Errorf("azure record = %+v, want the subscription and resource group back", r)
+		}
+		if r.Provider == "aws" && r.AccountID != "111122223333" {
+			t.Errorf("aws record = %+v, want the account back", r)
+		}
+	}
+	if byProvider["azure"] != 2.5 || byProvider["aws"] != 2 {
+		t.Errorf("totals = %v, want azure $2.50 and aws $2.00", byProvider)
+	}
+}
+
+func TestImportFOCUSRoundTripSkipsSyncedCosts(t *testing.T) {
+	db := newTestDB(t)
+	synced := []storage.CostRecord{
+		{Provider: "azure", SubscriptionID: "sub-1", ServiceName: "Storage", Cost: 1, Currency: "USD", Date: "2024-05-01"},
+		{Provider: "aws", AccountID: "111122223333", ServiceName: "Amazon EC2", Cost: 2, Currency: "USD", Date: "2024-05-01"},
+	}
+	if err := db.SaveCostRecords(synced); err != nil {
+		t.Fatal(err)
+	}
+
+	// The export restates the synced records and adds a day the database
+	// does not have.
+	var buf bytes.Buffer
+	w, err := focus.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra := storage.CostRecord{Provider: "aws", AccountID: "111122223333", ServiceName: "Amazon EC2", Cost: 3, Currency: "USD", Date: "2024-05-02"}
+	for _, r := range append(synced, extra) {
+		row, err := focus.FromRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Import(db, "focus-csv", bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 1 || result.Skipped != 2 || result.TotalCost != 3 {
+		t.Errorf("result = %+v, want 1 added and the 2 synced rows skipped", result)
+	}
+	if total, n := storedTotal(t, db); total != 6 || n != 3 {
+		t.Errorf("stored $%.2f in %d records, want $6.00 in 3", total, n)
+	}
+}
+
+func TestImportMapsColumns(t *testing.T) {
+	db := newTestDB(t)
+	// CUR 2.0 spells the columns in snake case.
+	file := "line_item_usage_start_date,line_item_unblended_cost,line_item_currency_code,line_item_usage_account_id,line_item_resource_id,product_product_name\n" +
+		"2024-05-03T00:00:00Z,\"1,234.50\",EUR,111122223333,i-0abc,Amazon Elastic Compute Cloud\n" +
+		"2024-05-04T00:00:00Z,0,EUR,111122223333,i-0abc,Amazon Elastic Compute Cloud\n"
+
+	result, err := Import(db, "aws-cur", strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows != 2 || result.Added != 1 || result.Skipped != 1 {
+		t.Errorf("result = %+v, want 2 rows, 1 added, 1 skipped", result)
+	}
+
+	records, err := db.GetCostRecords(storage.CostFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("stored %d records, want 1", len(records))
+	}
+	got := records[0]
+	want := storage.CostRecord{
+		ID:          got.ID,
+		Provider:    "aws",
+		AccountID:   "111122223333",
+		ResourceID:  "i-0abc",
+		ServiceName: "Amazon Elastic Compute Cloud",
+		Cost:        1234.5,
+		Currency:    "EUR",
+		Date:        "2024-05-03",
+	}
+	if got != want {
+		t.Errorf("record = %+v, want %+v", got, want)
+	}
+}
+
+func TestImportRejectsMissingColumns(t *testing.T) {
+	db := newTestDB(t)
+	_, err := Import(db, "azure-export", strings.NewReader("Date,MeterCategory\n2024-05-01,Storage\n"))
+	if err == nil || !strings.Contains(err.Error(), "missing column CostInBillingCurrency") {
+		t.Errorf("Import() error = %v, want a missing cost column", err)
+	}
+}
+
+func TestImportKey(t *testing.T) {
+	spec := formats["azure-export"]
+	cols := newColumns([]string{"Date", "SubscriptionId", "ResourceId", "MeterId", "ChargeType", "MeterCategory", "CostInBillingCurrency"})
+	row := []string{"2024-05-01", "sub-1", "/vm1", "meter-a", "Usage", "Virtual Machines", "1.00"}
+	key := importKey("azure", spec.keyColumns, cols, row)
+
+	if !strings.HasPrefix(key, "azure:") {
+		t.Errorf("key %q lacks the provider prefix", key)
+	}
+
+	revised := append([]string(nil), row...)
+	revised[6] = "9.99"
+	if got := importKey("azure", spec.keyColumns, cols, revised); got != key {
+		t.Error("a changed cost changed the import key")
+	}
+
+	for i, name := range []string{"Date", "SubscriptionId", "ResourceId", "MeterId", "ChargeType"} {
+		changed := append([]string(nil), row...)
+		changed[i] += "x"
+		if importKey("azure", spec.keyColumns, cols, changed) == key {
+			t.Errorf("a changed %s kept the import key", name)
+		}
+	}
+
+	if importKey("aws", spec.keyColumns, cols, row) == key {
+		t.Error("the provider does not change the import key")
+	}
+}
+
+func TestParseCostAndDate(t *testing.T) {
+	costs := map[string]float64{"": 0, "1.5": 1.5, "$1,234.50": 1234.5, "-0.25": -0.25}
+	for in, want := range costs {
+		if got, err := parseCost(in); err != nil || got != want {
+			t.Errorf("parseCost(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseCost("n/a"); err == nil {
+		t.Error("parseCost(\"n/a\") succeeded")
+	}
+
+	dates := map[string]string{
+		"2024-05-01":           "2024-05-01",
+		"2024-05-01T13:00:00Z": "2024-05-01",
+		"5/1/2024":             "2024-05-01",
+		"05/01/2024 00:00:00":  "2024-05-01",
+	}
+	for in, want := range dates {
+		if got, err := parseDate(in); err != nil || got != want {
+			t.Errorf("parseDate(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := parseDate("yesterday"); err == nil {
+		t.Error("parseDate(\"yesterday\") succeeded")
+	}
+}
@@ -0,0 +1,210 @@
+// Package providers adapts the Azure, AWS and GCP clients to the
+// cloud.Provider interface.
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	awscloud "github.com/azguard/azguard/internal/cloud/aws"
+	"github.com/azguard/azguard/internal/cost"
+)
+
+// AWS is an AWS account seen through its CostClient.
+type AWS struct {
+	Client *awscloud.CostClient
+	// Regions limits Inventory to these regions; empty means every
+	// enabled region.
+	Regions []string
+}
+
+func NewAWS(client *awscloud.CostClient) *AWS {
+	return &AWS{Client: client}
+}
+
+func (p *AWS) Name() string { return "aws" }
+
+func (p *AWS) IsConfigured() bool { return p.Client.IsConfigured() }
+
+func (p *AWS) Account(ctx context.Context) (string, error) {
+	accountID, err := p.Client.GetCallerIdentity(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up AWS account: %w", err)
+	}
+	return accountID, nil
+}
+
+func (p *AWS) DailyCosts(ctx context.Context, startDate, endDate string) ([]cloud.CostRecord, error) {
+	accountID, err := p.Account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := p.Client.GetDailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]cloud.CostRecord, len(costs))
+	for i, r := range costs {
+		records[i] = cloud.CostRecord{
+			AccountID:   accountID,
+			ServiceName: r.ServiceName,
+			Cost:        r.Cost,
+			Currency:    r.Currency,
+			Date:        r.Date,
+		}
+	}
+	return records, nil
+}
+
+func (p *AWS) FreeTierUsage(ctx context.Context) ([]cloud.FreeTierUsage, error) {
+	catalog, err := cost.LoadFreeTierCatalog("aws")
+	if err != nil {
+		return nil, err
+	}
+	usages, err := p.Client.GetFreeTierUsage(ctx)
+	if errors.Is(err, awscloud.ErrUnsupportedService) {
+		return nil, fmt.Errorf("the AWS Free Tier API is %w outside the commercial partition", cloud.ErrNotSupported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch free tier usage: %w", err)
+	}
+	return AWSFreeTierUsage(catalog, usages), nil
+}
+
+// AWSFreeTierUsage converts Free Tier API rows, taking the warning threshold
+// and a missing description from the matching catalog entry.
+func AWSFreeTierUsage(catalog *cost.FreeTierConfig, usages []awscloud.FreeTierUsage) []cloud.FreeTierUsage {
+	result := make([]cloud.FreeTierUsage, len(usages))
+	for i, u := range usages {
+		_, limit := catalog.MatchUsage(u.ServiceName, u.UsageType)
+		description := u.Description
+		if description == "" && limit != nil {
+			description = limit.Description
+		}
+		result[i] = cloud.FreeTierUsage{
+			Key:         u.UsageType,
+			Name:        u.ServiceName,
+			Description: description,
+			Used:        u.ActualUsage,
+			Forecast:    u.ForecastUsage,
+			Limit:       u.FreeTierLimit,
+			Unit:        u.Unit,
+			PercentUsed: u.PercentUsed,
+			Threshold:   limit.Threshold(),
+		}
+	}
+	return result
+}
+
+// awsPlanStatuses names the Free Tier API plan statuses for display.
+var awsPlanStatuses = map[string]string{
+	awscloud.PlanStatusNotStarted: "not started",
+	awscloud.PlanStatusActive:     "active",
+	awscloud.PlanStatusExpired:    "expired",
+}
+
+// Credit returns the account plan and, on the free plan, the credits left.
+func (p *AWS) Credit(ctx context.Context) (*cloud.Credit, error) {
+	state, err := p.Client.GetAccountPlanState(ctx)
+	if errors.Is(err, awscloud.ErrUnsupportedService) {
+		// Free plans only exist in the commercial partition.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if state.PlanType == awscloud.PlanTypePaid {
+		return &cloud.Credit{Currency: state.Currency, Plan: "Paid", Paid: true}, nil
+	}
+	if !state.IsFreePlan() {
+		return nil, nil
+	}
+	plan := "Free"
+	if status, ok := awsPlanStatuses[state.Status]; ok {
+		plan += " (" + status + ")"
+	}
+	return &cloud.Credit{
+		Remaining: state.RemainingCredits,
+		Currency:  state.Currency,
+		Expires:   state.ExpirationDate,
+		Plan:      plan,
+		Expired:   state.Status == awscloud.PlanStatusExpired || state.DaysRemaining(time.Now()) == 0,
+	}, nil
+}
+
+func (p *AWS) Inventory(ctx context.Context) (*cloud.Inventory, error) {
+	found, err := p.Client.ListResources(ctx, p.Regions)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &cloud.Inventory{
+		Provider: p.Name(),
+		Regions:  found.Regions,
+		Errors:   found.Errors,
+	}
+	if accountID, err := p.Client.GetCallerIdentity(ctx); err == nil {
+		inv.Account = accountID
+	}
+
+	awayBillable := make(map[string]int)
+	for _, r := range found.Resources {
+		inv.Resources = append(inv.Resources, cloud.Resource{
+			Region:   r.Region,
+			Type:     r.Type,
+			ID:       r.ID,
+			Name:     r.Name,
+			Detail:   r.Detail,
+			SizeGB:   r.SizeGB,
+			FreeTier: r.FreeTier,
+			Note:     r.Note,
+		})
+		if !r.FreeTier && r.Region != "" && r.Region != p.Client.Region {
+			awayBillable[r.Region]++
+		}
+	}
+
+	if gb := found.EBSTotalGB(); gb > awscloud.FreeTierEBSGB {
+		inv.Warnings = append(inv.Warnings, fmt.Sprintf("%d GB of EBS volumes, above the %d GB free tier", gb, awscloud.FreeTierEBSGB))
+	}
+	if hours := found.PublicIPv4Hours(); hours > awscloud.FreeTierPublicIPv4Hours {
+		inv.Warnings = append(inv.Warnings, fmt.Sprintf("About %d public IPv4 hours a month, above the %d free in the first year", hours, awscloud.FreeTierPublicIPv4Hours))
+	}
+	if len(awayBillable) > 0 {
+		away := make([]string, 0, len(awayBillable))
+		for r, n := range awayBillable {
+			away = append(away, fmt.Sprintf("%s (%d)", r, n))
+		}
+		sort.Strings(away)
+		inv.Warnings = append(inv.Warnings, fmt.Sprintf("Billable resources outside %s: %s", p.Client.Region, strings.Join(away, ", ")))
+	}
+	return inv, nil
+}
+
+func (p *AWS) Forecast(ctx context.Context) (*cloud.Forecast, error) {
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	actual, err := p.Client.QueryCostsByService(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AWS costs: %w", err)
+	}
+
+	forecastStart, forecastEnd := awscloud.RemainingMonth()
+	f, err := p.Client.GetCostForecast(ctx, forecastStart, forecastEnd, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloud.Forecast{
+		MonthToDate: actual.TotalCost,
+		MonthEnd:    actual.TotalCost + f.Mean,
+		Lower:       actual.TotalCost + f.Lower,
+		Upper:       actual.TotalCost + f.Upper,
+		Currency:    f.Currency,
+	}, nil
+}
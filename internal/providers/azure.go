@@ -0,0 +1,312 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/cost"
+)
+
+// AzureFreeCredit is the Free Trial (MS-AZR-0044P) credit and
+// AzureStudentCredit the Azure for Students (MS-AZR-0170P) credit, in USD.
+const (
+	AzureFreeCredit    = 200.0
+	AzureStudentCredit = 100.0
+)
+
+// azureOfferCredits maps the quota ID prefix of the offers that come with a
+// sign-up credit to its amount. Other offers, such as pay-as-you-go, have none.
+var azureOfferCredits = map[string]float64{
+	"FreeTrial_":        AzureFreeCredit,
+	"AzureForStudents_": AzureStudentCredit,
+}
+
+// AzureOfferCredit returns the sign-up credit of a subscription's quota ID,
+// or 0 when its offer has none.
+func AzureOfferCredit(quotaID string) float64 {
+	for prefix, credit := range azureOfferCredits {
+		if strings.HasPrefix(strings.ToLower(quotaID), strings.ToLower(prefix)) {
+			return credit
+		}
+	}
+	return 0
+}
+
+// azureServiceAllowances approximates the dollar value of a month of each
+// service's free allowance, e.g. 750 hours of a B1s VM.
+var azureServiceAllowances = map[string]float64{
+	"virtual machines": 0.01 * 750,
+	"virtualmachine":   0.01 * 750,
+	"storage":          0.023 * 5,
+	"blob storage":     0.023 * 5,
+	"app service":      0.05 * 750,
+	"appservice":       0.05 * 750,
+}
+
+// azureMonthCostsTTL is how long this month's costs are reused. Status reads
+// them for spend, free tier usage, credit and forecast, and Cost Management
+// throttles repeated queries.
+const azureMonthCostsTTL = time.Minute
+
+// Azure is an Azure subscription seen through its CostClient.
+type Azure struct {
+	Client *azure.CostClient
+
+	// credit caches the offer's sign-up credit once looked up.
+	credit *float64
+
+	mu           sync.Mutex
+	month        []cloud.CostRecord
+	monthStart   string
+	monthFetched time.Time
+}
+
+func NewAzure(client *azure.CostClient) *Azure {
+	return &Azure{Client: client}
+}
+
+func (p *Azure) Name() string { return "azure" }
+
+func (p *Azure) IsConfigured() bool {
+	return azure.ValidateSubscriptionID(p.Client.SubscriptionID) == nil
+}
+
+func (p *Azure) Account(ctx context.Context) (string, error) {
+	if err := azure.ValidateSubscriptionID(p.Client.SubscriptionID); err != nil {
+		return "", fmt.Errorf("invalid subscription ID: %w", err)
+	}
+	return p.Client.SubscriptionID, nil
+}
+
+// DailyCosts queries Cost Management. This month's costs are kept for
+// azureMonthCostsTTL and shared by FreeTierUsage, Credit and Forecast.
+func (p *Azure) DailyCosts(ctx context.Context, startDate, endDate string) ([]cloud.CostRecord, error) {
+	monthStart, monthEnd := cost.GetCurrentMonthDateRange()
+	if startDate != monthStart || endDate != monthEnd {
+		return p.queryDailyCosts(ctx, startDate, endDate)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.monthStart == monthStart && time.Since(p.monthFetched) < azureMonthCostsTTL {
+		return append([]cloud.CostRecord(nil), p.month...), nil
+	}
+	records, err := p.queryDailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	p.month, p.monthStart, p.monthFetched = records, monthStart, time.Now()
+	return append([]cloud.CostRecord(nil), records...), nil
+}
+
+func (p *Azure) queryDailyCosts(ctx context.Context, startDate, endDate string) ([]cloud.CostRecord, error) {
+	// Cost Management periods include their last day.
+	lastDay := endDate
+	if end, err := time.Parse("2006-01-02", endDate); err == nil {
+		lastDay = end.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	result, err := p.Client.QueryCostsByService(ctx, startDate, lastDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query costs: %w", err)
+	}
+
+	records := make([]cloud.CostRecord, len(result.Records))
+	for i, r := range result.Records {
+		records[i] = cloud.CostRecord{
+			AccountID:     p.Client.SubscriptionID,
+			ResourceGroup: r.ResourceGroup,
+			ServiceName:   r.ServiceName,
+			Cost:          r.Cost,
+			Currency:      r.Currency,
+			Date:          r.Date,
+		}
+	}
+	return records, nil
+}
+
+// FreeTierUsage compares this month's spend with the free account credit, on
+// offers that have one, and the approximate dollar value of the free
+// allowance of each service, as Azure has no free tier usage API.
+func (p *Azure) FreeTierUsage(ctx context.Context) ([]cloud.FreeTierUsage, error) {
+	credit, err := p.offerCredit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	records, err := p.DailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return AzureFreeTierUsage(records, credit), nil
+}
+
+// AzureFreeTierUsage turns a month of Azure costs into usage of a free credit
+// and of each service's free allowance. A credit of 0 leaves the credit out.
+func AzureFreeTierUsage(records []cloud.CostRecord, credit float64) []cloud.FreeTierUsage {
+	total := 0.0
+	byService := make(map[string]float64)
+	var services []string
+	for _, r := range records {
+		total += r.Cost
+		if _, ok := byService[r.ServiceName]; !ok {
+			services = append(services, r.ServiceName)
+		}
+		byService[r.ServiceName] += r.Cost
+	}
+
+	var usages []cloud.FreeTierUsage
+	if credit > 0 {
+		usages = append(usages, cloud.FreeTierUsage{
+			Key:         "credit",
+			Name:        "Free account credit",
+			Description: "Spend this month against the free account credit",
+			Used:        total,
+			Limit:       credit,
+			Unit:        "USD",
+			PercentUsed: total / credit * 100,
+			Threshold:   cost.DefaultWarningThreshold,
+		})
+	}
+	for _, service := range services {
+		limit := azureServiceAllowances[strings.ToLower(service)]
+		if limit <= 0 {
+			continue
+		}
+		usages = append(usages, cloud.FreeTierUsage{
+			Key:         strings.ToLower(service),
+			Name:        service,
+			Description: "Spend against the approximate value of the free allowance",
+			Used:        byService[service],
+			Limit:       limit,
+			Unit:        "USD",
+			PercentUsed: byService[service] / limit * 100,
+			Threshold:   cost.DefaultWarningThreshold,
+		})
+	}
+	return usages
+}
+
+// Credit approximates what is left of the free account credit from this
+// month's spend. It is nil for subscriptions whose offer has no credit.
+func (p *Azure) Credit(ctx context.Context) (*cloud.Credit, error) {
+	credit, err := p.offerCredit(ctx)
+	if err != nil || credit == 0 {
+		return nil, err
+	}
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	records, err := p.DailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	remaining := credit
+	for _, r := range records {
+		remaining -= r.Cost
+	}
+	return &cloud.Credit{Remaining: remaining, Currency: "USD"}, nil
+}
+
+// offerCredit looks up the subscription's offer and returns its sign-up
+// credit, or 0 when it has none.
+func (p *Azure) offerCredit(ctx context.Context) (float64, error) {
+	if p.credit != nil {
+		return *p.credit, nil
+	}
+	sub, err := p.Client.GetSubscription(ctx)
+	if err != nil {
+		return 0, err
+	}
+	credit := AzureOfferCredit(sub.QuotaID)
+	p.credit = &credit
+	return credit, nil
+}
+
+// Inventory lists every resource in the subscription. Azure bills most
+// resources by use, so only types that never cost anything count as free.
+func (p *Azure) Inventory(ctx context.Context) (*cloud.Inventory, error) {
+	found, err := p.Client.ListResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &cloud.Inventory{
+		Provider:  p.Name(),
+		Account:   p.Client.SubscriptionID,
+		Resources: AzureResources(found),
+	}, nil
+}
+
+// azureFreeResourceTypes are resource types Azure never charges for.
+var azureFreeResourceTypes = map[string]bool{
+	"microsoft.network/virtualnetworks":                true,
+	"microsoft.network/networksecuritygroups":          true,
+	"microsoft.network/networkinterfaces":              true,
+	"microsoft.network/routetables":                    true,
+	"microsoft.network/applicationsecuritygroups":      true,
+	"microsoft.insights/actiongroups":                  true,
+	"microsoft.managedidentity/userassignedidentities": true,
+}
+
+// AzureResources turns listed Azure resources into inventory rows sorted by
+// region, flagging the types in azureFreeResourceTypes as free.
+func AzureResources(found []azure.Resource) []cloud.Resource {
+	resources := make([]cloud.Resource, 0, len(found))
+	for _, r := range found {
+		res := cloud.Resource{
+			Region:   r.Location,
+			Type:     r.Type,
+			ID:       r.ID,
+			Name:     r.Name,
+			Detail:   "resource group " + r.ResourceGroup,
+			FreeTier: azureFreeResourceTypes[strings.ToLower(r.Type)],
+		}
+		if !res.FreeTier {
+			res.Note = "Billed by use beyond any free allowance of its service"
+		}
+		resources = append(resources, res)
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Region != resources[j].Region {
+			return resources[i].Region < resources[j].Region
+		}
+		return resources[i].Type < resources[j].Type
+	})
+	return resources
+}
+
+func (p *Azure) Forecast(ctx context.Context) (*cloud.Forecast, error) {
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	records, err := p.DailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	monthToDate, currency := 0.0, "USD"
+	for _, r := range records {
+		monthToDate += r.Cost
+		if r.Currency != "" {
+			currency = r.Currency
+		}
+	}
+
+	// The forecast covers the days left in the month, from tomorrow on.
+	now := time.Now().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	if tomorrow.Month() != now.Month() {
+		return &cloud.Forecast{MonthToDate: monthToDate, MonthEnd: monthToDate, Currency: currency}, nil
+	}
+	lastDay := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	result, err := p.Client.GetForecast(ctx, "Daily", tomorrow.Format("2006-01-02"), lastDay.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	return &cloud.Forecast{
+		MonthToDate: monthToDate,
+		MonthEnd:    monthToDate + result.TotalCost,
+		Currency:    result.Currency,
+	}, nil
+}
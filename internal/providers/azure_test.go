@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azguard/azguard/internal/cloud"
+	"github.com/azguard/azguard/internal/cloud/azure"
+	"github.com/azguard/azguard/internal/cost"
+	"github.com/azguard/azguard/internal/fakecloud"
+)
+
+func TestAzureOfferCredit(t *testing.T) {
+	tests := map[string]float64{
+		"FreeTrial_2014-09-01":        AzureFreeCredit,
+		"AzureForStudents_2018-01-01": AzureStudentCredit,
+		"azureforstudents_2018-01-01": AzureStudentCredit,
+		"PayAsYouGo_2014-09-01":       0,
+		"MSDN_2014-09-01":             0,
+		"":                            0,
+	}
+	for quotaID, want := range tests {
+		if got := AzureOfferCredit(quotaID); got != want {
+			t.Errorf("AzureOfferCredit(%q) = %v, want %v", quotaID, got, want)
+		}
+	}
+}
+
+func TestAzureCreditFollowsOffer(t *testing.T) {
+	tests := []struct {
+		quotaID   string
+		remaining float64
+		credit    bool
+	}{
+		{"FreeTrial_2014-09-01", AzureFreeCredit, true},
+		{"AzureForStudents_2018-01-01", AzureStudentCredit, true},
+		{"PayAsYouGo_2014-09-01", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.quotaID, func(t *testing.T) {
+			// No daily costs, so a credit is untouched.
+			server := httptest.NewServer(fakecloud.NewServer(&fakecloud.Scenario{
+				Currency: "USD",
+				Azure:    fakecloud.AzureScenario{QuotaID: tt.quotaID},
+			}))
+			defer server.Close()
+
+			saved := azure.AzureManagementURL
+			azure.AzureManagementURL = server.URL
+			defer func() { azure.AzureManagementURL = saved }()
+
+			p := NewAzure(azure.NewCostClient("11111111-2222-3333-4444-555555555555", func() (string, error) { return "token", nil }))
+
+			credit, err := p.Credit(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (credit != nil) != tt.credit || (credit != nil && credit.Remaining != tt.remaining) {
+				t.Errorf("Credit() = %+v, want credit %v of $%.2f", credit, tt.credit, tt.remaining)
+			}
+
+			usages, err := p.FreeTierUsage(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hasCreditUsage(usages) != tt.credit {
+				t.Errorf("FreeTierUsage() credit row = %v, want %v", hasCreditUsage(usages), tt.credit)
+			}
+		})
+	}
+}
+
+func TestAzureFreeTierUsage(t *testing.T) {
+	records := []cloud.CostRecord{
+		{ServiceName: "Virtual Machines", Cost: 3},
+		{ServiceName: "Virtual Machines", Cost: 3},
+		{ServiceName: "Key Vault", Cost: 1},
+	}
+
+	usages := AzureFreeTierUsage(records, 0)
+	if len(usages) != 1 || usages[0].Name != "Virtual Machines" || usages[0].Used != 6 || usages[0].Limit != 7.5 {
+		t.Errorf("AzureFreeTierUsage() without a credit = %+v", usages)
+	}
+
+	usages = AzureFreeTierUsage(records, 100)
+	if len(usages) != 2 || !hasCreditUsage(usages) || usages[0].Used != 7 || math.Abs(usages[0].PercentUsed-7) > 1e-9 {
+		t.Errorf("AzureFreeTierUsage() with a credit = %+v", usages)
+	}
+}
+
+func hasCreditUsage(usages []cloud.FreeTierUsage) bool {
+	for _, u := range usages {
+		if u.Key == "credit" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAzureResources(t *testing.T) {
+	found := []azure.Resource{
+		{ID: "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm1", Name: "vm1", Type: "Microsoft.Compute/virtualMachines", Location: "westeurope", ResourceGroup: "rg"},
+		{ID: "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet", Name: "vnet", Type: "Microsoft.Network/virtualNetworks", Location: "eastus", ResourceGroup: "rg"},
+	}
+
+	resources := AzureResources(found)
+	if len(resources) != 2 {
+		t.Fatalf("AzureResources() = %+v", resources)
+	}
+	if r := resources[0]; r.Name != "vnet" || r.Region != "eastus" || !r.FreeTier {
+		t.Errorf("first resource = %+v, want the free vnet in eastus", r)
+	}
+	if r := resources[1]; r.Name != "vm1" || r.FreeTier || r.Detail != "resource group rg" {
+		t.Errorf("second resource = %+v, want the billable vm1", r)
+	}
+}
+
+func TestAzureStatusQueriesMonthOnce(t *testing.T) {
+	fake := fakecloud.NewServer(&fakecloud.Scenario{
+		Currency: "USD",
+		Azure:    fakecloud.AzureScenario{QuotaID: "FreeTrial_2014-09-01"},
+	})
+	queries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(strings.ToLower(r.URL.Path), "/microsoft.costmanagement/query") {
+			queries++
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	saved := azure.AzureManagementURL
+	azure.AzureManagementURL = server.URL
+	defer func() { azure.AzureManagementURL = saved }()
+
+	p := NewAzure(azure.NewCostClient("11111111-2222-3333-4444-555555555555", func() (string, error) { return "token", nil }))
+	ctx := context.Background()
+
+	// The calls a status run makes share one Cost Management query.
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	if _, err := p.DailyCosts(ctx, startDate, endDate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.FreeTierUsage(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Credit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if queries != 1 {
+		t.Errorf("ran %d cost queries, want 1", queries)
+	}
+}
@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azguard/azguard/internal/cloud"
+	gcpcloud "github.com/azguard/azguard/internal/cloud/gcp"
+	"github.com/azguard/azguard/internal/cost"
+)
+
+// GCP is a Google Cloud project seen through its Client.
+type GCP struct {
+	Client *gcpcloud.Client
+}
+
+func NewGCP(client *gcpcloud.Client) *GCP {
+	return &GCP{Client: client}
+}
+
+func (p *GCP) Name() string { return "gcp" }
+
+func (p *GCP) IsConfigured() bool {
+	return p.Client.IsConfigured() && p.Client.ProjectID != ""
+}
+
+func (p *GCP) Account(ctx context.Context) (string, error) {
+	if p.Client.ProjectID == "" {
+		return "", fmt.Errorf("GCP project not configured. Set gcp.project_id or run 'gcloud config set project'")
+	}
+	return p.Client.ProjectID, nil
+}
+
+func (p *GCP) DailyCosts(ctx context.Context, startDate, endDate string) ([]cloud.CostRecord, error) {
+	costs, err := p.Client.GetDailyCosts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]cloud.CostRecord, len(costs))
+	for i, r := range costs {
+		records[i] = cloud.CostRecord{
+			AccountID:   r.ProjectID,
+			ServiceName: r.ServiceName,
+			Cost:        r.Cost,
+			Currency:    r.Currency,
+			Date:        r.Date,
+		}
+	}
+	return records, nil
+}
+
+// FreeTierUsage sums this month's SKU usage from the billing export into the
+// always-free allowances of the GCP catalog.
+func (p *GCP) FreeTierUsage(ctx context.Context) ([]cloud.FreeTierUsage, error) {
+	catalog, err := cost.LoadFreeTierCatalog("gcp")
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate := cost.GetCurrentMonthDateRange()
+	skus, err := p.Client.GetSKUUsage(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return cost.CheckGCPFreeTier(catalog, skus, time.Now()), nil
+}
+
+// Inventory lists Compute and Storage resources and checks them against the
+// region and SKU rules of the GCP catalog.
+func (p *GCP) Inventory(ctx context.Context) (*cloud.Inventory, error) {
+	catalog, err := cost.LoadFreeTierCatalog("gcp")
+	if err != nil {
+		return nil, err
+	}
+	found, err := p.Client.ListResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cost.CheckGCPResources(catalog, found)
+
+	inv := &cloud.Inventory{
+		Provider: p.Name(),
+		Account:  found.ProjectID,
+		Warnings: found.Warnings,
+		Errors:   found.Errors,
+	}
+	for _, r := range found.Resources {
+		inv.Resources = append(inv.Resources, cloud.Resource{
+			Region:   r.Region,
+			Type:     r.Type,
+			ID:       r.Name,
+			Name:     r.Name,
+			SKU:      r.SKU,
+			Detail:   r.Detail,
+			SizeGB:   r.SizeGB,
+			FreeTier: r.FreeTier,
+			Note:     r.Note,
+		})
+	}
+	return inv, nil
+}
+
+// Forecast is not offered by the billing export; stored costs are projected
+// instead.
+func (p *GCP) Forecast(ctx context.Context) (*cloud.Forecast, error) {
+	return nil, fmt.Errorf("GCP cost forecasts are %w", cloud.ErrNotSupported)
+}
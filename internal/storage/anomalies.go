@@ -0,0 +1,96 @@
+package storage
+
+import "encoding/json"
+
+// Anomaly is a stored cost anomaly. The first root cause is kept in columns
+// for filtering; RootCauses holds them all as reported.
+type Anomaly struct {
+	ID            string         `json:"id"`
+	Provider      string         `json:"provider"`
+	AccountID     string         `json:"account_id"`
+	StartDate     string         `json:"start_date"`
+	EndDate       string         `json:"end_date"`
+	ServiceName   string         `json:"service"`
+	Region        string         `json:"region"`
+	UsageType     string         `json:"usage_type"`
+	Impact        float64        `json:"impact"`
+	ImpactPercent float64        `json:"impact_percent"`
+	ActualSpend   float64        `json:"actual_spend"`
+	ExpectedSpend float64        `json:"expected_spend"`
+	Score         float64        `json:"score"`
+	RootCauses    []AnomalyCause `json:"root_causes,omitempty"`
+}
+
+type AnomalyCause struct {
+	Service       string `json:"service,omitempty"`
+	Region        string `json:"region,omitempty"`
+	UsageType     string `json:"usage_type,omitempty"`
+	LinkedAccount string `json:"linked_account,omitempty"`
+}
+
+// SaveAnomalies inserts anomalies or updates them in place; an ongoing
+// anomaly's impact grows until it ends.
+func (db *DB) SaveAnomalies(anomalies []Anomaly) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO anomalies (id, provider, account_id, start_date, end_date, service_name, region,
+			usage_type, impact, impact_percent, actual_spend, expected_spend, score, root_causes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range anomalies {
+		causes, err := json.Marshal(a.RootCauses)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(a.ID, a.Provider, a.AccountID, a.StartDate, a.EndDate, a.ServiceName, a.Region,
+			a.UsageType, a.Impact, a.ImpactPercent, a.ActualSpend, a.ExpectedSpend, a.Score, string(causes)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAnomalies returns stored anomalies that started on or after since,
+// largest impact first.
+func (db *DB) GetAnomalies(provider, since string) ([]Anomaly, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, provider, account_id, start_date, end_date, service_name, region, usage_type,
+			impact, impact_percent, actual_spend, expected_spend, score, root_causes
+		FROM anomalies WHERE provider = ? AND start_date >= ?
+		ORDER BY impact DESC
+	`, provider, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []Anomaly
+	for rows.Next() {
+		var (
+			a      Anomaly
+			causes string
+		)
+		if err := rows.Scan(&a.ID, &a.Provider, &a.AccountID, &a.StartDate, &a.EndDate, &a.ServiceName, &a.Region, &a.UsageType,
+			&a.Impact, &a.ImpactPercent, &a.ActualSpend, &a.ExpectedSpend, &a.Score, &causes); err != nil {
+			return nil, err
+		}
+		if causes != "" && causes != "null" {
+			if err := json.Unmarshal([]byte(causes), &a.RootCauses); err != nil {
+				return nil, err
+			}
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, nil
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSaveAnomaliesUpserts(t *testing.T) {
+	db := newTestDB(t)
+	ongoing := Anomaly{
+		ID: "a-1", Provider: "aws", AccountID: "123456789012", StartDate: "2024-05-04",
+		ServiceName: "Amazon EC2", Region: "us-east-1", Impact: 10, Score: 0.8,
+		RootCauses: []AnomalyCause{{Service: "Amazon EC2", Region: "us-east-1", LinkedAccount: "210987654321"}},
+	}
+	other := Anomaly{ID: "a-2", Provider: "aws", StartDate: "2024-04-20", ServiceName: "Amazon S3", Impact: 25}
+	if err := db.SaveAnomalies([]Anomaly{ongoing, other}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The anomaly grows and ends; saving it again updates the stored row.
+	ended := ongoing
+	ended.EndDate = "2024-05-06"
+	ended.Impact = 30
+	ended.RootCauses = append(ended.RootCauses, AnomalyCause{Service: "Amazon EC2", Region: "us-west-2"})
+	if err := db.SaveAnomalies([]Anomaly{ended}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := db.GetAnomalies("aws", "2024-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Anomaly{ended, other}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAnomalies() = %+v\nwant %+v (one row per ID, largest impact first)", got, want)
+	}
+}
+
+func TestGetAnomaliesFilters(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.SaveAnomalies([]Anomaly{
+		{ID: "old", Provider: "aws", StartDate: "2024-03-31", Impact: 5},
+		{ID: "new", Provider: "aws", StartDate: "2024-04-01", Impact: 2},
+		{ID: "other", Provider: "azure", StartDate: "2024-04-02", Impact: 9},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := db.GetAnomalies("aws", "2024-04-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "new" || got[0].RootCauses != nil {
+		t.Errorf("GetAnomalies() = %+v, want only the aws anomaly since 04-01", got)
+	}
+}
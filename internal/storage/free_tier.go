@@ -0,0 +1,72 @@
+package storage
+
+// FreeTierUsage is one free tier usage row for an account on a given day.
+type FreeTierUsage struct {
+	Provider    string  `json:"provider"`
+	AccountID   string  `json:"account_id"`
+	ServiceName string  `json:"service"`
+	UsageType   string  `json:"usage_type"`
+	Actual      float64 `json:"actual"`
+	Forecast    float64 `json:"forecast"`
+	Limit       float64 `json:"limit"`
+	Unit        string  `json:"unit"`
+	Date        string  `json:"date"`
+}
+
+// ReplaceFreeTierUsage stores a day's usage snapshot for one account,
+// replacing any earlier snapshot for that account and day.
+func (db *DB) ReplaceFreeTierUsage(provider, accountID, date string, usage []FreeTierUsage) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM free_tier_usage WHERE provider = ? AND account_id = ? AND date = ?",
+		provider, accountID, date); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO free_tier_usage (provider, account_id, service_name, usage_type, actual, forecast, limit_amount, unit, date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, u := range usage {
+		if _, err := stmt.Exec(provider, accountID, u.ServiceName, u.UsageType, u.Actual, u.Forecast, u.Limit, u.Unit, date); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFreeTierUsage returns the latest stored snapshot of each account. An
+// empty accountID includes every account of the provider.
+func (db *DB) GetFreeTierUsage(provider, accountID string) ([]FreeTierUsage, error) {
+	rows, err := db.conn.Query(`
+		SELECT u.provider, u.account_id, u.service_name, u.usage_type, u.actual, u.forecast, u.limit_amount, u.unit, u.date
+		FROM free_tier_usage u
+		WHERE u.provider = ? AND (? = '' OR u.account_id = ?)
+		AND u.date = (SELECT MAX(date) FROM free_tier_usage l WHERE l.provider = u.provider AND l.account_id = u.account_id)
+		ORDER BY u.account_id, u.service_name, u.usage_type
+	`, provider, accountID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []FreeTierUsage
+	for rows.Next() {
+		var u FreeTierUsage
+		if err := rows.Scan(&u.Provider, &u.AccountID, &u.ServiceName, &u.UsageType, &u.Actual, &u.Forecast, &u.Limit, &u.Unit, &u.Date); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
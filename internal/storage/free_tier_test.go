@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetFreeTierUsageLatestSnapshotPerAccount(t *testing.T) {
+	db := newTestDB(t)
+	save := func(account, date string, usage ...FreeTierUsage) {
+		t.Helper()
+		if err := db.ReplaceFreeTierUsage("aws", account, date, usage); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Account 111 was last synced on the 3rd, account 222 on the 5th. A
+	// per-provider MAX(date) would hide 111's latest snapshot entirely.
+	save("111", "2024-05-01", FreeTierUsage{ServiceName: "Amazon EC2", UsageType: "BoxUsage", Actual: 100, Limit: 750})
+	save("111", "2024-05-03", FreeTierUsage{ServiceName: "Amazon EC2", UsageType: "BoxUsage", Actual: 150, Limit: 750})
+	save("222", "2024-05-05",
+		FreeTierUsage{ServiceName: "Amazon S3", UsageType: "TimedStorage", Actual: 2, Limit: 5},
+		FreeTierUsage{ServiceName: "AWS Lambda", UsageType: "Request", Actual: 1000, Limit: 1000000},
+	)
+	// A re-sync on the same day replaces that day's snapshot.
+	save("222", "2024-05-05", FreeTierUsage{ServiceName: "Amazon S3", UsageType: "TimedStorage", Actual: 3, Limit: 5})
+	if err := db.ReplaceFreeTierUsage("azure", "sub-1", "2024-05-06", []FreeTierUsage{{ServiceName: "Storage", Actual: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := db.GetFreeTierUsage("aws", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FreeTierUsage{
+		{Provider: "aws", AccountID: "111", ServiceName: "Amazon EC2", UsageType: "BoxUsage", Actual: 150, Limit: 750, Date: "2024-05-03"},
+		{Provider: "aws", AccountID: "222", ServiceName: "Amazon S3", UsageType: "TimedStorage", Actual: 3, Limit: 5, Date: "2024-05-05"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFreeTierUsage(aws) = %+v\nwant %+v", got, want)
+	}
+
+	got, err = db.GetFreeTierUsage("aws", "111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Date != "2024-05-03" {
+		t.Errorf("GetFreeTierUsage(aws, 111) = %+v, want its 05-03 snapshot", got)
+	}
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetCachedPrices returns the cached price data for a query and when it was fetched.
+// A missing entry returns an empty string and a zero time.
+func (db *DB) GetCachedPrices(query string) (string, time.Time, error) {
+	var (
+		data      string
+		fetchedAt time.Time
+	)
+	err := db.conn.QueryRow("SELECT data, fetched_at FROM price_cache WHERE query = ?", query).Scan(&data, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return "", time.Time{}, nil
+	}
+	return data, fetchedAt, err
+}
+
+func (db *DB) SaveCachedPrices(query, data string) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO price_cache (query, data, fetched_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(query) DO UPDATE SET data = excluded.data, fetched_at = excluded.fetched_at
+	`, query, data, time.Now().UTC())
+	return err
+}
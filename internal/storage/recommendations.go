@@ -0,0 +1,82 @@
+package storage
+
+type Recommendation struct {
+	ID             string  `json:"id"`
+	SubscriptionID string  `json:"subscription_id"`
+	ResourceID     string  `json:"resource_id"`
+	ResourceGroup  string  `json:"resource_group"`
+	ResourceType   string  `json:"resource_type"`
+	ResourceName   string  `json:"resource_name"`
+	Location       string  `json:"location"`
+	Impact         string  `json:"impact"`
+	Problem        string  `json:"problem"`
+	Solution       string  `json:"solution"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	Currency       string  `json:"currency"`
+	RecordedCost   float64 `json:"recorded_cost"`
+	// CostScope says what RecordedCost covers: "resource", "resource_group",
+	// or "" when nothing was recorded.
+	CostScope string `json:"cost_scope"`
+}
+
+// ReplaceRecommendations swaps the stored recommendations for a subscription
+// with a fresh set, so dismissed or resolved items disappear.
+func (db *DB) ReplaceRecommendations(subscriptionID string, recs []Recommendation) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM recommendations WHERE subscription_id = ?", subscriptionID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO recommendations (id, subscription_id, resource_id, resource_group, resource_type,
+			resource_name, location, impact, problem, solution, monthly_savings, currency, recorded_cost, cost_scope)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range recs {
+		if _, err := stmt.Exec(r.ID, subscriptionID, r.ResourceID, r.ResourceGroup, r.ResourceType,
+			r.ResourceName, r.Location, r.Impact, r.Problem, r.Solution, r.MonthlySavings, r.Currency, r.RecordedCost, r.CostScope); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetRecommendations returns stored recommendations ranked by estimated monthly savings.
+func (db *DB) GetRecommendations(subscriptionID string) ([]Recommendation, error) {
+	query := `SELECT id, subscription_id, resource_id, resource_group, resource_type, resource_name, location,
+		impact, problem, solution, monthly_savings, currency, recorded_cost, COALESCE(cost_scope, '') FROM recommendations`
+	args := []interface{}{}
+	if subscriptionID != "" {
+		query += " WHERE subscription_id = ?"
+		args = append(args, subscriptionID)
+	}
+	query += " ORDER BY monthly_savings DESC, recorded_cost DESC"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recs []Recommendation
+	for rows.Next() {
+		var r Recommendation
+		if err := rows.Scan(&r.ID, &r.SubscriptionID, &r.ResourceID, &r.ResourceGroup, &r.ResourceType, &r.ResourceName,
+			&r.Location, &r.Impact, &r.Problem, &r.Solution, &r.MonthlySavings, &r.Currency, &r.RecordedCost, &r.CostScope); err != nil {
+			return nil, err
+		}
+		recs = append(recs, r)
+	}
+	return recs, nil
+}
@@ -61,9 +61,64 @@ func (db *DB) migrate() error {
 			enabled INTEGER DEFAULT 1,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`CREATE TABLE IF NOT EXISTS recommendations (
+			id TEXT NOT NULL,
+			subscription_id TEXT NOT NULL,
+			resource_id TEXT,
+			resource_group TEXT,
+			resource_type TEXT,
+			resource_name TEXT,
+			location TEXT,
+			impact TEXT,
+			problem TEXT,
+			solution TEXT,
+			monthly_savings REAL DEFAULT 0,
+			currency TEXT DEFAULT 'USD',
+			recorded_cost REAL DEFAULT 0,
+			cost_scope TEXT DEFAULT '',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (subscription_id, id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS price_cache (
+			query TEXT PRIMARY KEY,
+			data TEXT NOT NULL,
+			fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS anomalies (
+			id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			account_id TEXT DEFAULT '',
+			start_date TEXT NOT NULL,
+			end_date TEXT DEFAULT '',
+			service_name TEXT DEFAULT '',
+			region TEXT DEFAULT '',
+			usage_type TEXT DEFAULT '',
+			impact REAL DEFAULT 0,
+			impact_percent REAL DEFAULT 0,
+			actual_spend REAL DEFAULT 0,
+			expected_spend REAL DEFAULT 0,
+			score REAL DEFAULT 0,
+			root_causes TEXT DEFAULT '',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (provider, id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS free_tier_usage (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			provider TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			service_name TEXT NOT NULL,
+			usage_type TEXT DEFAULT '',
+			actual REAL DEFAULT 0,
+			forecast REAL DEFAULT 0,
+			limit_amount REAL DEFAULT 0,
+			unit TEXT DEFAULT '',
+			date TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_cost_date ON cost_records(date)`,
 		`CREATE INDEX IF NOT EXISTS idx_cost_subscription ON cost_records(subscription_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_cost_service ON cost_records(service_name)`,
+		`CREATE INDEX IF NOT EXISTS idx_free_tier_account ON free_tier_usage(provider, account_id, date)`,
 	}
 
 	for _, m := range migrations {
@@ -71,9 +126,84 @@ func (db *DB) migrate() error {
 			return err
 		}
 	}
+
+	// Columns added after the first release; SQLite has no ADD COLUMN IF NOT EXISTS.
+	columns := []struct {
+		table, column, definition string
+	}{
+		{"cost_records", "tag_key", "TEXT DEFAULT ''"},
+		{"cost_records", "tag_value", "TEXT DEFAULT ''"},
+		{"cost_records", "provider", "TEXT DEFAULT 'azure'"},
+		{"cost_records", "account_id", "TEXT DEFAULT ''"},
+		{"cost_records", "resource_id", "TEXT DEFAULT ''"},
+		{"cost_records", "import_key", "TEXT DEFAULT ''"},
+		{"recommendations", "cost_scope", "TEXT DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := db.addColumn(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_cost_tag ON cost_records(tag_key, tag_value)`,
+		`CREATE INDEX IF NOT EXISTS idx_cost_provider ON cost_records(provider, account_id)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_cost_import_key ON cost_records(import_key) WHERE import_key != ''`,
+	}
+	for _, idx := range indexes {
+		if _, err := db.conn.Exec(idx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (db *DB) addColumn(table, column, definition string) error {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// Reset deletes every stored row except cached prices, e.g. before the demo
+// database is seeded again.
+func (db *DB) Reset() error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"config", "cost_records", "alerts", "recommendations", "anomalies", "free_tier_usage"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
@@ -96,11 +226,21 @@ func (db *DB) SetConfig(key, value string) error {
 	return err
 }
 
+// CostRecord is one day of spend for a service. Provider is "azure", "aws"
+// or "gcp"; AccountID holds the AWS account or GCP project, SubscriptionID the
+// Azure subscription. Records imported from billing files are line items with
+// a ResourceID and an ImportKey that identifies the source line.
 type CostRecord struct {
 	ID              int64
+	Provider        string
 	SubscriptionID  string
+	AccountID       string
 	ResourceGroup   string
+	ResourceID      string
+	ImportKey       string
 	ServiceName     string
+	TagKey          string
+	TagValue        string
 	Cost            float64
 	Currency        string
 	Date            string
@@ -108,9 +248,9 @@ type CostRecord struct {
 
 func (db *DB) SaveCostRecord(record CostRecord) error {
 	_, err := db.conn.Exec(`
-		INSERT INTO cost_records (subscription_id, resource_group, service_name, cost, currency, date)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, record.SubscriptionID, record.ResourceGroup, record.ServiceName, record.Cost, record.Currency, record.Date)
+		INSERT INTO cost_records (provider, subscription_id, account_id, resource_group, service_name, tag_key, tag_value, cost, currency, date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, providerOrDefault(record.Provider), record.SubscriptionID, record.AccountID, record.ResourceGroup, record.ServiceName, record.TagKey, record.TagValue, record.Cost, record.Currency, record.Date)
 	return err
 }
 
@@ -122,8 +262,8 @@ func (db *DB) SaveCostRecords(records []CostRecord) error {
 	defer func() { _ = tx.Rollback() }()
 
 	stmt, err := tx.Prepare(`
-		INSERT INTO cost_records (subscription_id, resource_group, service_name, cost, currency, date)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO cost_records (provider, subscription_id, account_id, resource_group, service_name, tag_key, tag_value, cost, currency, date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
@@ -131,7 +271,7 @@ func (db *DB) SaveCostRecords(records []CostRecord) error {
 	defer stmt.Close()
 
 	for _, r := range records {
-		if _, err := stmt.Exec(r.SubscriptionID, r.ResourceGroup, r.ServiceName, r.Cost, r.Currency, r.Date); err != nil {
+		if _, err := stmt.Exec(providerOrDefault(r.Provider), r.SubscriptionID, r.AccountID, r.ResourceGroup, r.ServiceName, r.TagKey, r.TagValue, r.Cost, r.Currency, r.Date); err != nil {
 			return err
 		}
 	}
@@ -139,25 +279,167 @@ func (db *DB) SaveCostRecords(records []CostRecord) error {
 	return tx.Commit()
 }
 
-type CostFilter struct {
-	StartDate   string
-	EndDate     string
-	ServiceName string
-	GroupBy     string
+// SaveImportedCostRecords stores billing file line items. A record whose
+// ImportKey is already stored replaces it, so importing a file twice, or a
+// newer export of the same period, does not double count. Records with zero
+// cost are only written over a stored line item, so a charge revised down to
+// nothing is cleared without storing every free line item. It returns how
+// many records were new and how many replaced a stored one.
+func (db *DB) SaveImportedCostRecords(records []CostRecord) (added, updated int, err error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	exists, err := tx.Prepare("SELECT COUNT(*) FROM cost_records WHERE import_key = ?")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer exists.Close()
+
+	zero, err := tx.Prepare("UPDATE cost_records SET cost = 0 WHERE import_key = ?")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer zero.Close()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO cost_records (provider, subscription_id, account_id, resource_group, resource_id, service_name, cost, currency, date, import_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		if r.Cost == 0 {
+			res, err := zero.Exec(r.ImportKey)
+			if err != nil {
+				return 0, 0, err
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				updated++
+			}
+			continue
+		}
+
+		var n int
+		if err := exists.QueryRow(r.ImportKey).Scan(&n); err != nil {
+			return 0, 0, err
+		}
+		if n == 0 {
+			added++
+		} else {
+			updated++
+		}
+		if _, err := stmt.Exec(providerOrDefault(r.Provider), r.SubscriptionID, r.AccountID, r.ResourceGroup, r.ResourceID, r.ServiceName, r.Cost, r.Currency, r.Date, r.ImportKey); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return added, updated, tx.Commit()
 }
 
-func (db *DB) GetCostRecords(filter CostFilter) ([]CostRecord, error) {
-	query := "SELECT id, subscription_id, resource_group, service_name, cost, currency, date FROM cost_records WHERE 1=1"
-	args := []interface{}{}
+func providerOrDefault(provider string) string {
+	if provider == "" {
+		return "azure"
+	}
+	return provider
+}
+
+// CostFilter narrows cost queries. Records fetched grouped by a tag are kept
+// apart from the regular records so totals are not counted twice: an empty
+// TagKey selects the regular records, a non-empty one that tag's records.
+// An empty Provider, AccountID or SubscriptionID matches every provider,
+// account or subscription.
+type CostFilter struct {
+	StartDate      string
+	EndDate        string
+	ServiceName    string
+	TagKey         string
+	Provider       string
+	AccountID      string
+	SubscriptionID string
+	GroupBy        string
+}
 
-	if filter.StartDate != "" {
+// where appends the filter's date, provider and account conditions.
+func (f CostFilter) where(query string, args []interface{}) (string, []interface{}) {
+	if f.StartDate != "" {
 		query += " AND date >= ?"
-		args = append(args, filter.StartDate)
+		args = append(args, f.StartDate)
 	}
-	if filter.EndDate != "" {
+	if f.EndDate != "" {
 		query += " AND date <= ?"
-		args = append(args, filter.EndDate)
+		args = append(args, f.EndDate)
+	}
+	if f.Provider != "" {
+		query += " AND COALESCE(provider, 'azure') = ?"
+		args = append(args, f.Provider)
+	}
+	if f.AccountID != "" {
+		query += " AND account_id = ?"
+		args = append(args, f.AccountID)
+	}
+	if f.SubscriptionID != "" {
+		query += " AND subscription_id = ?"
+		args = append(args, f.SubscriptionID)
+	}
+	return query, args
+}
+
+// DeleteCostRecords removes the records matching the filter's date range, tag key, provider and account.
+// Records imported from billing files are kept; only a newer import replaces them.
+func (db *DB) DeleteCostRecords(filter CostFilter) error {
+	query := "DELETE FROM cost_records WHERE COALESCE(tag_key, '') = ? AND COALESCE(import_key, '') = ''"
+	query, args := filter.where(query, []interface{}{filter.TagKey})
+
+	_, err := db.conn.Exec(query, args...)
+	return err
+}
+
+// ImportedCostDays returns the days in the filter's range, provider and
+// account that have records imported from billing files.
+func (db *DB) ImportedCostDays(filter CostFilter) (map[string]bool, error) {
+	query := "SELECT DISTINCT date FROM cost_records WHERE COALESCE(import_key, '') != ''"
+	query, args := filter.where(query, nil)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	days := make(map[string]bool)
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days[day] = true
+	}
+	return days, rows.Err()
+}
+
+// HasSyncedCost reports whether records fetched from the provider's API,
+// rather than imported from a billing file, are stored for the record's
+// provider, account, service and day.
+func (db *DB) HasSyncedCost(r CostRecord) (bool, error) {
+	var n int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM cost_records
+		WHERE COALESCE(import_key, '') = '' AND COALESCE(tag_key, '') = ''
+			AND COALESCE(provider, 'azure') = ? AND subscription_id = ? AND COALESCE(account_id, '') = ?
+			AND service_name = ? AND date = ?
+	`, providerOrDefault(r.Provider), r.SubscriptionID, r.AccountID, r.ServiceName, r.Date).Scan(&n)
+	return n > 0, err
+}
+
+func (db *DB) GetCostRecords(filter CostFilter) ([]CostRecord, error) {
+	query := "SELECT id, COALESCE(provider, 'azure'), subscription_id, COALESCE(account_id, ''), COALESCE(resource_group, ''), service_name, COALESCE(tag_key, ''), COALESCE(tag_value, ''), cost, currency, date, COALESCE(resource_id, '') FROM cost_records WHERE COALESCE(tag_key, '') = ?"
+	query, args := filter.where(query, []interface{}{filter.TagKey})
 	if filter.ServiceName != "" {
 		query += " AND service_name = ?"
 		args = append(args, filter.ServiceName)
@@ -174,7 +456,7 @@ func (db *DB) GetCostRecords(filter CostFilter) ([]CostRecord, error) {
 	var records []CostRecord
 	for rows.Next() {
 		var r CostRecord
-		if err := rows.Scan(&r.ID, &r.SubscriptionID, &r.ResourceGroup, &r.ServiceName, &r.Cost, &r.Currency, &r.Date); err != nil {
+		if err := rows.Scan(&r.ID, &r.Provider, &r.SubscriptionID, &r.AccountID, &r.ResourceGroup, &r.ServiceName, &r.TagKey, &r.TagValue, &r.Cost, &r.Currency, &r.Date, &r.ResourceID); err != nil {
 			return nil, err
 		}
 		records = append(records, r)
@@ -184,21 +466,23 @@ func (db *DB) GetCostRecords(filter CostFilter) ([]CostRecord, error) {
 
 func (db *DB) GetAggregatedCosts(filter CostFilter) (map[string]float64, error) {
 	groupBy := "service_name"
-	if filter.GroupBy == "ResourceGroup" {
-		groupBy = "resource_group"
+	switch filter.GroupBy {
+	case "ResourceGroup":
+		groupBy = "COALESCE(resource_group, '')"
+	case "TagValue":
+		groupBy = "COALESCE(tag_value, '')"
+	case "Provider":
+		groupBy = "COALESCE(provider, 'azure')"
+	case "AccountID":
+		groupBy = "COALESCE(account_id, '')"
+	case "ResourceID":
+		groupBy = "LOWER(COALESCE(resource_id, ''))"
+	case "Currency":
+		groupBy = "COALESCE(currency, '')"
 	}
 
-	query := fmt.Sprintf("SELECT %s, SUM(cost) as total FROM cost_records WHERE 1=1", groupBy)
-	args := []interface{}{}
-
-	if filter.StartDate != "" {
-		query += " AND date >= ?"
-		args = append(args, filter.StartDate)
-	}
-	if filter.EndDate != "" {
-		query += " AND date <= ?"
-		args = append(args, filter.EndDate)
-	}
+	query := fmt.Sprintf("SELECT %s, SUM(cost) as total FROM cost_records WHERE COALESCE(tag_key, '') = ?", groupBy)
+	query, args := filter.where(query, []interface{}{filter.TagKey})
 
 	query += " GROUP BY " + groupBy
 
@@ -226,17 +510,20 @@ type MonthlyCost struct {
 	Currency  string
 }
 
-func (db *DB) GetMonthlyCosts(months int) ([]MonthlyCost, error) {
+// GetMonthlyCosts totals the last months of spend per month and currency,
+// newest first. An empty provider includes every provider.
+func (db *DB) GetMonthlyCosts(months int, provider string) ([]MonthlyCost, error) {
 	query := `
 		SELECT strftime('%Y-%m', date) as month, SUM(cost) as total, currency 
 		FROM cost_records 
-		WHERE date >= date('now', ?)
+		WHERE date >= date('now', ?) AND COALESCE(tag_key, '') = ''
+		AND (? = '' OR COALESCE(provider, 'azure') = ?)
 		GROUP BY strftime('%Y-%m', date), currency
 		ORDER BY month DESC
 	`
 
 	monthsAgo := fmt.Sprintf("-%d months", months)
-	rows, err := db.conn.Query(query, monthsAgo)
+	rows, err := db.conn.Query(query, monthsAgo, provider, provider)
 	if err != nil {
 		return nil, err
 	}
@@ -254,17 +541,8 @@ func (db *DB) GetMonthlyCosts(months int) ([]MonthlyCost, error) {
 }
 
 func (db *DB) GetTotalCost(filter CostFilter) (float64, error) {
-	query := "SELECT COALESCE(SUM(cost), 0) FROM cost_records WHERE 1=1"
-	args := []interface{}{}
-
-	if filter.StartDate != "" {
-		query += " AND date >= ?"
-		args = append(args, filter.StartDate)
-	}
-	if filter.EndDate != "" {
-		query += " AND date <= ?"
-		args = append(args, filter.EndDate)
-	}
+	query := "SELECT COALESCE(SUM(cost), 0) FROM cost_records WHERE COALESCE(tag_key, '') = ?"
+	query, args := filter.where(query, []interface{}{filter.TagKey})
 
 	var total float64
 	err := db.conn.QueryRow(query, args...).Scan(&total)
@@ -305,6 +583,14 @@ func (db *DB) SaveAlert(alert Alert) error {
 	return err
 }
 
+func (db *DB) UpdateAlert(alert Alert) error {
+	_, err := db.conn.Exec(`
+		UPDATE alerts SET threshold = ?, subscription_id = ?, enabled = ?
+		WHERE name = ?
+	`, alert.Threshold, alert.SubscriptionID, alert.Enabled, alert.Name)
+	return err
+}
+
 func (db *DB) DeleteAlert(name string) error {
 	_, err := db.conn.Exec("DELETE FROM alerts WHERE name = ?", name)
 	return err